@@ -28,7 +28,8 @@ func main() {
 	var mistClient *mistapi.Client
 	if cfg.MistAPIToken != "" && cfg.MistSiteID != "" {
 		mistClient = mistapi.NewClient(cfg.MistBaseURL, cfg.MistAPIToken, cfg.MistSiteID)
-		log.Printf("Mist APIクライアントが初期化されました (SiteID: %s)\n", cfg.MistSiteID)
+		mistClient.Limiter = mistapi.NewRateLimiter(cfg.MistHourlyBudget, cfg.MistBurst, cfg.MistLowPriorityReserve)
+		log.Printf("Mist APIクライアントが初期化されました (SiteID: %s, 予算: %d回/時)\n", cfg.MistSiteID, cfg.MistHourlyBudget)
 	} else {
 		log.Println("Mist API設定が不完全なため、Mist APIクライアントは無効化されています")
 	}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
@@ -13,10 +14,12 @@ import (
 	"github.com/Shakkuuu/ed-mist-backend/internal/config"
 	"github.com/Shakkuuu/ed-mist-backend/internal/db"
 	"github.com/Shakkuuu/ed-mist-backend/internal/handler"
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
 	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/internal/scheduler"
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
 	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mailer"
 	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
 
 	"github.com/labstack/echo/v4"
@@ -34,6 +37,25 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 	stayRepo := repository.NewStayRepository(dbConn.DB)
 	subjectRepo := repository.NewSubjectRepository(dbConn.DB)
 	lessonRepo := repository.NewLessonRepository(dbConn.DB)
+	occurrenceRepo := repository.NewLessonOccurrenceRepository(dbConn.DB)
+	termRepo := repository.NewTermRepository(dbConn.DB)
+	calendarDayRepo := repository.NewCalendarDayRepository(dbConn.DB)
+	overrideRepo := repository.NewLessonOverrideRepository(dbConn.DB)
+	enrollmentRepo := repository.NewEnrollmentRepository(dbConn.DB)
+	groupRepo := repository.NewGroupRepository(dbConn.DB)
+	groupMemberRepo := repository.NewGroupMemberRepository(dbConn.DB)
+	assignmentRepo := repository.NewTeachingAssignmentRepository(dbConn.DB)
+	markRepo := repository.NewAttendanceMarkRepository(dbConn.DB)
+	mapRepo := repository.NewMapRepository(dbConn.DB)
+	zoneRepo := repository.NewZoneRepository(dbConn.DB)
+	monitorStateRepo := repository.NewLessonMonitorStateRepository(dbConn.DB)
+	accountRepo := repository.NewAdminAccountRepository(dbConn.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(dbConn.DB)
+	appSessionRepo := repository.NewAppSessionRepository(dbConn.DB)
+	challengeRepo := repository.NewDeviceChallengeRepository(dbConn.DB)
+	registrationRepo := repository.NewDeviceRegistrationRepository(dbConn.DB)
+	feedTokenRepo := repository.NewFeedTokenRepository(dbConn.DB)
+	transactor := repository.NewTransactor(dbConn.DB)
 
 	// serviceの初期化
 	userService := service.NewUserService(userRepo)
@@ -43,20 +65,69 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 	stayService := service.NewStayService(stayRepo)
 	subjectService := service.NewSubjectService(subjectRepo)
 	lessonService := service.NewLessonService(lessonRepo)
-	zoneService := service.NewZoneService(mistClient)
-	mapService := service.NewMapService(mistClient)
+	calendarService := service.NewCalendarService(termRepo, calendarDayRepo)
+	enrollmentService := service.NewEnrollmentService(enrollmentRepo, groupMemberRepo)
+	groupService := service.NewGroupService(groupRepo, groupMemberRepo)
+	assignmentService := service.NewTeachingAssignmentService(assignmentRepo)
+	markService := service.NewAttendanceMarkService(markRepo)
+	occurrenceService := service.NewLessonOccurrenceService(occurrenceRepo, lessonRepo, userRepo, organizationRepo, overrideRepo, calendarService, enrollmentService)
+	overrideService := service.NewLessonOverrideService(overrideRepo)
+	zoneService := service.NewZoneService(mistClient, zoneRepo)
+	mapService := service.NewMapService(mistClient, mapRepo)
+	mistSyncService := service.NewMistSyncService(mistClient, mapRepo, zoneRepo)
+	monitorStateService := service.NewLessonMonitorStateService(monitorStateRepo)
+	accountService := service.NewAdminAccountService(accountRepo)
+	refreshTokenService := service.NewRefreshTokenService(refreshTokenRepo)
+	appSessionService := service.NewAppSessionService(appSessionRepo)
+	challengeService := service.NewDeviceChallengeService(challengeRepo)
+	registrationService := service.NewDeviceRegistrationService(registrationRepo)
+	feedTokenService := service.NewFeedTokenService(feedTokenRepo)
+
+	// トークンの署名鍵（管理APIとアプリで共有し、発行者で区別する）
+	secret := []byte(cfg.JWTSecret)
+
+	// デバイス登録の確認コードのメール送信
+	var mailSender mailer.Sender
+	if cfg.MailSender == config.MailSenderSMTP {
+		mailSender = mailer.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		mailSender = mailer.NewLogSender()
+		log.Println("MAIL_SENDER=logのため、確認コードのメールは送信せずにログへ出力します")
+	}
 
 	// usecaseの初期化
 	organizationUsecase := usecase.NewOrganizationUsecase(organizationService)
 	userUsecase := usecase.NewUserUsecase(userService, organizationService)
-	roomUsecase := usecase.NewRoomUsecase(roomService, organizationService)
-	appAuthUsecase := usecase.NewAppAuthUsecase(userService, deviceService, organizationService)
+	roomUsecase := usecase.NewRoomUsecase(roomService, organizationService, zoneService)
+	appAuthUsecase := usecase.NewAppAuthUsecase(userService, deviceService, organizationService, appSessionService, challengeService, registrationService, mailSender, usecase.AppSessionConfig{
+		Secret: secret,
+		TTL:    time.Duration(cfg.AppSessionTTLHours) * time.Hour,
+	})
 	stayLogUsecase := usecase.NewStayLogUsecase(stayService, userService, roomService, subjectService, organizationService)
-	attendanceUsecase := usecase.NewAttendanceUsecase(lessonService, stayService, userService)
+	attendanceUsecase := usecase.NewAttendanceUsecase(occurrenceService, stayService, userService, markService)
+	calendarUsecase := usecase.NewCalendarUsecase(calendarService, organizationService)
+	overrideUsecase := usecase.NewLessonOverrideUsecase(overrideService, lessonService, roomService, organizationService)
+	enrollmentUsecase := usecase.NewEnrollmentUsecase(enrollmentService, userService, subjectService, organizationService)
+	teacherUsecase := usecase.NewTeacherUsecase(assignmentService, markService, occurrenceService, enrollmentService, stayService, userService, subjectService, lessonService, organizationService)
+	groupUsecase := usecase.NewGroupUsecase(groupService, userService, lessonService, occurrenceService, attendanceUsecase, organizationService)
+	lessonImportUsecase := usecase.NewLessonImportUsecase(transactor, lessonService, overrideService, subjectService, roomService, organizationService)
+	timetableFeedUsecase := usecase.NewTimetableFeedUsecase(transactor, lessonService, overrideService, calendarService, enrollmentService, userService, roomService, feedTokenService)
+	bulkImportUsecase := usecase.NewBulkImportUsecase(transactor, userUsecase, roomUsecase, enrollmentUsecase, userService, roomService, subjectService, lessonService, groupService, organizationService)
+	mistUsecase := usecase.NewMistUsecase(mapService, zoneService, deviceService, roomService, mistSyncService)
+	authUsecase := usecase.NewAuthUsecase(accountService, refreshTokenService, userService, organizationService, usecase.AuthConfig{
+		Secret:          secret,
+		AccessTokenTTL:  time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute,
+		RefreshTokenTTL: time.Duration(cfg.RefreshTokenTTLHours) * time.Hour,
+	})
 
 	// APIハンドラーの初期化
-	appHandler := handler.NewAppHandler(appAuthUsecase, stayLogUsecase, attendanceUsecase, lessonService, deviceService, stayService, organizationService)
-	adminHandler := handler.NewAdminHandler(organizationUsecase, userUsecase, roomUsecase, stayLogUsecase, subjectService, lessonService)
+	appHandler := handler.NewAppHandler(appAuthUsecase, stayLogUsecase, attendanceUsecase, occurrenceService, deviceService, stayService, organizationService, roomUsecase, subjectService)
+	adminHandler := handler.NewAdminHandler(organizationUsecase, userUsecase, roomUsecase, stayLogUsecase, subjectService, lessonService, calendarUsecase, overrideUsecase, enrollmentUsecase, groupUsecase, teacherUsecase)
+	mistHandler := handler.NewMistHandler(mistClient, mistUsecase)
+	icalHandler := handler.NewICalHandler(lessonImportUsecase, timetableFeedUsecase)
+	importHandler := handler.NewImportHandler(bulkImportUsecase)
+	teacherHandler := handler.NewTeacherHandler(teacherUsecase)
+	authHandler := handler.NewAuthHandler(authUsecase)
 
 	e := echo.New()
 
@@ -66,11 +137,15 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 	}))
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
+		AllowOrigins: cfg.CORSAllowOrigins,
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodPatch},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-Device-ID"},
 	}))
 
+	if slices.Contains(cfg.CORSAllowOrigins, "*") {
+		log.Println("CORS_ALLOW_ORIGINSが未設定のため、すべてのオリジンを許可しています")
+	}
+
 	// サービス情報をログに出力
 	log.Printf("初期化されたサービス:")
 	log.Printf("- UserService: %v", userService != nil)
@@ -82,113 +157,286 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 	log.Printf("- ZoneService: %v", zoneService != nil)
 	log.Printf("- MapService: %v", mapService != nil)
 
-	// 授業スケジューラーの初期化と起動
-	lessonScheduler := scheduler.NewLessonScheduler(
-		lessonService,
+	// スケジューラー群の初期化（リーダーに選出されたプロセスのみで実行）
+	workers := scheduler.NewWorkers(
+		occurrenceService,
 		roomService,
 		deviceService,
 		stayService,
-		mistClient,
-	)
-	go lessonScheduler.Start()
-	log.Println("授業スケジューラーを起動しました")
-
-	// 日次バッチスケジューラーの初期化と起動
-	dailyBatchScheduler := scheduler.NewDailyBatchScheduler(
-		deviceService,
+		enrollmentService,
+		monitorStateService,
 		organizationService,
+		mistSyncService,
+		mistClient,
+		scheduler.WorkerConfig{
+			StreamEnabled:    cfg.MistStreamEnabled,
+			MistSyncInterval: time.Duration(cfg.MistSyncIntervalMinutes) * time.Minute,
+		},
 	)
-	go dailyBatchScheduler.Start()
-	log.Println("日次バッチスケジューラーを起動しました")
-
-	// API
-	apiV1 := e.Group("/api/v1")
-	{
-		// アプリ向けエンドポイント
-		app := e.Group("/app")
-		{
-			// デバイス登録（管理画面で作成済みのユーザーが前提）
-			app.POST("/device-register", appHandler.DeviceRegister)
-
-			// デバイスアクティベーション（生体認証）
-			app.POST("/device/activate", appHandler.DeviceActivate)
-
-			// 時間割取得
-			app.GET("/lessons/today", appHandler.GetLessonsToday)
-
-			// 出席状況取得
-			app.GET("/attendance/today", appHandler.GetAttendanceToday)
-
-			// 手動入室
-			app.POST("/stays/manual", appHandler.CreateManualStay)
-
-			// 手動退室
-			app.PUT("/stays/:stay_id/leave", appHandler.LeaveStay)
-
-			// アクティブな滞在確認
-			app.GET("/stays/active", appHandler.GetActiveStay)
 
-			// 滞在ログ取得（ユーザー向け）
-			app.GET("/stays/:user_id", appHandler.GetUserStays)
-
-			// デバッグ用エンドポイント
-			app.POST("/debug/daily-batch", appHandler.RunDailyBatch)
-		}
-
-		// 管理向けエンドポイント
-		// 組織関連
-		organizations := apiV1.Group("/organizations")
-		{
-			organizations.POST("", adminHandler.CreateOrganization)
-			organizations.GET("", adminHandler.GetOrganizations)
-			organizations.GET("/:org_id", adminHandler.GetOrganization)
-			organizations.DELETE("/:org_id", adminHandler.DeleteOrganization)
-		}
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	if cfg.RunsWorkers() {
+		leaderElector := scheduler.NewLeaderElector(dbConn.DB, cfg.LeaderLockKey, 10*time.Second)
+		go func() {
+			defer close(workersDone)
+			leaderElector.Run(workerCtx, workers.Run)
+		}()
+		log.Println("リーダー選出を開始しました（リーダーに選出されるとスケジューラーを起動します）")
+	} else {
+		close(workersDone)
+		log.Println("APP_MODE=apiのため、スケジューラーは起動しません")
+	}
 
-		// ユーザー関連
-		users := apiV1.Group("/users")
-		{
-			users.POST("", adminHandler.CreateUser)
-			users.GET("/:org_id", adminHandler.GetUsers)
-			users.GET("/:org_id/:user_id", adminHandler.GetUser)
-			users.DELETE("/:org_id/:user_id", adminHandler.DeleteUser)
-		}
+	// Mist webhookハンドラーの初期化（リーダーの授業スケジューラーに検知を振り分ける）
+	webhookHandler := handler.NewWebhookHandler(cfg.MistWebhookSecret, workers)
+	if cfg.MistWebhookSecret == "" {
+		log.Println("MIST_WEBHOOK_SECRETが未設定のため、Mist webhookは無効です（ポーリングのみで監視します）")
+	}
 
-		// 部屋関連
-		rooms := apiV1.Group("/rooms")
-		{
-			rooms.POST("", adminHandler.CreateRoom)
-			rooms.GET("/:org_id", adminHandler.GetRooms)
-			rooms.PUT("/:org_id/:room_id", adminHandler.UpdateRoom)
-			rooms.DELETE("/:org_id/:room_id", adminHandler.DeleteRoom)
+	// API（APP_MODE=workerの場合は提供しない）
+	if cfg.RunsAPI() {
+		// 初期スーパー管理者の作成
+		if cfg.BootstrapAdminMail != "" {
+			if err := authUsecase.BootstrapSuperAdmin(context.Background(), cfg.BootstrapAdminMail, cfg.BootstrapAdminPassword); err != nil {
+				log.Printf("初期スーパー管理者の作成に失敗しました: %v", err)
+			}
 		}
 
-		// 滞在ログ取得（管理向け）
-		logs := apiV1.Group("/logs")
+		apiV1 := e.Group("/api/v1")
 		{
-			logs.GET("/stays/:org_id/:room_id/:subject_id", adminHandler.GetStayLogs)
+			// アプリ向けエンドポイント
+			// デバイス登録（管理画面で作成済みのユーザーが前提。メールの確認コードを検証してからセッショントークンを発行する）
+			e.POST("/app/device-register", appHandler.DeviceRegister)
+			e.POST("/app/device-register/verify", appHandler.VerifyDeviceRegister)
+
+			// セッショントークンの更新（期限内のトークンと発行したデバイスが必要）
+			e.POST("/app/session/refresh", appHandler.RefreshSession)
+
+			// デバッグ用エンドポイント（スーパー管理者のみ）
+			e.POST("/app/debug/daily-batch", appHandler.RunDailyBatch, handler.Authenticate(authUsecase), handler.RequireRoles(model.AdminRoleSuperAdmin))
+
+			// 以降はセッショントークンのユーザーとして操作する
+			app := e.Group("/app", handler.AuthenticateApp(appAuthUsecase))
+			{
+				// ログアウト（セッションの失効）
+				app.POST("/session/logout", appHandler.Logout)
+
+				// デバイスアクティベーション（生体認証で保護された鍵によるチャレンジへの署名）
+				app.POST("/device/challenge", appHandler.DeviceChallenge)
+				app.POST("/device/activate", appHandler.DeviceActivate)
+
+				// 以降はアクティベーション済みのデバイスのみ
+				activeDevice := handler.RequireActiveDevice()
+
+				// 時間割取得
+				app.GET("/lessons/today", appHandler.GetLessonsToday, activeDevice)
+
+				// 出席状況取得
+				app.GET("/attendance/today", appHandler.GetAttendanceToday, activeDevice)
+
+				// 手動入室
+				app.POST("/stays/manual", appHandler.CreateManualStay, activeDevice)
+
+				// 手動退室
+				app.PUT("/stays/:stay_id/leave", appHandler.LeaveStay, activeDevice)
+
+				// アクティブな滞在確認
+				app.GET("/stays/active", appHandler.GetActiveStay, activeDevice)
+
+				// 滞在ログ取得（ユーザー向け）
+				app.GET("/stays", appHandler.GetUserStays, activeDevice)
+			}
+
+			// 教員向けエンドポイント
+			teacher := e.Group("/teacher", handler.Authenticate(authUsecase), handler.RequireRoles(model.AdminRoleTeacher))
+			{
+				// 担当する実施中の授業
+				teacher.GET("/lessons/current", teacherHandler.GetCurrentLessons)
+
+				// 授業の対象者の在室状況
+				teacher.GET("/lessons/:occurrence_id/roster", teacherHandler.GetLessonRoster)
+
+				// 出席の手動記録（出席扱い・公欠）
+				teacher.POST("/lessons/:occurrence_id/marks", teacherHandler.MarkAttendance)
+				teacher.DELETE("/lessons/:occurrence_id/marks/:student_id", teacherHandler.UnmarkAttendance)
+			}
+
+			// 認証関連
+			auth := apiV1.Group("/auth")
+			{
+				auth.POST("/login", authHandler.Login)
+				auth.POST("/refresh", authHandler.Refresh)
+				auth.POST("/logout", authHandler.Logout)
+				auth.GET("/me", authHandler.GetMe, handler.Authenticate(authUsecase))
+				auth.PUT("/password", authHandler.ChangePassword, handler.Authenticate(authUsecase))
+			}
+
+			// 管理アカウント関連（スーパー管理者・組織管理者）
+			accounts := apiV1.Group("/accounts", handler.Authenticate(authUsecase), handler.RequireRoles(model.AdminRoleSuperAdmin, model.AdminRoleOrgAdmin))
+			{
+				accounts.POST("", authHandler.CreateAccount)
+				accounts.GET("", authHandler.GetAccounts)
+				accounts.DELETE("/:account_id", authHandler.DeleteAccount)
+			}
+
+			// 管理向けエンドポイント（認証必須、組織管理者以外は参照のみ、自組織のリソースのみ）
+			admin := apiV1.Group("", handler.Authenticate(authUsecase), handler.RequireWriteAccess(), handler.ScopeOrgParam())
+			superAdminOnly := handler.RequireRoles(model.AdminRoleSuperAdmin)
+
+			// 組織関連
+			organizations := admin.Group("/organizations")
+			{
+				organizations.POST("", adminHandler.CreateOrganization, superAdminOnly)
+				organizations.GET("", adminHandler.GetOrganizations)
+				organizations.GET("/:org_id", adminHandler.GetOrganization)
+				organizations.DELETE("/:org_id", adminHandler.DeleteOrganization, superAdminOnly)
+			}
+
+			// ユーザー関連
+			users := admin.Group("/users")
+			{
+				users.POST("", adminHandler.CreateUser)
+				users.GET("/:org_id", adminHandler.GetUsers)
+				users.GET("/:org_id/:user_id", adminHandler.GetUser)
+				users.PUT("/:org_id/:user_id/role", adminHandler.UpdateUserRole)
+				users.DELETE("/:org_id/:user_id", adminHandler.DeleteUser)
+				users.DELETE("/:org_id/:user_id/sessions", appHandler.RevokeUserSessions)
+				users.DELETE("/:org_id/:user_id/devices", appHandler.RemoveUserDevices)
+			}
+
+			// 部屋関連
+			rooms := admin.Group("/rooms")
+			{
+				rooms.POST("", adminHandler.CreateRoom)
+				rooms.GET("/:org_id", adminHandler.GetRooms)
+				rooms.PUT("/:org_id/:room_id", adminHandler.UpdateRoom)
+				rooms.DELETE("/:org_id/:room_id", adminHandler.DeleteRoom)
+			}
+
+			// 滞在ログ取得（管理向け）
+			logs := admin.Group("/logs")
+			{
+				logs.GET("/stays/:org_id/:room_id/:subject_id", adminHandler.GetStayLogs)
+			}
+
+			// 教科関連
+			subjects := admin.Group("/subjects")
+			{
+				subjects.POST("", adminHandler.CreateSubject)
+				subjects.GET("/:org_id", adminHandler.GetSubjects)
+				subjects.DELETE("/:subject_id", adminHandler.DeleteSubject)
+			}
+
+			// 授業関連
+			lessons := admin.Group("/lessons")
+			{
+				lessons.POST("", adminHandler.CreateLesson)
+				lessons.GET("/:org_id", adminHandler.GetLessons)
+				lessons.DELETE("/:lesson_id", adminHandler.DeleteLesson)
+
+				// iCalendar（.ics）の取り込み・配信
+				lessons.POST("/import", icalHandler.ImportLessons)
+				lessons.GET("/feeds/users/:user_id", icalHandler.GetUserFeed)
+				lessons.GET("/feeds/rooms/:room_id", icalHandler.GetRoomFeed)
+
+				// カレンダーアプリで購読するURLのトークン発行・失効
+				lessons.POST("/feeds/users/:user_id/token", icalHandler.IssueUserFeedToken)
+				lessons.DELETE("/feeds/users/:user_id/token", icalHandler.RevokeUserFeedToken)
+				lessons.POST("/feeds/rooms/:room_id/token", icalHandler.IssueRoomFeedToken)
+				lessons.DELETE("/feeds/rooms/:room_id/token", icalHandler.RevokeRoomFeedToken)
+			}
+
+			// 授業変更（休講・教室変更・時間変更）関連
+			lessonOverrides := admin.Group("/lesson-overrides")
+			{
+				lessonOverrides.POST("", adminHandler.CreateLessonOverride)
+				lessonOverrides.GET("/:org_id", adminHandler.GetLessonOverrides)
+				lessonOverrides.PUT("/:org_id/:override_id", adminHandler.UpdateLessonOverride)
+				lessonOverrides.DELETE("/:org_id/:override_id", adminHandler.DeleteLessonOverride)
+			}
+
+			// 履修登録関連
+			enrollments := admin.Group("/enrollments")
+			{
+				enrollments.POST("", adminHandler.CreateEnrollment)
+				enrollments.GET("/:org_id", adminHandler.GetEnrollments)
+				enrollments.DELETE("/:org_id/:enrollment_id", adminHandler.DeleteEnrollment)
+			}
+
+			// クラス（ホームルーム）関連
+			groups := admin.Group("/groups")
+			{
+				groups.POST("", adminHandler.CreateGroup)
+				groups.GET("/:org_id", adminHandler.GetGroups)
+				groups.PUT("/:org_id/:group_id", adminHandler.UpdateGroup)
+				groups.DELETE("/:org_id/:group_id", adminHandler.DeleteGroup)
+				groups.POST("/:org_id/:group_id/members", adminHandler.AddGroupMembers)
+				groups.GET("/:org_id/:group_id/members", adminHandler.GetGroupMembers)
+				groups.DELETE("/:org_id/:group_id/members/:user_id", adminHandler.RemoveGroupMember)
+				groups.GET("/:org_id/:group_id/lessons", adminHandler.GetGroupLessons)
+				groups.POST("/:org_id/:group_id/lessons/:lesson_id", adminHandler.AssignGroupLesson)
+				groups.DELETE("/:org_id/:group_id/lessons/:lesson_id", adminHandler.UnassignGroupLesson)
+				groups.GET("/:org_id/:group_id/timetable", adminHandler.GetGroupTimetable)
+				groups.GET("/:org_id/:group_id/attendance", adminHandler.GetGroupAttendance)
+			}
+
+			// 教員の担当関連
+			teachingAssignments := admin.Group("/teaching-assignments")
+			{
+				teachingAssignments.POST("", adminHandler.CreateTeachingAssignment)
+				teachingAssignments.GET("/:org_id", adminHandler.GetTeachingAssignments)
+				teachingAssignments.DELETE("/:org_id/:assignment_id", adminHandler.DeleteTeachingAssignment)
+			}
+
+			// 学期・休日カレンダー関連
+			calendar := admin.Group("/calendar")
+			{
+				calendar.POST("/terms", adminHandler.CreateTerm)
+				calendar.GET("/terms/:org_id", adminHandler.GetTerms)
+				calendar.PUT("/terms/:org_id/:term_id", adminHandler.UpdateTerm)
+				calendar.DELETE("/terms/:org_id/:term_id", adminHandler.DeleteTerm)
+				calendar.POST("/days", adminHandler.CreateCalendarDay)
+				calendar.GET("/days/:org_id", adminHandler.GetCalendarDays)
+				calendar.PUT("/days/:org_id/:day_id", adminHandler.UpdateCalendarDay)
+				calendar.DELETE("/days/:org_id/:day_id", adminHandler.DeleteCalendarDay)
+			}
+
+			// CSV・XLSXの一括取り込み（users, rooms, subjects, lessons, enrollments, group_members）
+			imports := admin.Group("/imports")
+			{
+				imports.POST("/:kind", importHandler.BulkImport)
+			}
+
+			// Mist関連
+			mist := admin.Group("/mist")
+			{
+				mist.GET("/stats", mistHandler.GetAPIStats)
+				mist.GET("/maps", mistHandler.GetMaps)
+				mist.GET("/maps/:map_id/image", mistHandler.GetMapImage)
+				mist.GET("/maps/:map_id/overlay", mistHandler.GetFloorPlan)
+				mist.GET("/zones", mistHandler.GetZones)
+				mist.GET("/zones/:zone_id/clients", mistHandler.GetZoneClients)
+				mist.POST("/sync", mistHandler.SyncMist, superAdminOnly)
+			}
 		}
 
-		// 教科関連
-		subjects := apiV1.Group("/subjects")
-		{
-			subjects.POST("", adminHandler.CreateSubject)
-			subjects.GET("/:org_id", adminHandler.GetSubjects)
-			subjects.DELETE("/:subject_id", adminHandler.DeleteSubject)
-		}
+		// 時間割のiCalendar購読（カレンダーアプリはAuthorizationヘッダーを送れないため、URLのトークンで認証する）
+		e.GET("/feeds/:token", icalHandler.GetFeedByToken)
+	}
 
-		// 授業関連
-		lessons := apiV1.Group("/lessons")
-		{
-			lessons.POST("", adminHandler.CreateLesson)
-			lessons.GET("/:org_id", adminHandler.GetLessons)
-			lessons.DELETE("/:lesson_id", adminHandler.DeleteLesson)
-		}
+	// Mist webhook受信エンドポイント（検知はスケジューラーを実行するプロセスでのみ処理できる）
+	if cfg.RunsWorkers() {
+		e.POST("/webhooks/mist", webhookHandler.ReceiveMist)
 	}
 
 	// ヘルスチェックエンドポイント
 	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+			"mode":   cfg.AppMode,
+			"leader": workers.IsLeader(),
+		})
 	})
 
 	// シグナルチャンネルの作成
@@ -197,7 +445,7 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 
 	// サーバー起動
 	addr := ":" + strconv.Itoa(cfg.ServerPort)
-	log.Printf("サーバーを起動中: %s\n", addr)
+	log.Printf("サーバーを起動中: %s (APP_MODE=%s)\n", addr, cfg.AppMode)
 
 	// goroutineでサーバーを起動
 	go func() {
@@ -210,12 +458,10 @@ func Run(cfg *config.Config, dbConn *db.Connection, mistClient *mistapi.Client)
 	<-quit
 	log.Println("Graceful Shutdownを開始します...")
 
-	// スケジューラーを停止
-	log.Println("授業スケジューラーを停止しています...")
-	lessonScheduler.Stop()
-
-	log.Println("日次バッチスケジューラーを停止しています...")
-	dailyBatchScheduler.Stop()
+	// スケジューラーを停止してリーダーのロックを解放
+	log.Println("スケジューラーを停止しています...")
+	stopWorkers()
+	<-workersDone
 
 	// タイムアウト付きのcontextでシャットダウン
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -17,6 +17,74 @@ type Config struct {
 	MistBaseURL    string `env:"MIST_BASE_URL"`
 	MistSiteID     string `env:"MIST_SITE_ID"`
 	Interval       int    `env:"INTERVAL"`
+
+	// Mist APIのレート制限
+	MistHourlyBudget       int     `env:"MIST_HOURLY_BUDGET" env-default:"5000"`
+	MistBurst              int     `env:"MIST_BURST" env-default:"50"`
+	MistLowPriorityReserve float64 `env:"MIST_LOW_PRIORITY_RESERVE" env-default:"0.3"`
+
+	// Mist Webhookの署名検証用シークレット（未設定の場合はWebhookを受け付けない）
+	MistWebhookSecret string `env:"MIST_WEBHOOK_SECRET"`
+
+	// Mist WebSocketストリームで位置情報を受信するか（無効の場合はBLEアセットをポーリング）
+	MistStreamEnabled bool `env:"MIST_STREAM_ENABLED" env-default:"false"`
+
+	// Mistのマップ・ゾーン定義をDBに同期する間隔（分）
+	MistSyncIntervalMinutes int `env:"MIST_SYNC_INTERVAL_MINUTES" env-default:"15"`
+
+	// 起動モード（all: APIとスケジューラー / api: APIのみ / worker: スケジューラーのみ）
+	AppMode string `env:"APP_MODE" env-default:"all"`
+
+	// スケジューラーを実行するリーダーの選出に使うアドバイザリロックのキー
+	LeaderLockKey int64 `env:"LEADER_LOCK_KEY" env-default:"7266190217"`
+
+	// 管理APIのアクセストークン・アプリのセッショントークンの署名鍵（APIを提供する場合は必須、全プロセスで同じ値を設定する）
+	JWTSecret string `env:"JWT_SECRET"`
+
+	// アクセストークン（分）・リフレッシュトークン（時間）の有効期限
+	AccessTokenTTLMinutes int `env:"ACCESS_TOKEN_TTL_MINUTES" env-default:"15"`
+	RefreshTokenTTLHours  int `env:"REFRESH_TOKEN_TTL_HOURS" env-default:"720"`
+
+	// アプリのセッショントークンの有効期限（時間）
+	AppSessionTTLHours int `env:"APP_SESSION_TTL_HOURS" env-default:"720"`
+
+	// 初回起動時に作成するスーパー管理者（スーパー管理者が存在しない場合のみ作成）
+	BootstrapAdminMail     string `env:"BOOTSTRAP_ADMIN_MAIL"`
+	BootstrapAdminPassword string `env:"BOOTSTRAP_ADMIN_PASSWORD"`
+
+	// デバイス登録の確認コードのメール送信方法（smtp: SMTPサーバー / log: 送信せずにログへ出力する開発・テスト用）
+	MailSender   string `env:"MAIL_SENDER" env-default:"log"`
+	MailFrom     string `env:"MAIL_FROM"`
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT" env-default:"587"`
+	SMTPUser     string `env:"SMTP_USER"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+
+	// CORSで許可するオリジン（カンマ区切り）
+	CORSAllowOrigins []string `env:"CORS_ALLOW_ORIGINS" env-separator:"," env-default:"*"`
+}
+
+// 起動モード
+const (
+	AppModeAll    = "all"
+	AppModeAPI    = "api"
+	AppModeWorker = "worker"
+)
+
+// メール送信方法
+const (
+	MailSenderSMTP = "smtp"
+	MailSenderLog  = "log"
+)
+
+// RunsAPI APIを提供するか
+func (c *Config) RunsAPI() bool {
+	return c.AppMode != AppModeWorker
+}
+
+// RunsWorkers スケジューラーを実行するか
+func (c *Config) RunsWorkers() bool {
+	return c.AppMode != AppModeAPI
 }
 
 func Load() (*Config, error) {
@@ -25,6 +93,27 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	switch cfg.AppMode {
+	case AppModeAll, AppModeAPI, AppModeWorker:
+	default:
+		return nil, fmt.Errorf("APP_MODEが不正です: %s (all / api / worker)", cfg.AppMode)
+	}
+	if cfg.RunsAPI() && cfg.JWTSecret == "" {
+		return nil, fmt.Errorf("JWT_SECRETが設定されていません（トークンの署名鍵として、全プロセスで共有するランダムな文字列を設定してください）")
+	}
+	// 0以下では予算が補充されず、Mist APIの呼び出しがすべて待ち続けてしまう
+	if cfg.MistHourlyBudget <= 0 || cfg.MistBurst <= 0 {
+		return nil, fmt.Errorf("MIST_HOURLY_BUDGETとMIST_BURSTは1以上を設定してください (MIST_HOURLY_BUDGET=%d, MIST_BURST=%d)", cfg.MistHourlyBudget, cfg.MistBurst)
+	}
+	switch cfg.MailSender {
+	case MailSenderSMTP:
+		if cfg.SMTPHost == "" || cfg.MailFrom == "" {
+			return nil, fmt.Errorf("MAIL_SENDER=smtpの場合はSMTP_HOSTとMAIL_FROMが必須です")
+		}
+	case MailSenderLog:
+	default:
+		return nil, fmt.Errorf("MAIL_SENDERが不正です: %s (smtp / log)", cfg.MailSender)
+	}
 	return cfg, nil
 }
 
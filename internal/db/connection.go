@@ -53,7 +53,7 @@ func NewConnection(dsn string) (*Connection, error) {
 
 // runMigrations マイグレーションを実行
 func runMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&model.User{},
 		&model.Device{},
 		&model.Stay{},
@@ -61,7 +61,64 @@ func runMigrations(db *gorm.DB) error {
 		&model.Organization{},
 		&model.Room{},
 		&model.Lesson{},
-	)
+		&model.LessonOccurrence{},
+		&model.LessonOverride{},
+		&model.Enrollment{},
+		&model.Group{},
+		&model.GroupMember{},
+		&model.TeachingAssignment{},
+		&model.AttendanceMark{},
+		&model.AdminAccount{},
+		&model.RefreshToken{},
+		&model.AppSession{},
+		&model.DeviceChallenge{},
+		&model.DeviceRegistration{},
+		&model.FeedToken{},
+		&model.Term{},
+		&model.CalendarDay{},
+		&model.Map{},
+		&model.Zone{},
+		&model.LessonMonitorState{},
+	); err != nil {
+		return err
+	}
+	return backfillStayLessonDates(db)
+}
+
+// backfillStayLessonDates 授業に紐づく既存の滞在に授業実施日を設定
+// 同じユーザー・授業・日付の滞在が複数ある場合は最初の1件のみに設定し、
+// 残りは一意制約の対象外（lesson_date = NULL）のまま残す
+func backfillStayLessonDates(db *gorm.DB) error {
+	result := db.Exec(`
+		UPDATE stays SET lesson_date = d.lesson_date
+		FROM (
+			SELECT id, DATE(created_at) AS lesson_date,
+				ROW_NUMBER() OVER (PARTITION BY user_id, lesson_id, DATE(created_at) ORDER BY created_at, id) AS rn
+			FROM stays
+			WHERE lesson_id IS NOT NULL AND lesson_date IS NULL
+		) d
+		WHERE stays.id = d.id AND d.rn = 1
+			AND NOT EXISTS (
+				SELECT 1 FROM stays s
+				WHERE s.user_id = stays.user_id AND s.lesson_id = stays.lesson_id AND s.lesson_date = d.lesson_date
+			)`)
+	if result.Error != nil {
+		return fmt.Errorf("滞在の授業実施日の設定エラー: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("滞在の授業実施日を設定しました: %d件", result.RowsAffected)
+	}
+
+	var duplicates int64
+	if err := db.Model(&model.Stay{}).
+		Where("lesson_id IS NOT NULL AND lesson_date IS NULL").
+		Count(&duplicates).Error; err != nil {
+		return fmt.Errorf("重複した滞在の確認エラー: %w", err)
+	}
+	if duplicates > 0 {
+		log.Printf("警告: 同じ授業・日付の重複した滞在が%d件あります（授業実施日は未設定のままです）", duplicates)
+	}
+	return nil
 }
 
 func (c *Connection) Close() error {
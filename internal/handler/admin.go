@@ -20,6 +20,11 @@ type AdminHandler struct {
 	stayLogUsecase      *usecase.StayLogUsecase
 	subjectService      *service.SubjectService
 	lessonService       *service.LessonService
+	calendarUsecase     *usecase.CalendarUsecase
+	overrideUsecase     *usecase.LessonOverrideUsecase
+	enrollmentUsecase   *usecase.EnrollmentUsecase
+	groupUsecase        *usecase.GroupUsecase
+	teacherUsecase      *usecase.TeacherUsecase
 }
 
 // NewAdminHandler 管理向けハンドラーを作成
@@ -30,6 +35,11 @@ func NewAdminHandler(
 	stayLogUsecase *usecase.StayLogUsecase,
 	subjectService *service.SubjectService,
 	lessonService *service.LessonService,
+	calendarUsecase *usecase.CalendarUsecase,
+	overrideUsecase *usecase.LessonOverrideUsecase,
+	enrollmentUsecase *usecase.EnrollmentUsecase,
+	groupUsecase *usecase.GroupUsecase,
+	teacherUsecase *usecase.TeacherUsecase,
 ) *AdminHandler {
 	return &AdminHandler{
 		organizationUsecase: organizationUsecase,
@@ -38,6 +48,11 @@ func NewAdminHandler(
 		stayLogUsecase:      stayLogUsecase,
 		subjectService:      subjectService,
 		lessonService:       lessonService,
+		calendarUsecase:     calendarUsecase,
+		overrideUsecase:     overrideUsecase,
+		enrollmentUsecase:   enrollmentUsecase,
+		groupUsecase:        groupUsecase,
+		teacherUsecase:      teacherUsecase,
 	}
 }
 
@@ -50,6 +65,17 @@ func (h *AdminHandler) GetOrganizations(c echo.Context) error {
 		log.Printf("[GetOrganizations] 組織一覧取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+
+	// スーパー管理者以外は自組織のみ
+	if orgID := scopedOrgID(c); orgID != "" {
+		scoped := organizations[:0]
+		for _, organization := range organizations {
+			if organization.ID == orgID {
+				scoped = append(scoped, organization)
+			}
+		}
+		organizations = scoped
+	}
 	return c.JSON(http.StatusOK, organizations)
 }
 
@@ -138,6 +164,11 @@ func (h *AdminHandler) CreateUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_idは必須です"})
 	}
 
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
 	if request.UserMail == "" {
 		log.Printf("[CreateUser] ユーザーメールが空です\n")
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_mailは必須です"})
@@ -146,6 +177,9 @@ func (h *AdminHandler) CreateUser(c echo.Context) error {
 	user, err := h.userUsecase.CreateUser(ctx, &request)
 	if err != nil {
 		log.Printf("[CreateUser] ユーザー作成エラー: %v\n", err)
+		if err == usecase.ErrorInvalidUserRole {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -198,6 +232,36 @@ func (h *AdminHandler) GetUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, user)
 }
 
+// UpdateUserRole ユーザーの役割変更（student, teacher）
+// PUT /api/v1/users/:org_id/:user_id/role
+func (h *AdminHandler) UpdateUserRole(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	userID := c.Param("user_id")
+
+	if orgID == "" || userID == "" {
+		log.Printf("[UpdateUserRole] 組織IDまたはユーザーIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDとユーザーIDは必須です"})
+	}
+
+	var request usecase.UpdateUserRoleRequest
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[UpdateUserRole] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	user, err := h.userUsecase.UpdateUserRole(ctx, orgID, userID, &request)
+	if err != nil {
+		log.Printf("[UpdateUserRole] ユーザーの役割変更エラー: %v, orgID: %s, userID: %s\n", err, orgID, userID)
+		if err == usecase.ErrorInvalidUserRole {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
 // DeleteUser ユーザー削除
 // DELETE /users/:org_id/:user_id
 func (h *AdminHandler) DeleteUser(c echo.Context) error {
@@ -239,6 +303,11 @@ func (h *AdminHandler) CreateRoom(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_idは必須です"})
 	}
 
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
 	if request.OrgRoomID == "" {
 		log.Printf("[CreateRoom] 組織部屋IDが空です\n")
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_room_idは必須です"})
@@ -252,6 +321,9 @@ func (h *AdminHandler) CreateRoom(c echo.Context) error {
 	room, err := h.roomUsecase.CreateRoom(ctx, &request)
 	if err != nil {
 		log.Printf("[CreateRoom] 部屋作成エラー: %v\n", err)
+		if err == usecase.ErrorZoneNotFound {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -304,6 +376,9 @@ func (h *AdminHandler) UpdateRoom(c echo.Context) error {
 	room, err := h.roomUsecase.UpdateRoom(ctx, orgID, roomID, &request)
 	if err != nil {
 		log.Printf("[UpdateRoom] 部屋更新エラー: %v, orgID: %s, roomID: %s\n", err, orgID, roomID)
+		if err == usecase.ErrorZoneNotFound {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -414,6 +489,11 @@ func (h *AdminHandler) CreateSubject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, nameは必須です"})
 	}
 
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
 	if request.Year == 0 {
 		request.Year = time.Now().Year()
 	}
@@ -482,6 +562,16 @@ func (h *AdminHandler) DeleteSubject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "subject_idは必須です"})
 	}
 
+	// 教科の組織にアクセスできるか確認
+	subject, err := h.subjectService.GetByID(ctx, subjectID)
+	if err != nil {
+		log.Printf("[DeleteSubject] 教科取得エラー: %v\n", err)
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "教科が見つかりません"})
+	}
+	if !authorizeOrg(c, subject.OrgID) {
+		return forbidden(c)
+	}
+
 	if err := h.subjectService.Delete(ctx, subjectID); err != nil {
 		log.Printf("[DeleteSubject] 教科削除エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "教科の削除に失敗しました"})
@@ -503,7 +593,9 @@ func (h *AdminHandler) CreateLesson(c echo.Context) error {
 		StartTime  string `json:"start_time"` // "09:00"
 		EndTime    string `json:"end_time"`   // "10:30"
 		Period     int    `json:"period"`
-		DateString string `json:"date"` // "2025-10-10" (オプション)
+		DateString string `json:"date"`     // "2025-10-10" (オプション)
+		UntilDate  string `json:"until"`    // "2026-03-31" 毎週の授業の最終日 (オプション)
+		GroupID    string `json:"group_id"` // 対象のクラス (オプション)
 	}
 
 	if err := c.Bind(&request); err != nil {
@@ -515,10 +607,26 @@ func (h *AdminHandler) CreateLesson(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, subject_id, room_idは必須です"})
 	}
 
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
 	if request.StartTime == "" || request.EndTime == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "start_time, end_timeは必須です"})
 	}
 
+	// 教科・部屋が組織に属しているか確認（他組織の部屋を監視させないため）
+	subject, err := h.subjectService.GetByID(ctx, request.SubjectID)
+	if err != nil || subject.OrgID != request.OrgID {
+		log.Printf("[CreateLesson] 教科が組織に属していません: %v, subjectID: %s\n", err, request.SubjectID)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "subject_idが不正です"})
+	}
+	if _, err := h.roomUsecase.GetRoom(ctx, request.OrgID, request.RoomID); err != nil {
+		log.Printf("[CreateLesson] 部屋取得エラー: %v, roomID: %s\n", err, request.RoomID)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "room_idが不正です"})
+	}
+
 	// 日付を取得（指定されていなければ今日）
 	var baseDate time.Time
 	if request.DateString != "" {
@@ -565,6 +673,26 @@ func (h *AdminHandler) CreateLesson(c echo.Context) error {
 		datePtr = &baseDate
 	}
 
+	var untilPtr *time.Time
+	if request.UntilDate != "" {
+		until, err := time.Parse("2006-01-02", request.UntilDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "untilの形式が不正です（YYYY-MM-DD）"})
+		}
+		untilPtr = &until
+	}
+
+	// クラスが指定されていれば組織に属しているか確認
+	var groupPtr *string
+	if request.GroupID != "" {
+		group, err := h.groupUsecase.GetGroup(ctx, request.OrgID, request.GroupID)
+		if err != nil {
+			log.Printf("[CreateLesson] クラス取得エラー: %v, groupID: %s\n", err, request.GroupID)
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "group_idが不正です"})
+		}
+		groupPtr = &group.ID
+	}
+
 	lesson, err := h.lessonService.Create(
 		ctx,
 		request.SubjectID,
@@ -575,6 +703,8 @@ func (h *AdminHandler) CreateLesson(c echo.Context) error {
 		endTime,
 		request.Period,
 		datePtr,
+		untilPtr,
+		groupPtr,
 	)
 	if err != nil {
 		log.Printf("[CreateLesson] 授業作成エラー: %v\n", err)
@@ -592,6 +722,8 @@ func (h *AdminHandler) CreateLesson(c echo.Context) error {
 		"end_time":    lesson.EndTime,
 		"period":      lesson.Period,
 		"date":        lesson.Date,
+		"until":       lesson.Until,
+		"group_id":    lesson.GroupID,
 		"created_at":  lesson.CreatedAt,
 		"updated_at":  lesson.UpdatedAt,
 	}
@@ -628,6 +760,7 @@ func (h *AdminHandler) GetLessons(c echo.Context) error {
 			"end_time":    lesson.EndTime,
 			"period":      lesson.Period,
 			"date":        lesson.Date,
+			"until":       lesson.Until,
 			"created_at":  lesson.CreatedAt,
 			"updated_at":  lesson.UpdatedAt,
 			"subject": map[string]interface{}{
@@ -657,6 +790,16 @@ func (h *AdminHandler) DeleteLesson(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "lesson_idは必須です"})
 	}
 
+	// 授業の組織にアクセスできるか確認
+	lesson, err := h.lessonService.GetByID(ctx, lessonID)
+	if err != nil {
+		log.Printf("[DeleteLesson] 授業取得エラー: %v\n", err)
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "授業が見つかりません"})
+	}
+	if !authorizeOrg(c, lesson.OrgID) {
+		return forbidden(c)
+	}
+
 	if err := h.lessonService.Delete(ctx, lessonID); err != nil {
 		log.Printf("[DeleteLesson] 授業削除エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "授業の削除に失敗しました"})
@@ -664,3 +807,743 @@ func (h *AdminHandler) DeleteLesson(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "授業が削除されました"})
 }
+
+// lessonOverrideErrorStatus 授業変更関連のエラーに対応するHTTPステータス
+func lessonOverrideErrorStatus(err error) int {
+	switch err {
+	case usecase.ErrorInvalidDate, usecase.ErrorLessonOverrideEmpty, usecase.ErrorInvalidLessonTime, usecase.ErrorInvalidLessonTimeRange:
+		return http.StatusBadRequest
+	case usecase.ErrorLessonOverrideExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateLessonOverride 授業変更（休講・教室変更・時間変更）登録
+// POST /api/v1/lesson-overrides
+func (h *AdminHandler) CreateLessonOverride(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateLessonOverrideRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateLessonOverride] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.LessonID == "" || request.Date == "" {
+		log.Printf("[CreateLessonOverride] org_id, lesson_id, dateは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, lesson_id, dateは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	override, err := h.overrideUsecase.CreateLessonOverride(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateLessonOverride] 授業変更作成エラー: %v\n", err)
+		return c.JSON(lessonOverrideErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, override)
+}
+
+// GetLessonOverrides 授業変更一覧取得
+// GET /api/v1/lesson-overrides/:org_id?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AdminHandler) GetLessonOverrides(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetLessonOverrides] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	overrides, err := h.overrideUsecase.GetLessonOverridesByOrgID(ctx, orgID, c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		log.Printf("[GetLessonOverrides] 授業変更一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(lessonOverrideErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, overrides)
+}
+
+// UpdateLessonOverride 授業変更更新
+// PUT /api/v1/lesson-overrides/:org_id/:override_id
+func (h *AdminHandler) UpdateLessonOverride(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	overrideID := c.Param("override_id")
+	var request usecase.UpdateLessonOverrideRequest
+
+	if orgID == "" || overrideID == "" {
+		log.Printf("[UpdateLessonOverride] 組織IDまたは授業変更IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと授業変更IDは必須です"})
+	}
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[UpdateLessonOverride] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	override, err := h.overrideUsecase.UpdateLessonOverride(ctx, orgID, overrideID, &request)
+	if err != nil {
+		log.Printf("[UpdateLessonOverride] 授業変更更新エラー: %v, orgID: %s, overrideID: %s\n", err, orgID, overrideID)
+		return c.JSON(lessonOverrideErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, override)
+}
+
+// DeleteLessonOverride 授業変更削除（時間割どおりの実施に戻す）
+// DELETE /api/v1/lesson-overrides/:org_id/:override_id
+func (h *AdminHandler) DeleteLessonOverride(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	overrideID := c.Param("override_id")
+
+	if orgID == "" || overrideID == "" {
+		log.Printf("[DeleteLessonOverride] 組織IDまたは授業変更IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと授業変更IDは必須です"})
+	}
+
+	if err := h.overrideUsecase.DeleteLessonOverride(ctx, orgID, overrideID); err != nil {
+		log.Printf("[DeleteLessonOverride] 授業変更削除エラー: %v, orgID: %s, overrideID: %s\n", err, orgID, overrideID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "授業変更が削除されました"})
+}
+
+// calendarErrorStatus カレンダー関連のエラーに対応するHTTPステータス
+func calendarErrorStatus(err error) int {
+	switch err {
+	case usecase.ErrorInvalidDate, usecase.ErrorInvalidDateRange, usecase.ErrorInvalidCalendarDayKind, usecase.ErrorInvalidDayOfWeek:
+		return http.StatusBadRequest
+	case usecase.ErrorCalendarDayExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateTerm 学期登録
+// POST /api/v1/calendar/terms
+func (h *AdminHandler) CreateTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateTermRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateTerm] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.Name == "" {
+		log.Printf("[CreateTerm] org_id, nameは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, nameは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	if request.StartDate == "" || request.EndDate == "" {
+		log.Printf("[CreateTerm] start_date, end_dateは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "start_date, end_dateは必須です"})
+	}
+
+	term, err := h.calendarUsecase.CreateTerm(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateTerm] 学期作成エラー: %v\n", err)
+		return c.JSON(calendarErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, term)
+}
+
+// GetTerms 学期一覧取得
+// GET /api/v1/calendar/terms/:org_id
+func (h *AdminHandler) GetTerms(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetTerms] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	terms, err := h.calendarUsecase.GetTermsByOrgID(ctx, orgID)
+	if err != nil {
+		log.Printf("[GetTerms] 学期一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, terms)
+}
+
+// UpdateTerm 学期更新
+// PUT /api/v1/calendar/terms/:org_id/:term_id
+func (h *AdminHandler) UpdateTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	termID := c.Param("term_id")
+	var request usecase.UpdateTermRequest
+
+	if orgID == "" || termID == "" {
+		log.Printf("[UpdateTerm] 組織IDまたは学期IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと学期IDは必須です"})
+	}
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[UpdateTerm] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	term, err := h.calendarUsecase.UpdateTerm(ctx, orgID, termID, &request)
+	if err != nil {
+		log.Printf("[UpdateTerm] 学期更新エラー: %v, orgID: %s, termID: %s\n", err, orgID, termID)
+		return c.JSON(calendarErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm 学期削除
+// DELETE /api/v1/calendar/terms/:org_id/:term_id
+func (h *AdminHandler) DeleteTerm(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	termID := c.Param("term_id")
+
+	if orgID == "" || termID == "" {
+		log.Printf("[DeleteTerm] 組織IDまたは学期IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと学期IDは必須です"})
+	}
+
+	if err := h.calendarUsecase.DeleteTerm(ctx, orgID, termID); err != nil {
+		log.Printf("[DeleteTerm] 学期削除エラー: %v, orgID: %s, termID: %s\n", err, orgID, termID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "学期が削除されました"})
+}
+
+// CreateCalendarDay 休日・特別時間割登録
+// POST /api/v1/calendar/days
+func (h *AdminHandler) CreateCalendarDay(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateCalendarDayRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateCalendarDay] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.Date == "" || request.Kind == "" {
+		log.Printf("[CreateCalendarDay] org_id, date, kindは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, date, kindは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	day, err := h.calendarUsecase.CreateCalendarDay(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateCalendarDay] 休日・特別時間割作成エラー: %v\n", err)
+		return c.JSON(calendarErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, day)
+}
+
+// GetCalendarDays 休日・特別時間割一覧取得
+// GET /api/v1/calendar/days/:org_id?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AdminHandler) GetCalendarDays(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetCalendarDays] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	days, err := h.calendarUsecase.GetCalendarDaysByOrgID(ctx, orgID, c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		log.Printf("[GetCalendarDays] 休日・特別時間割一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(calendarErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, days)
+}
+
+// UpdateCalendarDay 休日・特別時間割更新
+// PUT /api/v1/calendar/days/:org_id/:day_id
+func (h *AdminHandler) UpdateCalendarDay(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	dayID := c.Param("day_id")
+	var request usecase.UpdateCalendarDayRequest
+
+	if orgID == "" || dayID == "" {
+		log.Printf("[UpdateCalendarDay] 組織IDまたは日付IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと日付IDは必須です"})
+	}
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[UpdateCalendarDay] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	day, err := h.calendarUsecase.UpdateCalendarDay(ctx, orgID, dayID, &request)
+	if err != nil {
+		log.Printf("[UpdateCalendarDay] 休日・特別時間割更新エラー: %v, orgID: %s, dayID: %s\n", err, orgID, dayID)
+		return c.JSON(calendarErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, day)
+}
+
+// DeleteCalendarDay 休日・特別時間割削除
+// DELETE /api/v1/calendar/days/:org_id/:day_id
+func (h *AdminHandler) DeleteCalendarDay(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	dayID := c.Param("day_id")
+
+	if orgID == "" || dayID == "" {
+		log.Printf("[DeleteCalendarDay] 組織IDまたは日付IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと日付IDは必須です"})
+	}
+
+	if err := h.calendarUsecase.DeleteCalendarDay(ctx, orgID, dayID); err != nil {
+		log.Printf("[DeleteCalendarDay] 休日・特別時間割削除エラー: %v, orgID: %s, dayID: %s\n", err, orgID, dayID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "休日・特別時間割が削除されました"})
+}
+
+// enrollmentErrorStatus 履修登録関連のエラーに対応するHTTPステータス
+func enrollmentErrorStatus(err error) int {
+	switch err {
+	case usecase.ErrorEnrollmentExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateEnrollment 履修登録
+// POST /api/v1/enrollments
+func (h *AdminHandler) CreateEnrollment(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateEnrollmentRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateEnrollment] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.UserID == "" || request.SubjectID == "" {
+		log.Printf("[CreateEnrollment] org_id, user_id, subject_idは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, user_id, subject_idは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	enrollment, err := h.enrollmentUsecase.CreateEnrollment(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateEnrollment] 履修登録エラー: %v\n", err)
+		return c.JSON(enrollmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, enrollment)
+}
+
+// GetEnrollments 履修登録一覧取得
+// GET /api/v1/enrollments/:org_id?user_id=...&subject_id=...
+func (h *AdminHandler) GetEnrollments(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetEnrollments] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	enrollments, err := h.enrollmentUsecase.GetEnrollmentsByOrgID(ctx, orgID, c.QueryParam("user_id"), c.QueryParam("subject_id"))
+	if err != nil {
+		log.Printf("[GetEnrollments] 履修登録一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(enrollmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, enrollments)
+}
+
+// DeleteEnrollment 履修登録削除
+// DELETE /api/v1/enrollments/:org_id/:enrollment_id
+func (h *AdminHandler) DeleteEnrollment(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	enrollmentID := c.Param("enrollment_id")
+
+	if orgID == "" || enrollmentID == "" {
+		log.Printf("[DeleteEnrollment] 組織IDまたは履修登録IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと履修登録IDは必須です"})
+	}
+
+	if err := h.enrollmentUsecase.DeleteEnrollment(ctx, orgID, enrollmentID); err != nil {
+		log.Printf("[DeleteEnrollment] 履修登録削除エラー: %v, orgID: %s, enrollmentID: %s\n", err, orgID, enrollmentID)
+		return c.JSON(enrollmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "履修登録が削除されました"})
+}
+
+// groupErrorStatus クラスのエラーに対応するHTTPステータスを返す
+func groupErrorStatus(err error) int {
+	switch err {
+	case usecase.ErrorInvalidDate, usecase.ErrorGroupNoMembers, usecase.ErrorNotGroupMember:
+		return http.StatusBadRequest
+	case usecase.ErrorGroupExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateGroup クラス作成
+// POST /api/v1/groups
+func (h *AdminHandler) CreateGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateGroupRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateGroup] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.Name == "" {
+		log.Printf("[CreateGroup] org_id, nameは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, nameは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	group, err := h.groupUsecase.CreateGroup(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateGroup] クラス作成エラー: %v\n", err)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, group)
+}
+
+// GetGroups クラス一覧取得
+// GET /api/v1/groups/:org_id?year=2025
+func (h *AdminHandler) GetGroups(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetGroups] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	year := 0
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "yearの形式が不正です"})
+		}
+		year = parsed
+	}
+
+	groups, err := h.groupUsecase.GetGroupsByOrgID(ctx, orgID, year)
+	if err != nil {
+		log.Printf("[GetGroups] クラス一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, groups)
+}
+
+// UpdateGroup クラス更新
+// PUT /api/v1/groups/:org_id/:group_id
+func (h *AdminHandler) UpdateGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	if orgID == "" || groupID == "" {
+		log.Printf("[UpdateGroup] 組織IDまたはクラスIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDとクラスIDは必須です"})
+	}
+
+	var request usecase.UpdateGroupRequest
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[UpdateGroup] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "nameは必須です"})
+	}
+
+	group, err := h.groupUsecase.UpdateGroup(ctx, orgID, groupID, &request)
+	if err != nil {
+		log.Printf("[UpdateGroup] クラス更新エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup クラス削除
+// DELETE /api/v1/groups/:org_id/:group_id
+func (h *AdminHandler) DeleteGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	if orgID == "" || groupID == "" {
+		log.Printf("[DeleteGroup] 組織IDまたはクラスIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDとクラスIDは必須です"})
+	}
+
+	if err := h.groupUsecase.DeleteGroup(ctx, orgID, groupID); err != nil {
+		log.Printf("[DeleteGroup] クラス削除エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "クラスが削除されました"})
+}
+
+// AddGroupMembers クラスへのユーザー所属追加
+// POST /api/v1/groups/:org_id/:group_id/members
+func (h *AdminHandler) AddGroupMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	var request usecase.AddGroupMembersRequest
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[AddGroupMembers] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	members, err := h.groupUsecase.AddGroupMembers(ctx, orgID, groupID, &request)
+	if err != nil {
+		log.Printf("[AddGroupMembers] クラス所属追加エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, members)
+}
+
+// GetGroupMembers クラスの所属ユーザー一覧取得
+// GET /api/v1/groups/:org_id/:group_id/members
+func (h *AdminHandler) GetGroupMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	members, err := h.groupUsecase.GetGroupMembers(ctx, orgID, groupID)
+	if err != nil {
+		log.Printf("[GetGroupMembers] クラス所属一覧取得エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// RemoveGroupMember クラスからのユーザー所属削除
+// DELETE /api/v1/groups/:org_id/:group_id/members/:user_id
+func (h *AdminHandler) RemoveGroupMember(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+	userID := c.Param("user_id")
+
+	if err := h.groupUsecase.RemoveGroupMember(ctx, orgID, groupID, userID); err != nil {
+		log.Printf("[RemoveGroupMember] クラス所属削除エラー: %v, groupID: %s, userID: %s\n", err, groupID, userID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "クラスの所属が削除されました"})
+}
+
+// GetGroupLessons クラスに割り当てた授業一覧取得
+// GET /api/v1/groups/:org_id/:group_id/lessons
+func (h *AdminHandler) GetGroupLessons(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	lessons, err := h.groupUsecase.GetGroupLessons(ctx, orgID, groupID)
+	if err != nil {
+		log.Printf("[GetGroupLessons] クラスの授業一覧取得エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, lessons)
+}
+
+// AssignGroupLesson 授業をクラスに割り当て
+// POST /api/v1/groups/:org_id/:group_id/lessons/:lesson_id
+func (h *AdminHandler) AssignGroupLesson(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+	lessonID := c.Param("lesson_id")
+
+	lesson, err := h.groupUsecase.AssignLesson(ctx, orgID, groupID, lessonID)
+	if err != nil {
+		log.Printf("[AssignGroupLesson] 授業の割り当てエラー: %v, groupID: %s, lessonID: %s\n", err, groupID, lessonID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, lesson)
+}
+
+// UnassignGroupLesson 授業のクラスへの割り当て解除
+// DELETE /api/v1/groups/:org_id/:group_id/lessons/:lesson_id
+func (h *AdminHandler) UnassignGroupLesson(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+	lessonID := c.Param("lesson_id")
+
+	lesson, err := h.groupUsecase.UnassignLesson(ctx, orgID, groupID, lessonID)
+	if err != nil {
+		log.Printf("[UnassignGroupLesson] 授業の割り当て解除エラー: %v, groupID: %s, lessonID: %s\n", err, groupID, lessonID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, lesson)
+}
+
+// GetGroupTimetable クラスの時間割取得
+// GET /api/v1/groups/:org_id/:group_id/timetable?date=2025-10-10
+func (h *AdminHandler) GetGroupTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	timetable, err := h.groupUsecase.GetGroupTimetable(ctx, orgID, groupID, c.QueryParam("date"))
+	if err != nil {
+		log.Printf("[GetGroupTimetable] クラスの時間割取得エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, timetable)
+}
+
+// GetGroupAttendance クラスの出席状況取得
+// GET /api/v1/groups/:org_id/:group_id/attendance?date=2025-10-10
+func (h *AdminHandler) GetGroupAttendance(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	groupID := c.Param("group_id")
+
+	attendance, err := h.groupUsecase.GetGroupAttendance(ctx, orgID, groupID, c.QueryParam("date"))
+	if err != nil {
+		log.Printf("[GetGroupAttendance] クラスの出席状況取得エラー: %v, orgID: %s, groupID: %s\n", err, orgID, groupID)
+		return c.JSON(groupErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, attendance)
+}
+
+// teachingAssignmentErrorStatus 教員の担当のエラーに対応するHTTPステータスを返す
+func teachingAssignmentErrorStatus(err error) int {
+	switch err {
+	case usecase.ErrorTeachingAssignmentRequired, usecase.ErrorNotTeacher:
+		return http.StatusBadRequest
+	case usecase.ErrorTeachingAssignmentExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateTeachingAssignment 教員の担当登録
+// POST /api/v1/teaching-assignments
+func (h *AdminHandler) CreateTeachingAssignment(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateTeachingAssignmentRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateTeachingAssignment] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.OrgID == "" || request.UserID == "" {
+		log.Printf("[CreateTeachingAssignment] org_id, user_idは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_id, user_idは必須です"})
+	}
+
+	// 自組織以外のリソースは作成できない
+	if !authorizeOrg(c, request.OrgID) {
+		return forbidden(c)
+	}
+
+	assignment, err := h.teacherUsecase.CreateTeachingAssignment(ctx, &request)
+	if err != nil {
+		log.Printf("[CreateTeachingAssignment] 教員の担当登録エラー: %v\n", err)
+		return c.JSON(teachingAssignmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, assignment)
+}
+
+// GetTeachingAssignments 教員の担当一覧取得
+// GET /api/v1/teaching-assignments/:org_id?user_id=...
+func (h *AdminHandler) GetTeachingAssignments(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+
+	if orgID == "" {
+		log.Printf("[GetTeachingAssignments] 組織IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDが指定されていません"})
+	}
+
+	assignments, err := h.teacherUsecase.GetTeachingAssignmentsByOrgID(ctx, orgID, c.QueryParam("user_id"))
+	if err != nil {
+		log.Printf("[GetTeachingAssignments] 教員の担当一覧取得エラー: %v, orgID: %s\n", err, orgID)
+		return c.JSON(teachingAssignmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, assignments)
+}
+
+// DeleteTeachingAssignment 教員の担当削除
+// DELETE /api/v1/teaching-assignments/:org_id/:assignment_id
+func (h *AdminHandler) DeleteTeachingAssignment(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	assignmentID := c.Param("assignment_id")
+
+	if orgID == "" || assignmentID == "" {
+		log.Printf("[DeleteTeachingAssignment] 組織IDまたは担当IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "組織IDと担当IDは必須です"})
+	}
+
+	if err := h.teacherUsecase.DeleteTeachingAssignment(ctx, orgID, assignmentID); err != nil {
+		log.Printf("[DeleteTeachingAssignment] 教員の担当削除エラー: %v, orgID: %s, assignmentID: %s\n", err, orgID, assignmentID)
+		return c.JSON(teachingAssignmentErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "教員の担当が削除されました"})
+}
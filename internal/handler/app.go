@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
 	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
 
@@ -18,10 +20,12 @@ type AppHandler struct {
 	authUsecase         *usecase.AppAuthUsecase
 	stayLogUsecase      *usecase.StayLogUsecase
 	attendanceUsecase   *usecase.AttendanceUsecase
-	lessonService       *service.LessonService
+	occurrenceService   *service.LessonOccurrenceService
 	deviceService       *service.DeviceService
 	stayService         *service.StayService
 	organizationService *service.OrganizationService
+	roomUsecase         *usecase.RoomUsecase
+	subjectService      *service.SubjectService
 }
 
 // NewAppHandler アプリ向けハンドラーを作成
@@ -29,33 +33,58 @@ func NewAppHandler(
 	authUsecase *usecase.AppAuthUsecase,
 	stayLogUsecase *usecase.StayLogUsecase,
 	attendanceUsecase *usecase.AttendanceUsecase,
-	lessonService *service.LessonService,
+	occurrenceService *service.LessonOccurrenceService,
 	deviceService *service.DeviceService,
 	stayService *service.StayService,
 	organizationService *service.OrganizationService,
+	roomUsecase *usecase.RoomUsecase,
+	subjectService *service.SubjectService,
 ) *AppHandler {
 	return &AppHandler{
 		authUsecase:         authUsecase,
 		stayLogUsecase:      stayLogUsecase,
 		attendanceUsecase:   attendanceUsecase,
-		lessonService:       lessonService,
+		occurrenceService:   occurrenceService,
 		deviceService:       deviceService,
 		stayService:         stayService,
 		organizationService: organizationService,
+		roomUsecase:         roomUsecase,
+		subjectService:      subjectService,
 	}
 }
 
-// DeviceRegister デバイス登録（管理画面で作成済みのユーザーが前提）
+// deviceRegisterErrorStatus デバイス登録のエラーに対応するHTTPステータスを返す
+func deviceRegisterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrorUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrorInvalidPublicKey),
+		errors.Is(err, usecase.ErrorInvalidRegistration),
+		errors.Is(err, usecase.ErrorInvalidCode):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrorAlreadyRegistered), errors.Is(err, usecase.ErrorDeviceInUse):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrorCodeRecentlySent), errors.Is(err, usecase.ErrorTooManyAttempts):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DeviceRegister デバイス登録の開始（管理画面で作成済みのユーザーが前提）
 // POST /device-register
 //
+// ユーザーのメールアドレスに確認コードを送信する。デバイスは確認コードを検証してから登録される（VerifyDeviceRegister）
+//
 // リクエスト:
 // - mail: メールアドレス（必須）
 // - device_id: デバイスID（必須）
+// - public_key: デバイスで生成した公開鍵（必須：SPKI DERのbase64。ECDSA P-256またはEd25519）
 // - org_id: 組織ID（オプション：複数組織に同じメールアドレスがある場合に指定）
 //
 // レスポンス:
-//   - 1つだけユーザーが見つかった場合、または org_id を指定した場合:
-//     { "user": {...}, "device": {...} }
+//   - 1つだけユーザーが見つかった場合、または org_id を指定した場合（202 Accepted）:
+//     { "registration_id": "...", "expires_at": "...", "message": "..." }
 //   - 複数の組織に同じメールアドレスのユーザーが見つかった場合:
 //     { "message": "...", "organizations": [...], "requires_org_id": true }
 func (h *AppHandler) DeviceRegister(c echo.Context) error {
@@ -77,16 +106,15 @@ func (h *AppHandler) DeviceRegister(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "device_idは必須です"})
 	}
 
+	if request.PublicKey == "" {
+		log.Printf("[DeviceRegister] 公開鍵が空です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "public_keyは必須です"})
+	}
+
 	response, err := h.authUsecase.DeviceRegister(ctx, &request)
 	if err != nil {
 		log.Printf("[DeviceRegister] デバイス登録エラー: %v\n", err)
-
-		// ユーザーが見つからない場合は404
-		if err == usecase.ErrorUserNotFound {
-			return c.JSON(http.StatusNotFound, map[string]string{"error": "ユーザーが見つかりません"})
-		}
-
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(deviceRegisterErrorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	// 複数組織が見つかった場合は、ステータスコード 300 Multiple Choices を返す
@@ -94,7 +122,44 @@ func (h *AppHandler) DeviceRegister(c echo.Context) error {
 		return c.JSON(http.StatusMultipleChoices, multipleOrgs)
 	}
 
-	// 正常にデバイス登録できた場合
+	// 確認コードを送信した場合
+	return c.JSON(http.StatusAccepted, response)
+}
+
+// VerifyDeviceRegister 確認コードを検証してデバイスを登録
+// POST /app/device-register/verify
+//
+// リクエスト:
+// - registration_id: DeviceRegisterで返したID（必須）
+// - code: メールで届いた確認コード（必須）
+//
+// レスポンス:
+//
+//	{ "user": {...}, "device": {...}, "session": { "token": "...", "token_type": "Bearer", "expires_at": "..." } }
+//
+// 以降のアプリ向けAPIは Authorization: Bearer <token> と X-Device-ID: <device_id> を指定する
+// デバイスは非アクティブで登録されるため、チャレンジに署名してアクティベーションする
+func (h *AppHandler) VerifyDeviceRegister(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.DeviceRegisterVerifyRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[VerifyDeviceRegister] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.RegistrationID == "" || request.Code == "" {
+		log.Printf("[VerifyDeviceRegister] registration_idとcodeは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "registration_idとcodeは必須です"})
+	}
+
+	response, err := h.authUsecase.VerifyDeviceRegister(ctx, &request)
+	if err != nil {
+		log.Printf("[VerifyDeviceRegister] 確認コードの検証エラー: %v, registrationID: %s\n", err, request.RegistrationID)
+		return c.JSON(deviceRegisterErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[VerifyDeviceRegister] デバイスを登録しました: User=%s, Device=%s", response.User.ID, response.Device.ID)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -156,31 +221,64 @@ func (h *AppHandler) GetStayLogs(c echo.Context) error {
 	return c.JSON(http.StatusOK, stays)
 }
 
+// deviceActivationErrorStatus デバイスアクティベーションのエラーに対応するHTTPステータスを返す
+func deviceActivationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrorInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrorInvalidChallenge), errors.Is(err, usecase.ErrorPublicKeyMissing):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrorRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DeviceChallenge デバイスアクティベーションのチャレンジ発行
+// POST /app/device/challenge
+//
+// レスポンス: { "challenge_id": "...", "nonce": "...", "expires_at": "..." }
+func (h *AppHandler) DeviceChallenge(c echo.Context) error {
+	ctx := c.Request().Context()
+	identity := appIdentityFrom(c)
+
+	challenge, err := h.authUsecase.IssueChallenge(ctx, identity)
+	if err != nil {
+		log.Printf("[DeviceChallenge] チャレンジ発行エラー: %v, userID: %s\n", err, identity.UserID)
+		return c.JSON(deviceActivationErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, challenge)
+}
+
 // DeviceActivate デバイスアクティベーション（生体認証）
 // POST /app/device/activate
+//
+// 生体認証で保護されたデバイスの秘密鍵でチャレンジのnonceに署名し、登録済みの公開鍵で検証できた場合のみアクティブにする
+//
+// リクエスト:
+// - challenge_id: チャレンジID（必須）
+// - signature: nonceの署名（必須：base64）
 func (h *AppHandler) DeviceActivate(c echo.Context) error {
 	ctx := c.Request().Context()
+	identity := appIdentityFrom(c)
 
-	var request struct {
-		DeviceID string `json:"device_id"`
-		UserID   string `json:"user_id"`
-	}
-
+	var request usecase.DeviceActivateRequest
 	if err := c.Bind(&request); err != nil {
 		log.Printf("[DeviceActivate] リクエストの解析に失敗しました: %v\n", err)
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
 	}
 
-	if request.DeviceID == "" || request.UserID == "" {
-		log.Printf("[DeviceActivate] device_idとuser_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "device_idとuser_idは必須です"})
+	if request.ChallengeID == "" || request.Signature == "" {
+		log.Printf("[DeviceActivate] challenge_idとsignatureは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "challenge_idとsignatureは必須です"})
 	}
 
-	// デバイスをアクティベーション
-	device, err := h.deviceService.ActivateWithAuthentication(ctx, request.DeviceID)
+	device, err := h.authUsecase.ActivateDevice(ctx, identity, &request)
 	if err != nil {
-		log.Printf("[DeviceActivate] デバイスアクティベーションエラー: %v\n", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "デバイスのアクティベーションに失敗しました"})
+		log.Printf("[DeviceActivate] デバイスアクティベーションエラー: %v, userID: %s\n", err, identity.UserID)
+		return c.JSON(deviceActivationErrorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -259,14 +357,9 @@ func (h *AppHandler) RunDailyBatch(c echo.Context) error {
 func (h *AppHandler) GetLessonsToday(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	userID := c.QueryParam("user_id")
+	userID := appIdentityFrom(c).UserID
 	dateStr := c.QueryParam("date")
 
-	if userID == "" {
-		log.Printf("[GetLessonsToday] user_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_idは必須です"})
-	}
-
 	// 日付のパース（省略時は今日）
 	var date time.Time
 	var err error
@@ -280,25 +373,37 @@ func (h *AppHandler) GetLessonsToday(c echo.Context) error {
 		}
 	}
 
-	// 時間割を取得
-	lessons, err := h.lessonService.GetByUserAndDate(ctx, userID, date)
+	// カレンダー上の授業実施状況（休日・学期外・特別時間割）を取得
+	schoolDay, err := h.occurrenceService.GetSchoolDayByUser(ctx, userID, date)
+	if err != nil {
+		log.Printf("[GetLessonsToday] カレンダー取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "時間割の取得に失敗しました"})
+	}
+
+	// 時間割（その日の実施回。授業変更で休講になったものを含む）を取得
+	occurrences, err := h.occurrenceService.GetTimetableByUser(ctx, userID, date)
 	if err != nil {
 		log.Printf("[GetLessonsToday] 時間割取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "時間割の取得に失敗しました"})
 	}
 
 	// リレーションを整形したレスポンス
-	formattedLessons := make([]map[string]interface{}, len(lessons))
-	for i, lesson := range lessons {
+	formattedLessons := make([]map[string]interface{}, len(occurrences))
+	for i, occurrence := range occurrences {
+		lesson := occurrence.LessonOnDate()
 		formattedLessons[i] = map[string]interface{}{
-			"id":          lesson.ID,
-			"subject_id":  lesson.SubjectID,
-			"room_id":     lesson.RoomID,
-			"org_id":      lesson.OrgID,
-			"day_of_week": lesson.DayOfWeek,
-			"start_time":  lesson.StartTime,
-			"end_time":    lesson.EndTime,
-			"period":      lesson.Period,
+			"id":            lesson.ID,
+			"occurrence_id": occurrence.ID,
+			"subject_id":    lesson.SubjectID,
+			"room_id":       lesson.RoomID,
+			"org_id":        lesson.OrgID,
+			"day_of_week":   lesson.DayOfWeek,
+			"start_time":    lesson.StartTime,
+			"end_time":      lesson.EndTime,
+			"period":        lesson.Period,
+			"status":        occurrence.Status,
+			"reason":        occurrence.Reason,
+			"overridden":    occurrence.Overridden,
 			"subject": map[string]interface{}{
 				"id":   lesson.Subject.ID,
 				"name": lesson.Subject.Name,
@@ -314,10 +419,14 @@ func (h *AppHandler) GetLessonsToday(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"date":          date.Format("2006-01-02"),
-		"day_of_week":   int(date.Weekday()),
-		"lessons":       formattedLessons,
-		"total_lessons": len(lessons),
+		"date":             date.Format("2006-01-02"),
+		"day_of_week":      int(date.Weekday()),
+		"schedule_weekday": schoolDay.DayOfWeek,
+		"open":             schoolDay.Open,
+		"special":          schoolDay.Special,
+		"reason":           schoolDay.Reason,
+		"lessons":          formattedLessons,
+		"total_lessons":    len(occurrences),
 	})
 }
 
@@ -326,14 +435,9 @@ func (h *AppHandler) GetLessonsToday(c echo.Context) error {
 func (h *AppHandler) GetAttendanceToday(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	userID := c.QueryParam("user_id")
+	userID := appIdentityFrom(c).UserID
 	dateStr := c.QueryParam("date")
 
-	if userID == "" {
-		log.Printf("[GetAttendanceToday] user_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_idは必須です"})
-	}
-
 	// 日付のパース（省略時は今日）
 	var date time.Time
 	var err error
@@ -358,13 +462,14 @@ func (h *AppHandler) GetAttendanceToday(c echo.Context) error {
 	formattedRecords := make([]map[string]interface{}, len(records))
 	for i, record := range records {
 		lessonData := map[string]interface{}{
-			"id":          record.Lesson.ID,
-			"subject_id":  record.Lesson.SubjectID,
-			"room_id":     record.Lesson.RoomID,
-			"day_of_week": record.Lesson.DayOfWeek,
-			"start_time":  record.Lesson.StartTime,
-			"end_time":    record.Lesson.EndTime,
-			"period":      record.Lesson.Period,
+			"id":            record.Lesson.ID,
+			"occurrence_id": record.OccurrenceID,
+			"subject_id":    record.Lesson.SubjectID,
+			"room_id":       record.Lesson.RoomID,
+			"day_of_week":   record.Lesson.DayOfWeek,
+			"start_time":    record.Lesson.StartTime,
+			"end_time":      record.Lesson.EndTime,
+			"period":        record.Lesson.Period,
 			"subject": map[string]interface{}{
 				"id":   record.Lesson.Subject.ID,
 				"name": record.Lesson.Subject.Name,
@@ -397,11 +502,13 @@ func (h *AppHandler) GetAttendanceToday(c echo.Context) error {
 
 // CreateManualStay 手動入室
 // POST /app/stays/manual
+//
+// 現在の授業に自動で紐付ける。同じ授業・同じ日の入室記録（自動記録を含む）が既にある場合は
+// 409 Conflict を返し、既存の滞在IDを stay_id に含める。
 func (h *AppHandler) CreateManualStay(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	var request struct {
-		UserID    string `json:"user_id"`
 		RoomID    string `json:"room_id"`
 		SubjectID string `json:"subject_id"`
 	}
@@ -411,49 +518,93 @@ func (h *AppHandler) CreateManualStay(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
 	}
 
-	if request.UserID == "" || request.RoomID == "" || request.SubjectID == "" {
-		log.Printf("[CreateManualStay] user_id、room_id、subject_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id、room_id、subject_idは必須です"})
+	if request.RoomID == "" || request.SubjectID == "" {
+		log.Printf("[CreateManualStay] room_id、subject_idは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "room_id、subject_idは必須です"})
 	}
 
-	// 前のアクティブな滞在を終了
-	previousStay, _ := h.stayService.GetActiveByUserID(ctx, request.UserID)
-	if previousStay != nil {
-		previousStay.IsActive = false
-		now := time.Now()
-		previousStay.LeavedAt = &now
-		if err := h.stayService.Update(ctx, previousStay, previousStay.SubjectID, previousStay.Description); err != nil {
-			log.Printf("[CreateManualStay] 前の滞在終了エラー: %v\n", err)
-		}
+	identity := appIdentityFrom(c)
+	userID := identity.UserID
+
+	// 部屋・教科がユーザーの組織に属しているか確認
+	if _, err := h.roomUsecase.GetRoom(ctx, identity.OrgID, request.RoomID); err != nil {
+		log.Printf("[CreateManualStay] 部屋取得エラー: %v, roomID: %s\n", err, request.RoomID)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "room_idが不正です"})
+	}
+	subject, err := h.subjectService.GetByID(ctx, request.SubjectID)
+	if err != nil || subject.OrgID != identity.OrgID {
+		log.Printf("[CreateManualStay] 教科が組織に属していません: %v, subjectID: %s\n", err, request.SubjectID)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "subject_idが不正です"})
 	}
 
-	// 現在時刻と部屋から授業を検索（自動紐付け）
+	// 現在時刻と部屋から授業の実施回を検索（自動紐付け）
 	now := time.Now()
-	var lessonIDPtr *string
-	lesson, err := h.lessonService.GetByRoomAndTime(ctx, request.RoomID, now)
-	if err == nil && lesson != nil {
-		lessonIDPtr = &lesson.ID
-		log.Printf("[CreateManualStay] 授業を自動検出: Lesson=%s, Subject=%s", lesson.ID, lesson.SubjectID)
+	var lessonIDPtr, occurrenceIDPtr *string
+	var lessonDatePtr *time.Time
+	occurrence, err := h.occurrenceService.GetByRoomAndTime(ctx, request.RoomID, now)
+	if err == nil && occurrence != nil && occurrence.OrgID == identity.OrgID {
+		lessonIDPtr = &occurrence.LessonID
+		occurrenceIDPtr = &occurrence.ID
+		lessonDatePtr = &occurrence.Date
+		log.Printf("[CreateManualStay] 授業を自動検出: Lesson=%s, Occurrence=%s, Subject=%s", occurrence.LessonID, occurrence.ID, occurrence.SubjectID)
+
+		// 同じ授業の入室記録が既にある場合は重複して作成しない
+		existing, err := h.stayService.GetByUserLessonDate(ctx, userID, occurrence.LessonID, occurrence.Date)
+		if err == nil {
+			log.Printf("[CreateManualStay] 入室記録が重複しています: UserID=%s, Lesson=%s, StayID=%d", userID, occurrence.LessonID, existing.ID)
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":   "この授業の入室記録は既に存在します",
+				"stay_id": existing.ID,
+			})
+		}
+		if err != repository.ErrorRecordNotFound {
+			log.Printf("[CreateManualStay] 入室記録の確認エラー: %v\n", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "入室記録に失敗しました"})
+		}
 	}
 
+	// 前のアクティブな滞在（新しい滞在を作成できた場合のみ終了する）
+	previousStay, _ := h.stayService.GetActiveByUserID(ctx, userID)
+
 	// 新しい滞在を作成
 	stay := &model.Stay{
-		UserID:    request.UserID,
-		RoomID:    request.RoomID,
-		SubjectID: request.SubjectID,
-		LessonID:  lessonIDPtr,
-		Source:    "manual",
-		IsActive:  true,
-		CreatedAt: now,
-	}
-
-	if err := h.stayService.CreateWithLesson(ctx, stay); err != nil {
+		UserID:       userID,
+		RoomID:       request.RoomID,
+		SubjectID:    request.SubjectID,
+		LessonID:     lessonIDPtr,
+		OccurrenceID: occurrenceIDPtr,
+		LessonDate:   lessonDatePtr,
+		Source:       "manual",
+		IsActive:     true,
+		CreatedAt:    now,
+	}
+
+	created, err := h.stayService.CreateWithLesson(ctx, stay)
+	if err != nil {
 		log.Printf("[CreateManualStay] 滞在作成エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "入室記録に失敗しました"})
 	}
+	if !created {
+		// 確認後に自動記録が作成された場合
+		log.Printf("[CreateManualStay] 入室記録が重複しています: UserID=%s, StayID=%d", stay.UserID, stay.ID)
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error":   "この授業の入室記録は既に存在します",
+			"stay_id": stay.ID,
+		})
+	}
 
 	log.Printf("[CreateManualStay] 作成成功: StayID=%d, UserID=%s", stay.ID, stay.UserID)
 
+	// 前のアクティブな滞在を終了
+	if previousStay != nil {
+		previousStay.IsActive = false
+		leavedAt := time.Now()
+		previousStay.LeavedAt = &leavedAt
+		if err := h.stayService.Update(ctx, previousStay, previousStay.SubjectID, previousStay.Description); err != nil {
+			log.Printf("[CreateManualStay] 前の滞在終了エラー: %v\n", err)
+		}
+	}
+
 	// Preloadを含めて再取得
 	createdStay, err := h.stayService.GetByID(ctx, stay.ID)
 	if err != nil {
@@ -465,15 +616,16 @@ func (h *AppHandler) CreateManualStay(c echo.Context) error {
 
 	// リレーションを整形したレスポンス
 	stayData := map[string]interface{}{
-		"id":         createdStay.ID,
-		"user_id":    createdStay.UserID,
-		"room_id":    createdStay.RoomID,
-		"subject_id": createdStay.SubjectID,
-		"lesson_id":  createdStay.LessonID,
-		"source":     createdStay.Source,
-		"is_active":  createdStay.IsActive,
-		"created_at": createdStay.CreatedAt,
-		"leaved_at":  createdStay.LeavedAt,
+		"id":          createdStay.ID,
+		"user_id":     createdStay.UserID,
+		"room_id":     createdStay.RoomID,
+		"subject_id":  createdStay.SubjectID,
+		"lesson_id":   createdStay.LessonID,
+		"lesson_date": createdStay.LessonDate,
+		"source":      createdStay.Source,
+		"is_active":   createdStay.IsActive,
+		"created_at":  createdStay.CreatedAt,
+		"leaved_at":   createdStay.LeavedAt,
 		"room": map[string]interface{}{
 			"id":           createdStay.Room.ID,
 			"org_room_id":  createdStay.Room.OrgRoomID,
@@ -493,10 +645,14 @@ func (h *AppHandler) CreateManualStay(c echo.Context) error {
 
 	// Lessonがある場合のみ追加
 	if createdStay.Lesson != nil {
+		startTime, endTime := createdStay.Lesson.TimesOn(createdStay.CreatedAt)
+		if createdStay.Occurrence != nil {
+			startTime, endTime = createdStay.Occurrence.StartAt, createdStay.Occurrence.EndAt
+		}
 		stayData["lesson"] = map[string]interface{}{
 			"id":          createdStay.Lesson.ID,
-			"start_time":  createdStay.Lesson.StartTime,
-			"end_time":    createdStay.Lesson.EndTime,
+			"start_time":  startTime,
+			"end_time":    endTime,
 			"period":      createdStay.Lesson.Period,
 			"day_of_week": createdStay.Lesson.DayOfWeek,
 			"subject": map[string]interface{}{
@@ -546,19 +702,7 @@ func (h *AppHandler) LeaveStay(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "stay_idの形式が不正です"})
 	}
 
-	var request struct {
-		UserID string `json:"user_id"`
-	}
-
-	if err := c.Bind(&request); err != nil {
-		log.Printf("[LeaveStay] リクエストの解析に失敗しました: %v\n", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
-	}
-
-	if request.UserID == "" {
-		log.Printf("[LeaveStay] user_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_idは必須です"})
-	}
+	userID := appIdentityFrom(c).UserID
 
 	// 滞在を取得
 	stay, err := h.stayService.GetByID(ctx, stayID)
@@ -568,8 +712,8 @@ func (h *AppHandler) LeaveStay(c echo.Context) error {
 	}
 
 	// ユーザーIDを確認
-	if stay.UserID != request.UserID {
-		log.Printf("[LeaveStay] ユーザーID不一致: %s != %s\n", stay.UserID, request.UserID)
+	if stay.UserID != userID {
+		log.Printf("[LeaveStay] ユーザーID不一致: %s != %s\n", stay.UserID, userID)
 		return c.JSON(http.StatusForbidden, map[string]string{"error": "この滞在ログにアクセスする権限がありません"})
 	}
 
@@ -618,11 +762,7 @@ func (h *AppHandler) LeaveStay(c echo.Context) error {
 func (h *AppHandler) GetActiveStay(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	userID := c.QueryParam("user_id")
-	if userID == "" {
-		log.Printf("[GetActiveStay] user_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_idは必須です"})
-	}
+	userID := appIdentityFrom(c).UserID
 
 	// アクティブな滞在を取得
 	stay, err := h.stayService.GetActiveByUserID(ctx, userID)
@@ -658,10 +798,14 @@ func (h *AppHandler) GetActiveStay(c echo.Context) error {
 
 	// Lessonがある場合のみ追加
 	if stay.Lesson != nil {
+		startTime, endTime := stay.Lesson.TimesOn(stay.CreatedAt)
+		if stay.Occurrence != nil {
+			startTime, endTime = stay.Occurrence.StartAt, stay.Occurrence.EndAt
+		}
 		activeStayData["lesson"] = map[string]interface{}{
 			"id":          stay.Lesson.ID,
-			"start_time":  stay.Lesson.StartTime,
-			"end_time":    stay.Lesson.EndTime,
+			"start_time":  startTime,
+			"end_time":    endTime,
 			"period":      stay.Lesson.Period,
 			"day_of_week": stay.Lesson.DayOfWeek,
 		}
@@ -674,16 +818,12 @@ func (h *AppHandler) GetActiveStay(c echo.Context) error {
 	})
 }
 
-// GetUserStays ユーザーの滞在ログ取得
-// GET /app/stays/:user_id
+// GetUserStays 自分の滞在ログ取得
+// GET /app/stays
 func (h *AppHandler) GetUserStays(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	userID := c.Param("user_id")
-	if userID == "" {
-		log.Printf("[GetUserStays] user_idは必須です\n")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_idは必須です"})
-	}
+	userID := appIdentityFrom(c).UserID
 
 	// ユーザーの滞在ログを取得
 	stays, err := h.stayService.GetByUserID(ctx, userID)
@@ -694,3 +834,85 @@ func (h *AppHandler) GetUserStays(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, stays)
 }
+
+// RefreshSession セッショントークンの更新（使用したトークンは失効する）
+// POST /app/session/refresh
+func (h *AppHandler) RefreshSession(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	token := bearerToken(c)
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "認証が必要です"})
+	}
+
+	session, err := h.authUsecase.RotateSession(ctx, token, c.Request().Header.Get(deviceIDHeader))
+	if err != nil {
+		log.Printf("[RefreshSession] セッション更新エラー: %v\n", err)
+		if errors.Is(err, usecase.ErrorInvalidToken) || errors.Is(err, usecase.ErrorDeviceMismatch) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "セッションの更新に失敗しました"})
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
+// Logout セッションの失効
+// POST /app/session/logout
+func (h *AppHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	identity := appIdentityFrom(c)
+
+	if err := h.authUsecase.Logout(ctx, identity); err != nil {
+		log.Printf("[Logout] セッション失効エラー: %v, userID: %s\n", err, identity.UserID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "ログアウトに失敗しました"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "ログアウトしました"})
+}
+
+// RevokeUserSessions ユーザーのアプリのセッションをすべて失効（端末の紛失時など）
+// DELETE /api/v1/users/:org_id/:user_id/sessions
+func (h *AppHandler) RevokeUserSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	userID := c.Param("user_id")
+
+	revoked, err := h.authUsecase.RevokeUserSessions(ctx, orgID, userID)
+	if err != nil {
+		log.Printf("[RevokeUserSessions] セッション失効エラー: %v, orgID: %s, userID: %s\n", err, orgID, userID)
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[RevokeUserSessions] セッションを失効させました: User=%s, 件数=%d", userID, revoked)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "セッションを失効させました",
+		"revoked": revoked,
+	})
+}
+
+// RemoveUserDevices ユーザーのデバイスの登録を解除（機種変更・端末の譲渡など。セッションも失効する）
+// DELETE /api/v1/users/:org_id/:user_id/devices
+func (h *AppHandler) RemoveUserDevices(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.Param("org_id")
+	userID := c.Param("user_id")
+
+	removed, err := h.authUsecase.RemoveUserDevices(ctx, orgID, userID)
+	if err != nil {
+		log.Printf("[RemoveUserDevices] デバイスの登録解除エラー: %v, orgID: %s, userID: %s\n", err, orgID, userID)
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[RemoveUserDevices] デバイスの登録を解除しました: User=%s, 件数=%d", userID, removed)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "デバイスの登録を解除しました",
+		"removed": removed,
+	})
+}
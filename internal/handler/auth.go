@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuthHandler 管理APIの認証・アカウント管理ハンドラー
+type AuthHandler struct {
+	authUsecase *usecase.AuthUsecase
+}
+
+// NewAuthHandler 管理APIの認証・アカウント管理ハンドラーを作成
+func NewAuthHandler(authUsecase *usecase.AuthUsecase) *AuthHandler {
+	return &AuthHandler{
+		authUsecase: authUsecase,
+	}
+}
+
+// authErrorStatus 認証・アカウント管理のエラーに対応するHTTPステータスを返す
+func authErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrorInvalidCredentials), errors.Is(err, usecase.ErrorInvalidToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrorForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrorRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrorAccountExists):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrorInvalidAdminRole),
+		errors.Is(err, usecase.ErrorInvalidAccountMail),
+		errors.Is(err, usecase.ErrorWeakPassword),
+		errors.Is(err, usecase.ErrorAccountOrgRequired),
+		errors.Is(err, usecase.ErrorTeacherUserMissing),
+		errors.Is(err, usecase.ErrorNotTeacher),
+		errors.Is(err, usecase.ErrorDeleteOwnAccount):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Login ログイン（アクセストークンとリフレッシュトークンを発行）
+// POST /api/v1/auth/login
+func (h *AuthHandler) Login(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.LoginRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[Login] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.Mail == "" || request.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mail, passwordは必須です"})
+	}
+
+	tokens, err := h.authUsecase.Login(ctx, &request)
+	if err != nil {
+		log.Printf("[Login] ログインエラー: %v, mail: %s\n", err, request.Mail)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh トークン更新（使用したリフレッシュトークンは失効する）
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.RefreshRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[Refresh] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_tokenは必須です"})
+	}
+
+	tokens, err := h.authUsecase.Refresh(ctx, &request)
+	if err != nil {
+		log.Printf("[Refresh] トークン更新エラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout ログアウト（リフレッシュトークンを失効させる）
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.RefreshRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[Logout] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_tokenは必須です"})
+	}
+
+	if err := h.authUsecase.Logout(ctx, &request); err != nil {
+		log.Printf("[Logout] ログアウトエラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "ログアウトしました"})
+}
+
+// GetMe ログイン中のアカウント取得
+// GET /api/v1/auth/me
+func (h *AuthHandler) GetMe(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	account, err := h.authUsecase.GetAccount(ctx, principalFrom(c))
+	if err != nil {
+		log.Printf("[GetMe] アカウント取得エラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, account)
+}
+
+// ChangePassword 自分のパスワード変更（発行済みのリフレッシュトークンはすべて失効する）
+// PUT /api/v1/auth/password
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.ChangePasswordRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[ChangePassword] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "current_password, new_passwordは必須です"})
+	}
+
+	if err := h.authUsecase.ChangePassword(ctx, principalFrom(c), &request); err != nil {
+		log.Printf("[ChangePassword] パスワード変更エラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "パスワードを変更しました"})
+}
+
+// CreateAccount アカウント作成
+// POST /api/v1/accounts
+func (h *AuthHandler) CreateAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request usecase.CreateAccountRequest
+
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[CreateAccount] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.Mail == "" || request.Password == "" || request.Role == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mail, password, roleは必須です"})
+	}
+
+	account, err := h.authUsecase.CreateAccount(ctx, principalFrom(c), &request)
+	if err != nil {
+		log.Printf("[CreateAccount] アカウント作成エラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, account)
+}
+
+// GetAccounts アカウント一覧取得（スーパー管理者以外は自組織のアカウントのみ）
+// GET /api/v1/accounts?org_id=...
+func (h *AuthHandler) GetAccounts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	accounts, err := h.authUsecase.GetAccounts(ctx, principalFrom(c), c.QueryParam("org_id"))
+	if err != nil {
+		log.Printf("[GetAccounts] アカウント一覧取得エラー: %v\n", err)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, accounts)
+}
+
+// DeleteAccount アカウント削除
+// DELETE /api/v1/accounts/:account_id
+func (h *AuthHandler) DeleteAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+	accountID := c.Param("account_id")
+
+	if err := h.authUsecase.DeleteAccount(ctx, principalFrom(c), accountID); err != nil {
+		log.Printf("[DeleteAccount] アカウント削除エラー: %v, accountID: %s\n", err, accountID)
+		return c.JSON(authErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "アカウントが削除されました"})
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/ical"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxICalUploadSize 取り込むiCalendarファイルの最大サイズ
+const maxICalUploadSize = 5 << 20
+
+// ICalHandler 時間割のiCalendar取り込み・配信ハンドラー
+type ICalHandler struct {
+	importUsecase *usecase.LessonImportUsecase
+	feedUsecase   *usecase.TimetableFeedUsecase
+}
+
+// NewICalHandler 時間割のiCalendar取り込み・配信ハンドラーを作成
+func NewICalHandler(importUsecase *usecase.LessonImportUsecase, feedUsecase *usecase.TimetableFeedUsecase) *ICalHandler {
+	return &ICalHandler{
+		importUsecase: importUsecase,
+		feedUsecase:   feedUsecase,
+	}
+}
+
+// ImportLessons iCalendar（.ics）ファイルから授業を一括作成
+// POST /api/v1/lessons/import（multipart/form-data: org_id, file）
+func (h *ICalHandler) ImportLessons(c echo.Context) error {
+	ctx := c.Request().Context()
+	orgID := c.FormValue("org_id")
+
+	if orgID == "" {
+		log.Printf("[ImportLessons] org_idは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_idは必須です"})
+	}
+
+	// 自組織以外には取り込めない
+	if !authorizeOrg(c, orgID) {
+		return forbidden(c)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Printf("[ImportLessons] ファイルの取得に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "fileは必須です"})
+	}
+	if fileHeader.Size > maxICalUploadSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "ファイルサイズが大きすぎます（最大5MB）"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("[ImportLessons] ファイルを開けませんでした: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ファイルを開けませんでした"})
+	}
+	defer file.Close()
+
+	result, err := h.importUsecase.ImportICal(ctx, orgID, file)
+	if err != nil {
+		log.Printf("[ImportLessons] 授業の取り込みエラー: %v, orgID: %s\n", err, orgID)
+		if errors.Is(err, usecase.ErrorInvalidICal) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[ImportLessons] 授業を取り込みました: orgID: %s, 授業: %d, 授業変更: %d, スキップ: %d\n",
+		orgID, len(result.Lessons), len(result.Overrides), len(result.Skipped))
+	return c.JSON(http.StatusCreated, result)
+}
+
+// GetUserFeed ユーザーの時間割のiCalendar配信
+// GET /api/v1/lessons/feeds/users/:user_id（末尾の.icsは省略可）
+func (h *ICalHandler) GetUserFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := strings.TrimSuffix(c.Param("user_id"), ".ics")
+
+	if userID == "" {
+		log.Printf("[GetUserFeed] ユーザーIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ユーザーIDが指定されていません"})
+	}
+
+	calendar, err := h.feedUsecase.UserFeed(ctx, userID, scopedOrgID(c))
+	if err != nil {
+		log.Printf("[GetUserFeed] 時間割の作成エラー: %v, userID: %s\n", err, userID)
+		return feedErrorResponse(c, err)
+	}
+	return writeCalendar(c, calendar)
+}
+
+// GetRoomFeed 部屋の時間割のiCalendar配信
+// GET /api/v1/lessons/feeds/rooms/:room_id（末尾の.icsは省略可）
+func (h *ICalHandler) GetRoomFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	roomID := strings.TrimSuffix(c.Param("room_id"), ".ics")
+
+	if roomID == "" {
+		log.Printf("[GetRoomFeed] 部屋IDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "部屋IDが指定されていません"})
+	}
+
+	calendar, err := h.feedUsecase.RoomFeed(ctx, roomID, scopedOrgID(c))
+	if err != nil {
+		log.Printf("[GetRoomFeed] 時間割の作成エラー: %v, roomID: %s\n", err, roomID)
+		return feedErrorResponse(c, err)
+	}
+	return writeCalendar(c, calendar)
+}
+
+// GetFeedByToken 購読用のトークンで時間割のiCalendarを配信（認証不要）
+// GET /feeds/:token.ics
+func (h *ICalHandler) GetFeedByToken(c echo.Context) error {
+	ctx := c.Request().Context()
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	if token == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "指定された時間割が見つかりません"})
+	}
+
+	calendar, err := h.feedUsecase.FeedByToken(ctx, token)
+	if err != nil {
+		if !errors.Is(err, repository.ErrorRecordNotFound) {
+			log.Printf("[GetFeedByToken] 時間割の作成エラー: %v\n", err)
+		}
+		return feedErrorResponse(c, err)
+	}
+	return writeCalendar(c, calendar)
+}
+
+// IssueUserFeedToken ユーザーの時間割を購読するURLのトークンを発行（以前のURLは使えなくなる）
+// POST /api/v1/lessons/feeds/users/:user_id/token
+func (h *ICalHandler) IssueUserFeedToken(c echo.Context) error {
+	return h.issueFeedToken(c, model.FeedKindUser, c.Param("user_id"))
+}
+
+// RevokeUserFeedToken ユーザーの時間割を購読するURLを無効化
+// DELETE /api/v1/lessons/feeds/users/:user_id/token
+func (h *ICalHandler) RevokeUserFeedToken(c echo.Context) error {
+	return h.revokeFeedToken(c, model.FeedKindUser, c.Param("user_id"))
+}
+
+// IssueRoomFeedToken 部屋の時間割を購読するURLのトークンを発行（以前のURLは使えなくなる）
+// POST /api/v1/lessons/feeds/rooms/:room_id/token
+func (h *ICalHandler) IssueRoomFeedToken(c echo.Context) error {
+	return h.issueFeedToken(c, model.FeedKindRoom, c.Param("room_id"))
+}
+
+// RevokeRoomFeedToken 部屋の時間割を購読するURLを無効化
+// DELETE /api/v1/lessons/feeds/rooms/:room_id/token
+func (h *ICalHandler) RevokeRoomFeedToken(c echo.Context) error {
+	return h.revokeFeedToken(c, model.FeedKindRoom, c.Param("room_id"))
+}
+
+// issueFeedToken 配信対象の購読用のトークンを発行
+func (h *ICalHandler) issueFeedToken(c echo.Context, kind, targetID string) error {
+	ctx := c.Request().Context()
+
+	token, err := h.feedUsecase.IssueFeedToken(ctx, kind, targetID, scopedOrgID(c))
+	if err != nil {
+		log.Printf("[IssueFeedToken] 配信トークンの発行エラー: %v, kind: %s, targetID: %s\n", err, kind, targetID)
+		return feedErrorResponse(c, err)
+	}
+
+	log.Printf("[IssueFeedToken] 配信トークンを発行しました: kind: %s, targetID: %s\n", kind, targetID)
+	return c.JSON(http.StatusCreated, token)
+}
+
+// revokeFeedToken 配信対象の購読用のトークンを失効
+func (h *ICalHandler) revokeFeedToken(c echo.Context, kind, targetID string) error {
+	ctx := c.Request().Context()
+
+	if err := h.feedUsecase.RevokeFeedToken(ctx, kind, targetID, scopedOrgID(c)); err != nil {
+		log.Printf("[RevokeFeedToken] 配信トークンの失効エラー: %v, kind: %s, targetID: %s\n", err, kind, targetID)
+		return feedErrorResponse(c, err)
+	}
+
+	log.Printf("[RevokeFeedToken] 配信トークンを失効させました: kind: %s, targetID: %s\n", kind, targetID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "購読用のURLを無効化しました"})
+}
+
+// feedErrorResponse 配信エラーのレスポンスを返す
+func feedErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, repository.ErrorRecordNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "指定された時間割が見つかりません"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "時間割の作成に失敗しました"})
+}
+
+// writeCalendar iCalendarをレスポンスとして書き出す
+func writeCalendar(c echo.Context, calendar *ical.Component) error {
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf); err != nil {
+		log.Printf("[ICalHandler] iCalendarの書き出しエラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "時間割の作成に失敗しました"})
+	}
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
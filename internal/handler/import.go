@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportUploadSize 一括取り込みファイルの最大サイズ
+const maxImportUploadSize = 10 << 20
+
+// ImportHandler CSV・XLSXの一括取り込みハンドラー
+type ImportHandler struct {
+	bulkImportUsecase *usecase.BulkImportUsecase
+}
+
+// NewImportHandler CSV・XLSXの一括取り込みハンドラーを作成
+func NewImportHandler(bulkImportUsecase *usecase.BulkImportUsecase) *ImportHandler {
+	return &ImportHandler{
+		bulkImportUsecase: bulkImportUsecase,
+	}
+}
+
+// BulkImport ユーザー・部屋・教科・授業・履修登録・クラスの所属をCSV・XLSXから一括登録
+// POST /api/v1/imports/:kind（kind: users, rooms, subjects, lessons, enrollments, group_members）
+// multipart/form-data: org_id, file（.csv, .xlsx）, dry_run（trueの場合は検証のみ）
+//
+// ドライランは200、登録した場合は201、エラー行がある場合は422で取り込み結果を返す
+func (h *ImportHandler) BulkImport(c echo.Context) error {
+	ctx := c.Request().Context()
+	kind := c.Param("kind")
+	orgID := c.FormValue("org_id")
+
+	if orgID == "" {
+		log.Printf("[BulkImport] org_idは必須です\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "org_idは必須です"})
+	}
+
+	// 自組織以外には取り込めない
+	if !authorizeOrg(c, orgID) {
+		return forbidden(c)
+	}
+
+	dryRun := false
+	if value := c.FormValue("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "dry_runの形式が不正です（true/false）"})
+		}
+		dryRun = parsed
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Printf("[BulkImport] ファイルの取得に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "fileは必須です"})
+	}
+	if fileHeader.Size > maxImportUploadSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "ファイルサイズが大きすぎます（最大10MB）"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("[BulkImport] ファイルを開けませんでした: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ファイルを開けませんでした"})
+	}
+	defer file.Close()
+
+	report, err := h.bulkImportUsecase.Import(ctx, &usecase.BulkImportRequest{
+		OrgID:    orgID,
+		Kind:     kind,
+		FileName: fileHeader.Filename,
+		DryRun:   dryRun,
+	}, file, fileHeader.Size)
+	if err != nil {
+		log.Printf("[BulkImport] 一括取り込みエラー: %v, kind: %s, orgID: %s\n", err, kind, orgID)
+		switch {
+		case errors.Is(err, repository.ErrorRecordNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "組織が見つかりません"})
+		case errors.Is(err, usecase.ErrorInvalidImportKind),
+			errors.Is(err, usecase.ErrorInvalidImportFormat),
+			errors.Is(err, usecase.ErrorInvalidImportFile),
+			errors.Is(err, usecase.ErrorTooManyImportRows):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[BulkImport] 一括取り込み: kind: %s, orgID: %s, dryRun: %t, 登録: %t, 成功: %d, 失敗: %d\n",
+		kind, orgID, dryRun, report.Applied, report.Succeeded, report.Failed)
+
+	switch {
+	case report.Failed > 0:
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	case report.Applied:
+		return c.JSON(http.StatusCreated, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
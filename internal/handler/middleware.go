@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// principalKey 認証済みのアカウントを保存するecho.Contextのキー
+const principalKey = "principal"
+
+// appIdentityKey 認証済みのアプリのユーザーを保存するecho.Contextのキー
+const appIdentityKey = "app_identity"
+
+// deviceIDHeader アプリがデバイスIDを送るヘッダー
+const deviceIDHeader = "X-Device-ID"
+
+// Authenticate Authorizationヘッダーのアクセストークン（Bearer）を検証するミドルウェア
+func Authenticate(authUsecase *usecase.AuthUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := bearerToken(c)
+			if token == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "認証が必要です"})
+			}
+
+			principal, err := authUsecase.Authenticate(token)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			}
+
+			c.Set(principalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// AuthenticateApp アプリのセッショントークン（Bearer）と送信元のデバイス（X-Device-ID）を検証するミドルウェア
+func AuthenticateApp(appAuthUsecase *usecase.AppAuthUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := bearerToken(c)
+			if token == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "認証が必要です"})
+			}
+
+			identity, err := appAuthUsecase.Authenticate(c.Request().Context(), token, c.Request().Header.Get(deviceIDHeader))
+			if err != nil {
+				if errors.Is(err, usecase.ErrorInvalidToken) || errors.Is(err, usecase.ErrorDeviceMismatch) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+				}
+				log.Printf("[AuthenticateApp] セッションの検証エラー: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "認証に失敗しました"})
+			}
+
+			c.Set(appIdentityKey, identity)
+			return next(c)
+		}
+	}
+}
+
+// RequireActiveDevice アクティベーション済みのデバイスからのリクエストのみを許可するミドルウェア（AuthenticateAppの後に使用）
+func RequireActiveDevice() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			identity := appIdentityFrom(c)
+			if identity == nil || !identity.DeviceActive {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": usecase.ErrorDeviceInactive.Error()})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireRoles いずれかの役割を持つアカウントのみを許可するミドルウェア（Authenticateの後に使用）
+func RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := principalFrom(c)
+			if principal == nil || !principal.HasRole(roles...) {
+				return forbidden(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireWriteAccess 参照（GET・HEAD）以外の操作をスーパー管理者・組織管理者のみに許可するミドルウェア
+// 教員・監査担当は参照のみできる
+func RequireWriteAccess() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead:
+				return next(c)
+			}
+			principal := principalFrom(c)
+			if principal == nil || !principal.CanWrite() {
+				return forbidden(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// ScopeOrgParam パスの:org_idの組織にアクセスできるアカウントのみを許可するミドルウェア
+// :org_idを含まないルートでは何もしない（リクエストボディなどの組織IDは各ハンドラーで確認する）
+func ScopeOrgParam() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if orgID := c.Param("org_id"); orgID != "" && !authorizeOrg(c, orgID) {
+				return forbidden(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// bearerToken AuthorizationヘッダーのBearerトークン（指定されていない場合は空）
+func bearerToken(c echo.Context) string {
+	token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// appIdentityFrom 認証済みのアプリのユーザーを取得（AuthenticateAppの後に使用）
+func appIdentityFrom(c echo.Context) *usecase.AppIdentity {
+	identity, _ := c.Get(appIdentityKey).(*usecase.AppIdentity)
+	return identity
+}
+
+// principalFrom 認証済みのアカウントを取得（認証していない場合はnil）
+func principalFrom(c echo.Context) *usecase.Principal {
+	principal, _ := c.Get(principalKey).(*usecase.Principal)
+	return principal
+}
+
+// authorizeOrg 認証済みのアカウントが組織にアクセスできるか
+func authorizeOrg(c echo.Context, orgID string) bool {
+	principal := principalFrom(c)
+	return principal != nil && principal.CanAccessOrg(orgID)
+}
+
+// scopedOrgID アカウントがアクセスできる組織ID（スーパー管理者は全組織のため空）
+func scopedOrgID(c echo.Context) string {
+	principal := principalFrom(c)
+	if principal == nil || principal.IsSuperAdmin() {
+		return ""
+	}
+	return principal.OrgID
+}
+
+// forbidden 権限がない場合のレスポンスを返す
+func forbidden(c echo.Context) error {
+	if principal := principalFrom(c); principal != nil {
+		log.Printf("[Auth] 権限がありません: Account=%s, Role=%s, %s %s", principal.AccountID, principal.Role, c.Request().Method, c.Path())
+	}
+	return c.JSON(http.StatusForbidden, map[string]string{"error": usecase.ErrorForbidden.Error()})
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/jwt"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testAuthSecret = []byte("test-secret-0123456789abcdef")
+
+// testAccessToken テスト用のアクセストークンを作成
+func testAccessToken(t *testing.T, issuer, role, orgID string) string {
+	t.Helper()
+	now := time.Now()
+	token, err := jwt.Sign(jwt.Claims{
+		Subject:   "account-1",
+		Issuer:    issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(15 * time.Minute).Unix(),
+		Role:      role,
+		OrgID:     orgID,
+	}, testAuthSecret)
+	if err != nil {
+		t.Fatalf("jwt.Sign() error = %v", err)
+	}
+	return token
+}
+
+func TestScopeOrgParam(t *testing.T) {
+	authUsecase := usecase.NewAuthUsecase(nil, nil, nil, nil, usecase.AuthConfig{
+		Secret:         testAuthSecret,
+		AccessTokenTTL: 15 * time.Minute,
+	})
+
+	e := echo.New()
+	admin := e.Group("/api/v1", Authenticate(authUsecase), RequireWriteAccess(), ScopeOrgParam())
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "ok") }
+	admin.GET("/users/:org_id", ok)
+	admin.POST("/users/:org_id", ok)
+	admin.GET("/lessons/feeds/users/:user_id", ok)
+
+	const adminIssuer = "ed-mist-backend"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{
+			name:   "組織管理者は自組織を参照できる",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-1",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleOrgAdmin, "org-1"),
+			want:   http.StatusOK,
+		},
+		{
+			name:   "組織管理者は他組織を参照できない",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-2",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleOrgAdmin, "org-1"),
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "組織管理者は他組織を変更できない",
+			method: http.MethodPost,
+			path:   "/api/v1/users/org-2",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleOrgAdmin, "org-1"),
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "スーパー管理者は他組織も参照できる",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-2",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleSuperAdmin, ""),
+			want:   http.StatusOK,
+		},
+		{
+			name:   "監査担当は自組織を参照できる",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-1",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleAuditor, "org-1"),
+			want:   http.StatusOK,
+		},
+		{
+			name:   "監査担当は自組織でも変更できない",
+			method: http.MethodPost,
+			path:   "/api/v1/users/org-1",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleAuditor, "org-1"),
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "教員は他組織を参照できない",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-2",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleTeacher, "org-1"),
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   ":org_idを含まないルートでは確認しない",
+			method: http.MethodGet,
+			path:   "/api/v1/lessons/feeds/users/user-1",
+			token:  testAccessToken(t, adminIssuer, model.AdminRoleOrgAdmin, "org-1"),
+			want:   http.StatusOK,
+		},
+		{
+			name:   "アプリのセッショントークンは管理APIで使えない",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-1",
+			token:  testAccessToken(t, "ed-mist-backend/app", model.AdminRoleOrgAdmin, "org-1"),
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "トークンなし",
+			method: http.MethodGet,
+			path:   "/api/v1/users/org-1",
+			want:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d (body: %s)", tt.method, tt.path, rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireActiveDevice(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *usecase.AppIdentity
+		want     int
+	}{
+		{
+			name:     "アクティベーション済みのデバイス",
+			identity: &usecase.AppIdentity{UserID: "user-1", DeviceID: "device-1", DeviceActive: true},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "アクティベーションされていないデバイス",
+			identity: &usecase.AppIdentity{UserID: "user-1", DeviceID: "device-1"},
+			want:     http.StatusForbidden,
+		},
+		{
+			name: "認証されていない",
+			want: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			setIdentity := func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if tt.identity != nil {
+						c.Set(appIdentityKey, tt.identity)
+					}
+					return next(c)
+				}
+			}
+			e.POST("/app/stays/manual", func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			}, setIdentity, RequireActiveDevice())
+
+			req := httptest.NewRequest(http.MethodPost, "/app/stays/manual", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("POST /app/stays/manual = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MistHandler Mist連携の管理向けハンドラー
+type MistHandler struct {
+	mistClient  *mistapi.Client
+	mistUsecase *usecase.MistUsecase
+}
+
+// NewMistHandler Mist連携の管理向けハンドラーを作成
+func NewMistHandler(mistClient *mistapi.Client, mistUsecase *usecase.MistUsecase) *MistHandler {
+	return &MistHandler{
+		mistClient:  mistClient,
+		mistUsecase: mistUsecase,
+	}
+}
+
+// GetAPIStats Mist APIの使用状況（残り予算など）を取得
+// GET /api/v1/mist/stats
+func (h *MistHandler) GetAPIStats(c echo.Context) error {
+	if h.mistClient == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "mist APIクライアントが初期化されていません"})
+	}
+
+	stats := h.mistClient.RateLimitStats()
+	if stats == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"rate_limit_enabled": false,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rate_limit_enabled": true,
+		"stats":              stats,
+	})
+}
+
+// GetMaps 同期済みのマップ一覧取得
+// GET /api/v1/mist/maps
+func (h *MistHandler) GetMaps(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	maps, err := h.mistUsecase.GetMaps(ctx)
+	if err != nil {
+		log.Printf("[GetMaps] マップ一覧取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, maps)
+}
+
+// GetMapImage マップ画像（フロアプラン）取得
+// GET /api/v1/mist/maps/:map_id/image
+func (h *MistHandler) GetMapImage(c echo.Context) error {
+	ctx := c.Request().Context()
+	mapID := c.Param("map_id")
+
+	if mapID == "" {
+		log.Printf("[GetMapImage] マップIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "マップIDが指定されていません"})
+	}
+
+	image, err := h.mistUsecase.GetMapImage(ctx, mapID)
+	if err != nil {
+		log.Printf("[GetMapImage] マップ画像取得エラー: %v, mapID: %s\n", err, mapID)
+		return mistErrorResponse(c, err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	return c.Blob(http.StatusOK, http.DetectContentType(image), image)
+}
+
+// GetFloorPlan 部屋の範囲と在室数を重ねたフロアプラン画像取得
+// GET /api/v1/mist/maps/:map_id/overlay?format=svg|png&dots=true&org_id=
+// 組織に所属するアカウントは自組織の部屋のみラベル付けする（org_idを指定できるのはスーパー管理者のみ）
+func (h *MistHandler) GetFloorPlan(c echo.Context) error {
+	ctx := c.Request().Context()
+	mapID := c.Param("map_id")
+
+	if mapID == "" {
+		log.Printf("[GetFloorPlan] マップIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "マップIDが指定されていません"})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "svg"
+	}
+
+	orgID := scopedOrgID(c)
+	if orgID == "" {
+		orgID = c.QueryParam("org_id")
+	}
+
+	request := &usecase.FloorPlanRequest{
+		MistMapID: mapID,
+		OrgID:     orgID,
+		Format:    format,
+		WithDots:  c.QueryParam("dots") == "true",
+	}
+
+	image, contentType, err := h.mistUsecase.RenderFloorPlan(ctx, request)
+	if err != nil {
+		log.Printf("[GetFloorPlan] フロアプラン描画エラー: %v, mapID: %s\n", err, mapID)
+		if err == usecase.ErrorUnsupportedFormat {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return mistErrorResponse(c, err)
+	}
+
+	// 在室数はリアルタイムのためキャッシュしない
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(http.StatusOK, contentType, image)
+}
+
+// GetZones 同期済みのゾーン一覧取得
+// GET /api/v1/mist/zones?map_id=
+func (h *MistHandler) GetZones(c echo.Context) error {
+	ctx := c.Request().Context()
+	mapID := c.QueryParam("map_id")
+
+	zones, err := h.mistUsecase.GetZones(ctx, mapID)
+	if err != nil {
+		log.Printf("[GetZones] ゾーン一覧取得エラー: %v, mapID: %s\n", err, mapID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, zones)
+}
+
+// GetZoneClients ゾーン内で現在検知されているクライアントとユーザー取得
+// GET /api/v1/mist/zones/:zone_id/clients
+// 組織に所属するアカウントには他の組織のユーザーのデバイスを含めない
+func (h *MistHandler) GetZoneClients(c echo.Context) error {
+	ctx := c.Request().Context()
+	zoneID := c.Param("zone_id")
+
+	if zoneID == "" {
+		log.Printf("[GetZoneClients] ゾーンIDが指定されていません\n")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ゾーンIDが指定されていません"})
+	}
+
+	occupants, err := h.mistUsecase.GetZoneOccupants(ctx, zoneID, scopedOrgID(c))
+	if err != nil {
+		log.Printf("[GetZoneClients] ゾーンクライアント取得エラー: %v, zoneID: %s\n", err, zoneID)
+		return mistErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, occupants)
+}
+
+// SyncMist Mistのマップ・ゾーン定義を即時同期
+// POST /api/v1/mist/sync
+func (h *MistHandler) SyncMist(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	result, err := h.mistUsecase.Sync(ctx)
+	if err != nil {
+		log.Printf("[SyncMist] 同期エラー: %v\n", err)
+		return mistErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// mistErrorResponse Mist APIエラーの種類に応じたレスポンスを返す
+func mistErrorResponse(c echo.Context, err error) error {
+	var notFoundErr *mistapi.NotFoundError
+	var rateErr *mistapi.RateLimitError
+	var transientErr *mistapi.TransientError
+
+	switch {
+	case errors.As(err, &notFoundErr):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.As(err, &rateErr):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "mist APIのレート制限中です。しばらくしてから再度お試しください"})
+	case errors.As(err, &transientErr):
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TeacherHandler 教員向けハンドラー（教員アカウントに対応するユーザーとして操作する）
+type TeacherHandler struct {
+	teacherUsecase *usecase.TeacherUsecase
+}
+
+// NewTeacherHandler 教員向けハンドラーを作成
+func NewTeacherHandler(teacherUsecase *usecase.TeacherUsecase) *TeacherHandler {
+	return &TeacherHandler{
+		teacherUsecase: teacherUsecase,
+	}
+}
+
+// teacherErrorStatus 教員向けAPIのエラーに対応するHTTPステータスを返す
+func teacherErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrorRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrorNotTeacher), errors.Is(err, usecase.ErrorNotLessonTeacher):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrorInvalidAttendanceMark),
+		errors.Is(err, usecase.ErrorNotOnRoster),
+		errors.Is(err, usecase.ErrorOccurrenceCancelled):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetCurrentLessons 担当する実施中の授業一覧取得
+// GET /teacher/lessons/current
+func (h *TeacherHandler) GetCurrentLessons(c echo.Context) error {
+	ctx := c.Request().Context()
+	teacherID := principalFrom(c).UserID
+
+	if teacherID == "" {
+		return forbidden(c)
+	}
+
+	occurrences, err := h.teacherUsecase.GetCurrentLessons(ctx, teacherID, time.Now())
+	if err != nil {
+		log.Printf("[GetCurrentLessons] 実施中の授業取得エラー: %v, teacherID: %s\n", err, teacherID)
+		return c.JSON(teacherErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, occurrences)
+}
+
+// GetLessonRoster 担当する授業の対象者の在室状況取得（在室・遅刻・退室・未入室・公欠）
+// GET /teacher/lessons/:occurrence_id/roster
+func (h *TeacherHandler) GetLessonRoster(c echo.Context) error {
+	ctx := c.Request().Context()
+	teacherID := principalFrom(c).UserID
+	occurrenceID := c.Param("occurrence_id")
+
+	if teacherID == "" {
+		return forbidden(c)
+	}
+
+	roster, err := h.teacherUsecase.GetLessonRoster(ctx, teacherID, occurrenceID)
+	if err != nil {
+		log.Printf("[GetLessonRoster] 在室状況取得エラー: %v, teacherID: %s, occurrenceID: %s\n", err, teacherID, occurrenceID)
+		return c.JSON(teacherErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, roster)
+}
+
+// MarkAttendance 担当する授業の出席を手動で記録（出席扱い・公欠）
+// POST /teacher/lessons/:occurrence_id/marks
+func (h *TeacherHandler) MarkAttendance(c echo.Context) error {
+	ctx := c.Request().Context()
+	teacherID := principalFrom(c).UserID
+	occurrenceID := c.Param("occurrence_id")
+
+	if teacherID == "" {
+		return forbidden(c)
+	}
+
+	var request usecase.MarkAttendanceRequest
+	if err := c.Bind(&request); err != nil {
+		log.Printf("[MarkAttendance] リクエストの解析に失敗しました: %v\n", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストの解析に失敗しました"})
+	}
+
+	if request.StudentID == "" || request.Status == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "student_id, statusは必須です"})
+	}
+
+	mark, err := h.teacherUsecase.MarkAttendance(ctx, teacherID, occurrenceID, &request)
+	if err != nil {
+		log.Printf("[MarkAttendance] 出席の手動記録エラー: %v, teacherID: %s, occurrenceID: %s\n", err, teacherID, occurrenceID)
+		return c.JSON(teacherErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	log.Printf("[MarkAttendance] 出席を手動で記録: Teacher=%s, Student=%s, Occurrence=%s, Status=%s", teacherID, request.StudentID, occurrenceID, request.Status)
+	return c.JSON(http.StatusOK, mark)
+}
+
+// UnmarkAttendance 出席の手動記録を取り消し（滞在ログによる判定に戻す）
+// DELETE /teacher/lessons/:occurrence_id/marks/:student_id
+func (h *TeacherHandler) UnmarkAttendance(c echo.Context) error {
+	ctx := c.Request().Context()
+	teacherID := principalFrom(c).UserID
+	occurrenceID := c.Param("occurrence_id")
+	studentID := c.Param("student_id")
+
+	if teacherID == "" {
+		return forbidden(c)
+	}
+
+	if err := h.teacherUsecase.UnmarkAttendance(ctx, teacherID, occurrenceID, studentID); err != nil {
+		log.Printf("[UnmarkAttendance] 手動記録の取り消しエラー: %v, teacherID: %s, occurrenceID: %s\n", err, teacherID, occurrenceID)
+		return c.JSON(teacherErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "出席の手動記録が取り消されました"})
+}
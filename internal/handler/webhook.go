@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/scheduler"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler Mistからのwebhook受信ハンドラー
+type WebhookHandler struct {
+	secret  string
+	workers *scheduler.Workers
+}
+
+// NewWebhookHandler Mistからのwebhook受信ハンドラーを作成
+func NewWebhookHandler(secret string, workers *scheduler.Workers) *WebhookHandler {
+	return &WebhookHandler{
+		secret:  secret,
+		workers: workers,
+	}
+}
+
+// ReceiveMist Mistのwebhook（zone / location / client-join）を受信
+// POST /webhooks/mist
+func (h *WebhookHandler) ReceiveMist(c echo.Context) error {
+	if h.secret == "" {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "webhookのシークレットが設定されていません"})
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "リクエストボディの読み込みに失敗しました"})
+	}
+
+	if err := mistapi.VerifyWebhookSignature(h.secret, body, c.Request().Header); err != nil {
+		if !errors.Is(err, mistapi.ErrWebhookSignatureMissing) {
+			log.Printf("[Webhook] 署名検証エラー: remote=%s", c.RealIP())
+		}
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	events, err := mistapi.ParseWebhook(body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// リーダー以外はイベントを処理できないため、Mistまたはロードバランサーに別インスタンスへの再送を促す
+	if !h.workers.IsLeader() {
+		log.Printf("[Webhook] リーダーではないため受信を拒否しました: topic=%s", events.Topic)
+		c.Response().Header().Set("Retry-After", "5")
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "このインスタンスはwebhookを処理できません"})
+	}
+
+	for _, event := range events.Zone {
+		h.workers.HandleZoneEvent(event)
+	}
+	for _, event := range events.Location {
+		h.workers.HandleLocationEvent(event)
+	}
+	for _, event := range events.ClientJoin {
+		h.workers.HandleClientJoinEvent(event)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"topic":       events.Topic,
+		"zone":        len(events.Zone),
+		"location":    len(events.Location),
+		"client_join": len(events.ClientJoin),
+		"unsupported": events.Unsupported,
+	})
+}
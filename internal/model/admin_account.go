@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+)
+
+// 管理APIのアカウントの役割
+const (
+	AdminRoleSuperAdmin = "super_admin" // 全組織の管理者
+	AdminRoleOrgAdmin   = "org_admin"   // 自組織の管理者
+	AdminRoleTeacher    = "teacher"     // 教員（自組織の参照と教員向けAPI）
+	AdminRoleAuditor    = "auditor"     // 監査担当（自組織の参照のみ）
+)
+
+// AdminAccount 管理APIのアカウント
+// スーパー管理者以外は1つの組織に属し、その組織のリソースのみを操作できる
+type AdminAccount struct {
+	ID           string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	Mail         string     `gorm:"column:mail;type:varchar(255);not null;uniqueIndex" json:"mail"`
+	PasswordHash string     `gorm:"column:password_hash;type:varchar(255);not null" json:"-"`
+	Role         string     `gorm:"column:role;type:varchar(20);not null;index" json:"role"`
+	OrgID        *string    `gorm:"type:uuid;column:org_id;index" json:"org_id,omitempty"`   // スーパー管理者はnil
+	UserID       *string    `gorm:"type:uuid;column:user_id;index" json:"user_id,omitempty"` // 教員アカウントに対応するユーザー
+	LastLoginAt  *time.Time `gorm:"column:last_login_at" json:"last_login_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Organization *Organization `gorm:"foreignKey:OrgID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	User         *User         `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (AdminAccount) TableName() string {
+	return "admin_accounts"
+}
+
+// IsValidAdminRole 有効なアカウントの役割か
+func IsValidAdminRole(role string) bool {
+	switch role {
+	case AdminRoleSuperAdmin, AdminRoleOrgAdmin, AdminRoleTeacher, AdminRoleAuditor:
+		return true
+	}
+	return false
+}
+
+// RefreshToken リフレッシュトークン（トークンそのものは保存せずハッシュのみを保存）
+// 使用すると失効し、新しいトークンを発行する
+type RefreshToken struct {
+	ID        string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	AccountID string     `gorm:"type:uuid;column:account_id;not null;index" json:"account_id"`
+	TokenHash string     `gorm:"column:token_hash;type:char(64);not null;uniqueIndex" json:"-"` // SHA-256（16進数）
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+
+	// リレーション
+	Account AdminAccount `gorm:"foreignKey:AccountID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsActive 失効しておらず有効期限内か
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
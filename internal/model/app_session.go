@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// AppSession アプリのセッション（デバイス登録時に発行し、登録したデバイスからのみ使用できる）
+// トークンそのものは保存せず、トークンのjtiとしてセッションIDを埋め込む
+type AppSession struct {
+	ID        string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	UserID    string     `gorm:"type:uuid;column:user_id;not null;index" json:"user_id"`
+	DeviceID  string     `gorm:"type:uuid;column:device_id;not null;index" json:"device_id"` // devicesのID
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+
+	// リレーション
+	User   User   `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Device Device `gorm:"foreignKey:DeviceID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (AppSession) TableName() string {
+	return "app_sessions"
+}
+
+// IsActive 失効しておらず有効期限内か
+func (s *AppSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
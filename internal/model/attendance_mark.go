@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// 教員による出席の手動記録の種類
+const (
+	AttendanceMarkPresent = "present" // 出席扱い（検知されなかった場合など）
+	AttendanceMarkExcused = "excused" // 公欠・届出のある欠席
+)
+
+// AttendanceMark 教員による出席の手動記録
+// 滞在ログによる出席判定より優先する（同じユーザー・授業・実施日で1件）
+type AttendanceMark struct {
+	ID           string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID        string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	UserID       string    `gorm:"type:uuid;column:user_id;not null;uniqueIndex:idx_attendance_marks_user_lesson_date,priority:1" json:"user_id"`
+	LessonID     string    `gorm:"type:uuid;column:lesson_id;not null;index;uniqueIndex:idx_attendance_marks_user_lesson_date,priority:2" json:"lesson_id"`
+	LessonDate   time.Time `gorm:"type:date;column:lesson_date;not null;uniqueIndex:idx_attendance_marks_user_lesson_date,priority:3" json:"lesson_date"`
+	OccurrenceID *string   `gorm:"type:uuid;column:occurrence_id;index" json:"occurrence_id,omitempty"`
+	Status       string    `gorm:"column:status;type:varchar(20);not null" json:"status"` // "present" or "excused"
+	Reason       string    `gorm:"column:reason;type:text" json:"reason,omitempty"`
+	MarkedBy     string    `gorm:"type:uuid;column:marked_by;not null" json:"marked_by"` // 記録した教員のユーザーID
+	CreatedAt    time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	User   User   `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Lesson Lesson `gorm:"foreignKey:LessonID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (AttendanceMark) TableName() string {
+	return "attendance_marks"
+}
+
+// IsValidAttendanceMarkStatus 有効な手動記録の種類か
+func IsValidAttendanceMarkStatus(status string) bool {
+	return status == AttendanceMarkPresent || status == AttendanceMarkExcused
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+)
+
+// カレンダー日の種類
+const (
+	CalendarDayHoliday = "holiday" // 休日（授業なし）
+	CalendarDaySpecial = "special" // 特別時間割（別の曜日の時間割で授業を行う）
+)
+
+// Term 学期（時間割の有効期間）
+// 組織に学期が1件以上登録されている場合、いずれの学期にも含まれない日は授業を行わない
+type Term struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null" json:"name"`
+	StartDate time.Time `gorm:"type:date;column:start_date;not null;index" json:"start_date"`
+	EndDate   time.Time `gorm:"type:date;column:end_date;not null;index" json:"end_date"` // この日を含む
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Organization Organization `gorm:"foreignKey:OrgID;references:ID" json:"organization,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (Term) TableName() string {
+	return "terms"
+}
+
+// CalendarDay 休日・特別時間割の日
+// 組織と日付の組み合わせごとに1件
+type CalendarDay struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;uniqueIndex:idx_calendar_days_org_date" json:"org_id"`
+	Date      time.Time `gorm:"type:date;column:date;not null;uniqueIndex:idx_calendar_days_org_date" json:"date"`
+	Kind      string    `gorm:"column:kind;type:varchar(20);not null" json:"kind"` // "holiday" or "special"
+	Name      string    `gorm:"column:name;type:varchar(100)" json:"name,omitempty"`
+	DayOfWeek *int      `gorm:"column:day_of_week" json:"day_of_week,omitempty"` // 特別時間割で使用する曜日（0=日, 1=月, ..., 6=土）
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Organization Organization `gorm:"foreignKey:OrgID;references:ID" json:"organization,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (CalendarDay) TableName() string {
+	return "calendar_days"
+}
+
+// SchoolDay 組織のある日付の授業実施状況
+type SchoolDay struct {
+	Date      time.Time `json:"date"`
+	Open      bool      `json:"open"`              // 授業を行う日か
+	DayOfWeek int       `json:"day_of_week"`       // 時間割に使用する曜日
+	Reason    string    `json:"reason,omitempty"`  // 授業を行わない理由・特別時間割の名前
+	Term      *Term     `json:"term,omitempty"`    // 日付を含む学期
+	Special   bool      `json:"special,omitempty"` // 特別時間割の日か
+}
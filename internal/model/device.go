@@ -11,6 +11,7 @@ type Device struct {
 	DeviceID          string    `gorm:"column:device_id;type:varchar(255);not null;uniqueIndex" json:"device_id"`
 	IsActive          bool      `gorm:"column:is_active;default:false" json:"is_active"`
 	LastAuthenticated time.Time `gorm:"column:last_authenticated" json:"last_authenticated"`
+	PublicKey         string    `gorm:"column:public_key;type:text" json:"-"` // アクティベーションの署名を検証する公開鍵（SPKI DERのbase64）
 	CreatedAt         time.Time `gorm:"column:created_at;not null" json:"created_at"`
 	UpdatedAt         time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
 
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+// DeviceChallenge デバイスアクティベーションのチャレンジ（1回だけ使用でき、有効期限は短い）
+// アプリはNonceをデバイスの秘密鍵で署名して送り、登録済みの公開鍵で検証する
+type DeviceChallenge struct {
+	ID        string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	DeviceID  string     `gorm:"type:uuid;column:device_id;not null;index" json:"device_id"` // devicesのID
+	Nonce     string     `gorm:"column:nonce;type:varchar(64);not null" json:"nonce"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+
+	// リレーション
+	Device Device `gorm:"foreignKey:DeviceID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (DeviceChallenge) TableName() string {
+	return "device_challenges"
+}
+
+// IsUsable 未使用で有効期限内か
+func (c *DeviceChallenge) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
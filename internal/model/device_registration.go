@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// DeviceRegistration 確認コードの入力待ちのデバイス登録
+// ユーザーのメールアドレスに送った確認コードを検証してからデバイスを作成する（コードはハッシュのみを保存）
+type DeviceRegistration struct {
+	ID         string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	UserID     string     `gorm:"type:uuid;column:user_id;not null;index" json:"user_id"`
+	DeviceID   string     `gorm:"column:device_id;type:varchar(255);not null" json:"device_id"`
+	PublicKey  string     `gorm:"column:public_key;type:text;not null" json:"-"`
+	CodeHash   string     `gorm:"column:code_hash;type:char(64);not null" json:"-"` // SHA-256（16進数）
+	Attempts   int        `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	ConsumedAt *time.Time `gorm:"column:consumed_at" json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+
+	// リレーション
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (DeviceRegistration) TableName() string {
+	return "device_registrations"
+}
+
+// IsPending 確認コードの入力待ちか（未使用で有効期限内）
+func (r *DeviceRegistration) IsPending(now time.Time) bool {
+	return r.ConsumedAt == nil && now.Before(r.ExpiresAt)
+}
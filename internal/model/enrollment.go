@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+)
+
+// Enrollment 履修登録（ユーザーが受講する教科）
+// 履修登録のある教科の授業は、履修しているユーザーのみの時間割・出席・授業監視の対象になる
+type Enrollment struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	UserID    string    `gorm:"type:uuid;column:user_id;not null;uniqueIndex:idx_enrollments_user_subject;index" json:"user_id"`
+	SubjectID string    `gorm:"type:uuid;column:subject_id;not null;uniqueIndex:idx_enrollments_user_subject;index" json:"subject_id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	User    User    `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Subject Subject `gorm:"foreignKey:SubjectID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (Enrollment) TableName() string {
+	return "enrollments"
+}
+
+// LessonRoster 授業の対象者
+// 履修登録のない教科・クラスを割り当てていない授業は組織の全ユーザーが対象（両方ある場合は両方を満たすユーザー）
+type LessonRoster struct {
+	Enrolled map[string]bool // 教科を履修しているユーザーID（nilの場合は制限なし）
+	Members  map[string]bool // クラスに所属するユーザーID（nilの場合は制限なし）
+}
+
+// Includes ユーザーが授業の対象者か
+func (r LessonRoster) Includes(userID string) bool {
+	return (r.Enrolled == nil || r.Enrolled[userID]) && (r.Members == nil || r.Members[userID])
+}
+
+// LessonAudience ユーザーが対象者になっている授業の判定
+type LessonAudience struct {
+	RestrictedSubjects map[string]bool // 組織内で履修登録のある教科ID
+	EnrolledSubjects   map[string]bool // ユーザーが履修している教科ID
+	Groups             map[string]bool // ユーザーが所属するクラスID
+}
+
+// Includes 教科・クラスの授業がユーザーの授業か
+func (a *LessonAudience) Includes(subjectID string, groupID *string) bool {
+	if a.RestrictedSubjects[subjectID] && !a.EnrolledSubjects[subjectID] {
+		return false
+	}
+	return groupID == nil || a.Groups[*groupID]
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// 時間割のiCalendar配信の対象
+const (
+	FeedKindUser = "user" // ユーザーの時間割
+	FeedKindRoom = "room" // 部屋の時間割
+)
+
+// FeedToken 時間割のiCalendar配信URLのトークン（トークンそのものは保存せずハッシュのみを保存）
+// カレンダーアプリはAuthorizationヘッダーを送れないため、URLに含めたトークンで配信対象を特定する
+// 対象ごとに有効なトークンは1つで、再発行すると以前のURLは使えなくなる
+type FeedToken struct {
+	ID        string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID     string     `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	Kind      string     `gorm:"column:kind;type:varchar(10);not null;index:idx_feed_tokens_target,priority:1" json:"kind"` // "user", "room"
+	TargetID  string     `gorm:"type:uuid;column:target_id;not null;index:idx_feed_tokens_target,priority:2" json:"target_id"`
+	TokenHash string     `gorm:"column:token_hash;type:char(64);not null;uniqueIndex" json:"-"` // SHA-256（16進数）
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+
+	// リレーション
+	Organization Organization `gorm:"foreignKey:OrgID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (FeedToken) TableName() string {
+	return "feed_tokens"
+}
+
+// IsActive 失効していないか
+func (t *FeedToken) IsActive() bool {
+	return t.RevokedAt == nil
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// Group クラス（ホームルーム）モデル（例: 2-A）
+// クラスに割り当てた授業は、所属するユーザーのみの時間割・出席・授業監視の対象になる
+type Group struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;uniqueIndex:idx_groups_org_name_year" json:"org_id"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null;uniqueIndex:idx_groups_org_name_year" json:"name"`
+	Year      int       `gorm:"column:year;not null;uniqueIndex:idx_groups_org_name_year" json:"year"` // 年度
+	Caption   string    `gorm:"column:caption;type:varchar(255)" json:"caption,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Organization Organization `gorm:"foreignKey:OrgID;references:ID" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (Group) TableName() string {
+	return "groups"
+}
+
+// GroupMember クラスの所属（1人のユーザーが複数のクラスに所属できる）
+type GroupMember struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	GroupID   string    `gorm:"type:uuid;column:group_id;not null;uniqueIndex:idx_group_members_group_user" json:"group_id"`
+	UserID    string    `gorm:"type:uuid;column:user_id;not null;uniqueIndex:idx_group_members_group_user;index" json:"user_id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Group Group `gorm:"foreignKey:GroupID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	User  User  `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (GroupMember) TableName() string {
+	return "group_members"
+}
@@ -9,19 +9,28 @@ type Lesson struct {
 	ID        string `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
 	SubjectID string `gorm:"type:uuid;column:subject_id;not null;index" json:"subject_id"`
 	RoomID    string `gorm:"type:uuid;column:room_id;not null;index" json:"room_id"`
-	OrgID     string `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	OrgID     string `gorm:"type:uuid;column:org_id;not null;index;uniqueIndex:idx_lessons_org_ical_uid,priority:1" json:"org_id"`
+
+	// 対象のクラス（オプション、指定した場合はクラスに所属するユーザーのみが対象）
+	GroupID *string `gorm:"type:uuid;column:group_id;index" json:"group_id,omitempty"`
 
 	// 時間情報
-	DayOfWeek int       `gorm:"column:day_of_week;not null;index" json:"day_of_week"` // 0=日, 1=月, ..., 6=土
-	StartTime time.Time `gorm:"column:start_time;not null;index" json:"start_time"`   // 開始時刻
-	EndTime   time.Time `gorm:"column:end_time;not null" json:"end_time"`             // 終了時刻
+	DayOfWeek int       `gorm:"column:day_of_week;not null;index;uniqueIndex:idx_lessons_org_ical_uid,priority:3" json:"day_of_week"` // 0=日, 1=月, ..., 6=土
+	StartTime time.Time `gorm:"column:start_time;not null;index" json:"start_time"`                                                   // 開始時刻
+	EndTime   time.Time `gorm:"column:end_time;not null" json:"end_time"`                                                             // 終了時刻
 
 	// 特定日付の授業の場合（オプション）
 	Date *time.Time `gorm:"column:date;index" json:"date,omitempty"`
 
+	// 毎週の授業の最終日（オプション、この日を含む）
+	Until *time.Time `gorm:"type:date;column:until" json:"until,omitempty"`
+
 	// 時限（オプション）
 	Period int `gorm:"column:period" json:"period,omitempty"` // 1限、2限など
 
+	// iCalendarから取り込んだ予定のUID（再取り込み時は組織・UID・曜日が同じ授業を更新する）
+	ICalUID *string `gorm:"column:ical_uid;type:varchar(255);uniqueIndex:idx_lessons_org_ical_uid,priority:2,where:ical_uid IS NOT NULL" json:"ical_uid,omitempty"`
+
 	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
 
@@ -35,3 +44,22 @@ type Lesson struct {
 func (Lesson) TableName() string {
 	return "lessons"
 }
+
+// TimesOn 指定日に実施する場合の開始・終了時刻
+// StartTime・EndTimeは作成日の日付を含むため、時刻部分（登録時のHH:MM）のみを使用する
+func (l *Lesson) TimesOn(date time.Time) (time.Time, time.Time) {
+	return onDate(date, l.StartTime), onDate(date, l.EndTime)
+}
+
+// LessonDateOf 時刻の暦日（授業実施日）
+func LessonDateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// onDate 指定日の日付に登録時刻（UTCで保存したHH:MM）を組み合わせる
+func onDate(date, clock time.Time) time.Time {
+	clock = clock.UTC()
+	y, m, d := date.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), 0, date.Location())
+}
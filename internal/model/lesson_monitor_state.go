@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// 監視フェーズ
+const (
+	MonitorPhaseMonitoring = "monitoring" // 監視中
+	MonitorPhaseFinished   = "finished"   // 授業終了処理済み
+)
+
+// MonitorUserState 監視中の授業における1ユーザーの在室状況
+type MonitorUserState struct {
+	StayID       int       `json:"stay_id"` // 自動記録した滞在ログID（手動入室の場合は0）
+	LastSeen     time.Time `json:"last_seen"`
+	Left         bool      `json:"left"`          // 不在が続いたため滞在ログを終了したか
+	PresentPolls int       `json:"present_polls"` // 在室を検知したスナップショット数
+}
+
+// LessonMonitorState 授業監視の状態（サーバー再起動時の復元用）
+// 授業と日付の組み合わせごとに1件
+type LessonMonitorState struct {
+	ID            string                      `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	LessonID      string                      `gorm:"type:uuid;column:lesson_id;not null;uniqueIndex:idx_lesson_monitor_states_lesson_date" json:"lesson_id"`
+	LessonDate    time.Time                   `gorm:"type:date;column:lesson_date;not null;uniqueIndex:idx_lesson_monitor_states_lesson_date" json:"lesson_date"`
+	Phase         string                      `gorm:"column:phase;type:varchar(20);not null;default:'monitoring'" json:"phase"`
+	ObservedPolls int                         `gorm:"column:observed_polls;not null;default:0" json:"observed_polls"`
+	Users         map[string]MonitorUserState `gorm:"column:users;type:jsonb;serializer:json" json:"users"` // ユーザーID → 在室状況
+	CreatedAt     time.Time                   `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt     time.Time                   `gorm:"column:updated_at;not null" json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (LessonMonitorState) TableName() string {
+	return "lesson_monitor_states"
+}
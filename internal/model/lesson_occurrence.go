@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+)
+
+// 実施回の状態
+const (
+	OccurrenceScheduled = "scheduled" // 実施予定
+	OccurrenceCancelled = "cancelled" // 休講（休日・学期外・時間割の変更など）
+)
+
+// 授業の監視期間（授業時間の前後に監視する時間）
+const (
+	MonitorLeadTime  = 5 * time.Minute  // 開始前
+	MonitorTrailTime = 10 * time.Minute // 終了後（終了処理はこの時間を過ぎてから行う）
+)
+
+// MonitoringRange 時刻に監視対象となる実施回の範囲
+// 開始時刻がto以前かつ終了時刻がfrom以降の実施回が監視対象（開始MonitorLeadTime前〜終了MonitorTrailTime後）
+func MonitoringRange(now time.Time) (from, to time.Time) {
+	return now.Add(-MonitorTrailTime), now.Add(MonitorLeadTime)
+}
+
+// LessonOccurrence 授業の実施回（時間割の1コマを日付ごとに展開したもの）
+// 授業と日付の組み合わせごとに1件
+type LessonOccurrence struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	LessonID  string    `gorm:"type:uuid;column:lesson_id;not null;uniqueIndex:idx_lesson_occurrences_lesson_date" json:"lesson_id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	SubjectID string    `gorm:"type:uuid;column:subject_id;not null;index" json:"subject_id"`
+	RoomID    string    `gorm:"type:uuid;column:room_id;not null;index" json:"room_id"`
+	GroupID   *string   `gorm:"type:uuid;column:group_id;index" json:"group_id,omitempty"` // 対象のクラス
+	Date      time.Time `gorm:"type:date;column:date;not null;uniqueIndex:idx_lesson_occurrences_lesson_date;index" json:"date"`
+	StartAt   time.Time `gorm:"column:start_at;not null;index" json:"start_at"` // この日の開始時刻
+	EndAt     time.Time `gorm:"column:end_at;not null;index" json:"end_at"`     // この日の終了時刻
+	Period    int       `gorm:"column:period" json:"period,omitempty"`
+	Status    string    `gorm:"column:status;type:varchar(20);not null;default:'scheduled';index" json:"status"`
+	Reason    string    `gorm:"column:reason;type:varchar(100)" json:"reason,omitempty"` // 休講の理由
+	// 授業の変更（休講・教室変更・時間変更）を反映した実施回か
+	Overridden bool `gorm:"column:overridden;not null;default:false" json:"overridden"`
+
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Lesson  Lesson  `gorm:"foreignKey:LessonID;references:ID;constraint:OnDelete:CASCADE" json:"lesson,omitempty"`
+	Subject Subject `gorm:"foreignKey:SubjectID;references:ID" json:"subject,omitempty"`
+	Room    Room    `gorm:"foreignKey:RoomID;references:ID" json:"room,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (LessonOccurrence) TableName() string {
+	return "lesson_occurrences"
+}
+
+// IsCancelled 休講かどうか
+func (o *LessonOccurrence) IsCancelled() bool {
+	return o.Status == OccurrenceCancelled
+}
+
+// LessonOnDate 授業の開始・終了時刻をこの日の時刻に置き換えた授業
+// 出席判定など授業の時刻を参照する処理に渡す
+func (o *LessonOccurrence) LessonOnDate() Lesson {
+	lesson := o.Lesson
+	lesson.StartTime = o.StartAt
+	lesson.EndTime = o.EndAt
+	lesson.RoomID = o.RoomID
+	lesson.SubjectID = o.SubjectID
+	lesson.GroupID = o.GroupID
+	if o.Room.ID != "" {
+		lesson.Room = o.Room
+	}
+	if o.Subject.ID != "" {
+		lesson.Subject = o.Subject
+	}
+	return lesson
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+)
+
+// LessonOverride 授業の日付ごとの変更（休講・教室変更・時間変更）
+// 授業と日付の組み合わせごとに1件。指定された項目のみ時間割の内容を置き換える
+type LessonOverride struct {
+	ID        string     `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	LessonID  string     `gorm:"type:uuid;column:lesson_id;not null;uniqueIndex:idx_lesson_overrides_lesson_date" json:"lesson_id"`
+	OrgID     string     `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	Date      time.Time  `gorm:"type:date;column:date;not null;uniqueIndex:idx_lesson_overrides_lesson_date;index" json:"date"`
+	Cancelled bool       `gorm:"column:cancelled;not null;default:false" json:"cancelled"`
+	RoomID    *string    `gorm:"type:uuid;column:room_id" json:"room_id,omitempty"`       // 変更後の部屋
+	StartTime *time.Time `gorm:"column:start_time" json:"start_time,omitempty"`           // 変更後の開始時刻（HH:MMのみ使用）
+	EndTime   *time.Time `gorm:"column:end_time" json:"end_time,omitempty"`               // 変更後の終了時刻（HH:MMのみ使用）
+	Reason    string     `gorm:"column:reason;type:varchar(100)" json:"reason,omitempty"` // 休講・変更の理由
+	CreatedAt time.Time  `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	Lesson Lesson `gorm:"foreignKey:LessonID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (LessonOverride) TableName() string {
+	return "lesson_overrides"
+}
+
+// Apply 実施回に変更内容を反映
+func (o *LessonOverride) Apply(occurrence *LessonOccurrence) {
+	occurrence.Overridden = true
+	if o.RoomID != nil {
+		occurrence.RoomID = *o.RoomID
+	}
+	if o.StartTime != nil {
+		occurrence.StartAt = onDate(occurrence.Date, *o.StartTime)
+	}
+	if o.EndTime != nil {
+		occurrence.EndAt = onDate(occurrence.Date, *o.EndTime)
+	}
+	if o.Cancelled {
+		occurrence.Status = OccurrenceCancelled
+		occurrence.Reason = o.Reason
+		if occurrence.Reason == "" {
+			occurrence.Reason = "休講"
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"time"
 )
 
-// Map マップモデル
+// Map マップモデル（Mistのマップ定義を同期したもの）
 type Map struct {
 	ID           string    `gorm:"primaryKey;type:uuid;column:id" json:"id"`
 	MistMapID    string    `gorm:"column:mist_map_id;type:varchar(255);uniqueIndex" json:"mist_map_id"`
@@ -6,20 +6,27 @@ import (
 
 // Stay 滞在モデル
 type Stay struct {
-	ID          int        `gorm:"primaryKey;autoIncrement;column:id;not null" json:"id"`
-	UserID      string     `gorm:"type:uuid;column:user_id;not null;index" json:"user_id"`
-	IsActive    bool       `gorm:"column:is_active;not null;index" json:"is_active"`
-	RoomID      string     `gorm:"type:uuid;column:room_id;not null;index" json:"room_id"`
-	SubjectID   string     `gorm:"type:uuid;column:subject_id;index" json:"subject_id,omitempty"`
-	LessonID    *string    `gorm:"type:uuid;column:lesson_id;index" json:"lesson_id,omitempty"`
-	Description string     `gorm:"column:description;type:text" json:"description,omitempty"`
-	Source      string     `gorm:"column:source;type:varchar(20);default:'auto'" json:"source"` // "auto" or "manual"
-	CreatedAt   time.Time  `gorm:"column:created_at;not null;index" json:"created_at"`
-	LeavedAt    *time.Time `gorm:"column:leaved_at" json:"leaved_at,omitempty"`
+	ID           int        `gorm:"primaryKey;autoIncrement;column:id;not null" json:"id"`
+	UserID       string     `gorm:"type:uuid;column:user_id;not null;index;uniqueIndex:idx_stays_user_lesson_date,priority:1" json:"user_id"`
+	IsActive     bool       `gorm:"column:is_active;not null;index" json:"is_active"`
+	RoomID       string     `gorm:"type:uuid;column:room_id;not null;index" json:"room_id"`
+	SubjectID    string     `gorm:"type:uuid;column:subject_id;index" json:"subject_id,omitempty"`
+	LessonID     *string    `gorm:"type:uuid;column:lesson_id;index;uniqueIndex:idx_stays_user_lesson_date,priority:2,where:lesson_id IS NOT NULL AND lesson_date IS NOT NULL" json:"lesson_id,omitempty"`
+	LessonDate   *time.Time `gorm:"type:date;column:lesson_date;uniqueIndex:idx_stays_user_lesson_date,priority:3" json:"lesson_date,omitempty"` // 授業の実施日（同じ授業・同じ日の滞在はユーザーごとに1件）
+	OccurrenceID *string    `gorm:"type:uuid;column:occurrence_id;index" json:"occurrence_id,omitempty"`                                         // 授業の実施回
+	Description  string     `gorm:"column:description;type:text" json:"description,omitempty"`
+	Source       string     `gorm:"column:source;type:varchar(20);default:'auto'" json:"source"` // "auto" or "manual"
+	CreatedAt    time.Time  `gorm:"column:created_at;not null;index" json:"created_at"`
+	LeavedAt     *time.Time `gorm:"column:leaved_at" json:"leaved_at,omitempty"`
+
+	// 滞在時間による出席判定（授業終了時に確定。未確定の場合はnil）
+	DwellMinutes int   `gorm:"column:dwell_minutes;not null;default:0" json:"dwell_minutes"`
+	Attended     *bool `gorm:"column:attended" json:"attended,omitempty"`
 
 	// リレーション
-	User    User    `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
-	Room    Room    `gorm:"foreignKey:RoomID;references:ID" json:"room,omitempty"`
-	Subject Subject `gorm:"foreignKey:SubjectID;references:ID" json:"subject,omitempty"`
-	Lesson  *Lesson `gorm:"foreignKey:LessonID;references:ID" json:"lesson,omitempty"`
+	User       User              `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	Room       Room              `gorm:"foreignKey:RoomID;references:ID" json:"room,omitempty"`
+	Subject    Subject           `gorm:"foreignKey:SubjectID;references:ID" json:"subject,omitempty"`
+	Lesson     *Lesson           `gorm:"foreignKey:LessonID;references:ID" json:"lesson,omitempty"`
+	Occurrence *LessonOccurrence `gorm:"foreignKey:OccurrenceID;references:ID;constraint:OnDelete:SET NULL" json:"occurrence,omitempty"`
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+// TeachingAssignment 教員の担当（教科または授業）
+// LessonIDがnilの場合は教科のすべての授業を担当する
+type TeachingAssignment struct {
+	ID        string    `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
+	OrgID     string    `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
+	UserID    string    `gorm:"type:uuid;column:user_id;not null;index" json:"user_id"`
+	SubjectID string    `gorm:"type:uuid;column:subject_id;not null;index" json:"subject_id"`
+	LessonID  *string   `gorm:"type:uuid;column:lesson_id;index" json:"lesson_id,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+
+	// リレーション
+	User    User    `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Subject Subject `gorm:"foreignKey:SubjectID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Lesson  *Lesson `gorm:"foreignKey:LessonID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (TeachingAssignment) TableName() string {
+	return "teaching_assignments"
+}
+
+// Covers 担当に授業が含まれるか
+func (a TeachingAssignment) Covers(lessonID, subjectID string) bool {
+	if a.LessonID != nil {
+		return *a.LessonID == lessonID
+	}
+	return a.SubjectID == subjectID
+}
@@ -11,6 +11,7 @@ type User struct {
 	ID        string         `gorm:"primaryKey;type:uuid;column:id;not null" json:"id"`
 	OrgID     string         `gorm:"type:uuid;column:org_id;not null;index" json:"org_id"`
 	Mail      string         `gorm:"column:mail;type:varchar(255);not null;uniqueIndex" json:"mail"`
+	Role      string         `gorm:"column:role;type:varchar(20);not null;default:'student'" json:"role"` // "student" or "teacher"
 	CreatedAt time.Time      `gorm:"column:created_at;not null" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"column:updated_at;not null" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"deleted_at,omitempty"`
@@ -19,3 +20,19 @@ type User struct {
 	Organization Organization `gorm:"foreignKey:OrgID;references:ID" json:"organization,omitempty"`
 	Devices      []Device     `gorm:"foreignKey:UserID" json:"devices,omitempty"`
 }
+
+// ユーザーの役割
+const (
+	UserRoleStudent = "student" // 生徒（出席の記録対象）
+	UserRoleTeacher = "teacher" // 教員（担当する授業の出席を確認・修正できる）
+)
+
+// IsValidUserRole 有効なユーザーの役割か
+func IsValidUserRole(role string) bool {
+	return role == UserRoleStudent || role == UserRoleTeacher
+}
+
+// IsTeacher 教員か
+func (u *User) IsTeacher() bool {
+	return u.Role == UserRoleTeacher
+}
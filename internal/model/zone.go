@@ -4,15 +4,23 @@ import (
 	"time"
 )
 
-// Zone ゾーンモデル
+// ZoneVertex ゾーンの頂点座標（マップ画像上のピクセル）
+type ZoneVertex struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Zone ゾーンモデル（Mistのゾーン定義を同期したもの）
 type Zone struct {
-	ID         string    `gorm:"primaryKey;type:uuid;column:id" json:"id"`
-	MistZoneID string    `gorm:"column:mist_zone_id;type:varchar(255);uniqueIndex" json:"mist_zone_id"`
-	Name       string    `gorm:"column:name;type:varchar(255)" json:"name"`
-	MapID      string    `gorm:"column:map_id;type:varchar(255)" json:"map_id"`
-	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
-	IsActive   bool      `gorm:"column:is_active" json:"is_active"`
+	ID         string       `gorm:"primaryKey;type:uuid;column:id" json:"id"`
+	MistZoneID string       `gorm:"column:mist_zone_id;type:varchar(255);uniqueIndex" json:"mist_zone_id"`
+	Name       string       `gorm:"column:name;type:varchar(255)" json:"name"`
+	MapID      string       `gorm:"column:map_id;type:uuid;index" json:"map_id"`
+	MistMapID  string       `gorm:"column:mist_map_id;type:varchar(255);index" json:"mist_map_id"`
+	Vertices   []ZoneVertex `gorm:"column:vertices;type:jsonb;serializer:json" json:"vertices"`
+	CreatedAt  time.Time    `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time    `gorm:"column:updated_at" json:"updated_at"`
+	IsActive   bool         `gorm:"column:is_active" json:"is_active"`
 
 	// リレーション
 	Map Map `gorm:"foreignKey:MapID" json:"map,omitempty"`
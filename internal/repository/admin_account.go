@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// AdminAccountRepository 管理APIのアカウントリポジトリ
+type AdminAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminAccountRepository 管理APIのアカウントリポジトリを作成
+func NewAdminAccountRepository(db *gorm.DB) *AdminAccountRepository {
+	return &AdminAccountRepository{db: db}
+}
+
+// Create アカウントを作成
+func (r *AdminAccountRepository) Create(ctx context.Context, account *model.AdminAccount) error {
+	return dbFrom(ctx, r.db).Create(account).Error
+}
+
+// FindByID IDでアカウントを取得
+func (r *AdminAccountRepository) FindByID(ctx context.Context, id string) (*model.AdminAccount, error) {
+	var account model.AdminAccount
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByMail メールアドレスでアカウントを取得
+func (r *AdminAccountRepository) FindByMail(ctx context.Context, mail string) (*model.AdminAccount, error) {
+	var account model.AdminAccount
+	err := dbFrom(ctx, r.db).Where("mail = ?", mail).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindAll アカウント一覧を取得（orgIDが空でない場合はその組織で絞り込む）
+func (r *AdminAccountRepository) FindAll(ctx context.Context, orgID string) ([]model.AdminAccount, error) {
+	var accounts []model.AdminAccount
+	query := dbFrom(ctx, r.db)
+	if orgID != "" {
+		query = query.Where("org_id = ?", orgID)
+	}
+	err := query.Order("created_at ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// ExistsByRole 指定した役割のアカウントがあるか
+func (r *AdminAccountRepository) ExistsByRole(ctx context.Context, role string) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&model.AdminAccount{}).Where("role = ?", role).Count(&count).Error
+	return count > 0, err
+}
+
+// UpdatePasswordHash パスワードのハッシュを更新
+func (r *AdminAccountRepository) UpdatePasswordHash(ctx context.Context, id, passwordHash string) error {
+	return dbFrom(ctx, r.db).Model(&model.AdminAccount{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password_hash": passwordHash,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// UpdateLastLoginAt 最終ログイン日時を更新
+func (r *AdminAccountRepository) UpdateLastLoginAt(ctx context.Context, id string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&model.AdminAccount{}).
+		Where("id = ?", id).
+		Update("last_login_at", at).Error
+}
+
+// Delete アカウントを削除
+func (r *AdminAccountRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.AdminAccount{}).Error
+}
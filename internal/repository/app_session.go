@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// AppSessionRepository アプリのセッションリポジトリ
+type AppSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewAppSessionRepository アプリのセッションリポジトリを作成
+func NewAppSessionRepository(db *gorm.DB) *AppSessionRepository {
+	return &AppSessionRepository{db: db}
+}
+
+// Create セッションを作成
+func (r *AppSessionRepository) Create(ctx context.Context, session *model.AppSession) error {
+	return dbFrom(ctx, r.db).Create(session).Error
+}
+
+// FindByID IDでセッションを取得
+func (r *AppSessionRepository) FindByID(ctx context.Context, id string) (*model.AppSession, error) {
+	var session model.AppSession
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Revoke 未失効のセッションを失効させる（失効させた場合はtrue）
+// 同じトークンで同時に更新した場合に1つだけが成功するよう、未失効の条件付きで更新する
+func (r *AppSessionRepository) Revoke(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := dbFrom(ctx, r.db).Model(&model.AppSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeByDeviceID デバイスの未失効のセッションをすべて失効させる
+func (r *AppSessionRepository) RevokeByDeviceID(ctx context.Context, deviceID string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&model.AppSession{}).
+		Where("device_id = ? AND revoked_at IS NULL", deviceID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeByUserID ユーザーの未失効のセッションをすべて失効させる（失効させた件数を返す）
+func (r *AppSessionRepository) RevokeByUserID(ctx context.Context, userID string, at time.Time) (int64, error) {
+	result := dbFrom(ctx, r.db).Model(&model.AppSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired 有効期限切れのセッションを削除
+func (r *AppSessionRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	return dbFrom(ctx, r.db).Where("expires_at < ?", before).Delete(&model.AppSession{}).Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// AttendanceMarkRepository 出席の手動記録リポジトリ
+type AttendanceMarkRepository struct {
+	db *gorm.DB
+}
+
+// NewAttendanceMarkRepository 出席の手動記録リポジトリを作成
+func NewAttendanceMarkRepository(db *gorm.DB) *AttendanceMarkRepository {
+	return &AttendanceMarkRepository{db: db}
+}
+
+// Upsert ユーザー・授業・実施日をキーに手動記録を作成または更新
+func (r *AttendanceMarkRepository) Upsert(ctx context.Context, mark *model.AttendanceMark) error {
+	return dbFrom(ctx, r.db).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "lesson_id"}, {Name: "lesson_date"}},
+				DoUpdates: clause.AssignmentColumns([]string{"occurrence_id", "status", "reason", "marked_by", "updated_at"}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+		).
+		Create(mark).Error
+}
+
+// FindByUserLessonDate ユーザーID・授業ID・実施日で手動記録を取得
+func (r *AttendanceMarkRepository) FindByUserLessonDate(ctx context.Context, userID, lessonID string, lessonDate time.Time) (*model.AttendanceMark, error) {
+	var mark model.AttendanceMark
+	err := dbFrom(ctx, r.db).
+		Where("user_id = ? AND lesson_id = ? AND lesson_date = ?", userID, lessonID, lessonDate.Format("2006-01-02")).
+		First(&mark).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &mark, nil
+}
+
+// FindByLessonAndDate 授業IDと実施日で手動記録一覧を取得
+func (r *AttendanceMarkRepository) FindByLessonAndDate(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.AttendanceMark, error) {
+	var marks []model.AttendanceMark
+	err := dbFrom(ctx, r.db).
+		Where("lesson_id = ? AND lesson_date = ?", lessonID, lessonDate.Format("2006-01-02")).
+		Find(&marks).Error
+	return marks, err
+}
+
+// Delete 手動記録を削除
+func (r *AttendanceMarkRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.AttendanceMark{}).Error
+}
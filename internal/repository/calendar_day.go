@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// CalendarDayRepository 休日・特別時間割リポジトリ
+type CalendarDayRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarDayRepository 休日・特別時間割リポジトリを作成
+func NewCalendarDayRepository(db *gorm.DB) *CalendarDayRepository {
+	return &CalendarDayRepository{db: db}
+}
+
+// Create 休日・特別時間割を作成
+func (r *CalendarDayRepository) Create(ctx context.Context, day *model.CalendarDay) error {
+	return dbFrom(ctx, r.db).Create(day).Error
+}
+
+// FindByID IDで休日・特別時間割を取得
+func (r *CalendarDayRepository) FindByID(ctx context.Context, id string) (*model.CalendarDay, error) {
+	var day model.CalendarDay
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&day).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &day, nil
+}
+
+// FindByOrgAndDate 組織IDと日付で休日・特別時間割を取得
+func (r *CalendarDayRepository) FindByOrgAndDate(ctx context.Context, orgID string, date time.Time) (*model.CalendarDay, error) {
+	var day model.CalendarDay
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND date = ?", orgID, date.Format("2006-01-02")).
+		First(&day).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &day, nil
+}
+
+// FindByOrgID 組織IDで休日・特別時間割一覧を取得（from・toがゼロ値の場合は期間を限定しない）
+func (r *CalendarDayRepository) FindByOrgID(ctx context.Context, orgID string, from, to time.Time) ([]model.CalendarDay, error) {
+	var days []model.CalendarDay
+	query := dbFrom(ctx, r.db).Where("org_id = ?", orgID)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to.Format("2006-01-02"))
+	}
+	err := query.Order("date ASC").Find(&days).Error
+	return days, err
+}
+
+// Update 休日・特別時間割を更新
+func (r *CalendarDayRepository) Update(ctx context.Context, day *model.CalendarDay) error {
+	return dbFrom(ctx, r.db).Save(day).Error
+}
+
+// Delete 休日・特別時間割を削除
+func (r *CalendarDayRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.CalendarDay{}).Error
+}
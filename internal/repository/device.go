@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -21,13 +22,13 @@ func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
 
 // Create デバイスを作成
 func (r *DeviceRepository) Create(ctx context.Context, device *model.Device) error {
-	return r.db.WithContext(ctx).Create(device).Error
+	return dbFrom(ctx, r.db).Create(device).Error
 }
 
 // FindByID IDでデバイスを取得
 func (r *DeviceRepository) FindByID(ctx context.Context, id string) (*model.Device, error) {
 	var device model.Device
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -44,7 +45,7 @@ func (r *DeviceRepository) FindByID(ctx context.Context, id string) (*model.Devi
 // FindByDeviceID デバイスIDでデバイスを取得
 func (r *DeviceRepository) FindByDeviceID(ctx context.Context, deviceID string) (*model.Device, error) {
 	var device model.Device
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -61,7 +62,7 @@ func (r *DeviceRepository) FindByDeviceID(ctx context.Context, deviceID string)
 // FindByUserID ユーザーIDでデバイス一覧を取得
 func (r *DeviceRepository) FindByUserID(ctx context.Context, userID string) ([]model.Device, error) {
 	var devices []model.Device
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail")
 		}).
@@ -72,7 +73,7 @@ func (r *DeviceRepository) FindByUserID(ctx context.Context, userID string) ([]m
 // GetActiveByUserID ユーザーIDでアクティブなデバイスを取得
 func (r *DeviceRepository) GetActiveByUserID(ctx context.Context, userID string) (*model.Device, error) {
 	var device model.Device
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail")
 		}).
@@ -89,23 +90,23 @@ func (r *DeviceRepository) GetActiveByUserID(ctx context.Context, userID string)
 // FindAll 全デバイスを取得
 func (r *DeviceRepository) FindAll(ctx context.Context) ([]model.Device, error) {
 	var devices []model.Device
-	err := r.db.WithContext(ctx).Find(&devices).Error
+	err := dbFrom(ctx, r.db).Find(&devices).Error
 	return devices, err
 }
 
 // Update デバイスを更新
 func (r *DeviceRepository) Update(ctx context.Context, device *model.Device) error {
-	return r.db.WithContext(ctx).Save(device).Error
+	return dbFrom(ctx, r.db).Save(device).Error
 }
 
 // Delete デバイスを削除
 func (r *DeviceRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&model.Device{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&model.Device{}, "id = ?", id).Error
 }
 
 // Activate デバイスをアクティブにする
 func (r *DeviceRepository) Activate(ctx context.Context, id string) error {
-	err := r.db.WithContext(ctx).Model(&model.Device{}).Where("id = ?", id).Update("is_active", true).Error
+	err := dbFrom(ctx, r.db).Model(&model.Device{}).Where("id = ?", id).Update("is_active", true).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrorRecordNotFound
@@ -115,9 +116,18 @@ func (r *DeviceRepository) Activate(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdatePublicKey デバイスの公開鍵を更新（鍵を登録し直したデバイスは再度アクティベーションが必要）
+func (r *DeviceRepository) UpdatePublicKey(ctx context.Context, id, publicKey string) error {
+	return dbFrom(ctx, r.db).Model(&model.Device{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"public_key": publicKey,
+		"is_active":  false,
+		"updated_at": time.Now(),
+	}).Error
+}
+
 // Deactivate デバイスを非アクティブにする
 func (r *DeviceRepository) Deactivate(ctx context.Context, id string) error {
-	err := r.db.WithContext(ctx).Model(&model.Device{}).Where("id = ?", id).Update("is_active", false).Error
+	err := dbFrom(ctx, r.db).Model(&model.Device{}).Where("id = ?", id).Update("is_active", false).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrorRecordNotFound
@@ -129,7 +139,7 @@ func (r *DeviceRepository) Deactivate(ctx context.Context, id string) error {
 
 // DeactivateAllForOrg 組織の全デバイスを非アクティブにする
 func (r *DeviceRepository) DeactivateAllForOrg(ctx context.Context, orgID string) error {
-	err := r.db.WithContext(ctx).Model(&model.Device{}).
+	err := dbFrom(ctx, r.db).Model(&model.Device{}).
 		Joins("JOIN users ON devices.user_id = users.id").
 		Where("users.org_id = ? AND devices.is_active = ?", orgID, true).
 		Update("is_active", false).Error
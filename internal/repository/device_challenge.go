@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// DeviceChallengeRepository デバイスアクティベーションのチャレンジリポジトリ
+type DeviceChallengeRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceChallengeRepository デバイスアクティベーションのチャレンジリポジトリを作成
+func NewDeviceChallengeRepository(db *gorm.DB) *DeviceChallengeRepository {
+	return &DeviceChallengeRepository{db: db}
+}
+
+// Create チャレンジを作成
+func (r *DeviceChallengeRepository) Create(ctx context.Context, challenge *model.DeviceChallenge) error {
+	return dbFrom(ctx, r.db).Create(challenge).Error
+}
+
+// FindByID IDでチャレンジを取得
+func (r *DeviceChallengeRepository) FindByID(ctx context.Context, id string) (*model.DeviceChallenge, error) {
+	var challenge model.DeviceChallenge
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&challenge).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// MarkUsed 未使用のチャレンジを使用済みにする（使用済みにした場合はtrue）
+// 同じチャレンジを同時に使用した場合に1つだけが成功するよう、未使用の条件付きで更新する
+func (r *DeviceChallengeRepository) MarkUsed(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := dbFrom(ctx, r.db).Model(&model.DeviceChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired 有効期限切れのチャレンジを削除
+func (r *DeviceChallengeRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	return dbFrom(ctx, r.db).Where("expires_at < ?", before).Delete(&model.DeviceChallenge{}).Error
+}
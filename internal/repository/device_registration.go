@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// DeviceRegistrationRepository 確認コードの入力待ちのデバイス登録リポジトリ
+type DeviceRegistrationRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceRegistrationRepository 確認コードの入力待ちのデバイス登録リポジトリを作成
+func NewDeviceRegistrationRepository(db *gorm.DB) *DeviceRegistrationRepository {
+	return &DeviceRegistrationRepository{db: db}
+}
+
+// Create デバイス登録を作成
+func (r *DeviceRegistrationRepository) Create(ctx context.Context, registration *model.DeviceRegistration) error {
+	return dbFrom(ctx, r.db).Create(registration).Error
+}
+
+// FindByID IDでデバイス登録を取得
+func (r *DeviceRegistrationRepository) FindByID(ctx context.Context, id string) (*model.DeviceRegistration, error) {
+	var registration model.DeviceRegistration
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&registration).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// FindLatestByUserID ユーザーの最新のデバイス登録を取得
+func (r *DeviceRegistrationRepository) FindLatestByUserID(ctx context.Context, userID string) (*model.DeviceRegistration, error) {
+	var registration model.DeviceRegistration
+	err := dbFrom(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").First(&registration).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// IncrementAttempts 入力回数が上限未満の未使用のデバイス登録の入力回数を1増やす（増やした場合はtrue）
+// 同時に入力した場合も上限を超えないよう、条件付きで更新する
+func (r *DeviceRegistrationRepository) IncrementAttempts(ctx context.Context, id string, maxAttempts int) (bool, error) {
+	result := dbFrom(ctx, r.db).Model(&model.DeviceRegistration{}).
+		Where("id = ? AND consumed_at IS NULL AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// Consume 未使用のデバイス登録を使用済みにする（使用済みにした場合はtrue）
+func (r *DeviceRegistrationRepository) Consume(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := dbFrom(ctx, r.db).Model(&model.DeviceRegistration{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeletePendingByUserID ユーザーの未使用のデバイス登録を削除
+func (r *DeviceRegistrationRepository) DeletePendingByUserID(ctx context.Context, userID string) error {
+	return dbFrom(ctx, r.db).Where("user_id = ? AND consumed_at IS NULL", userID).Delete(&model.DeviceRegistration{}).Error
+}
+
+// DeleteExpired 有効期限切れのデバイス登録を削除
+func (r *DeviceRegistrationRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	return dbFrom(ctx, r.db).Where("expires_at < ?", before).Delete(&model.DeviceRegistration{}).Error
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// EnrollmentRepository 履修登録リポジトリ
+type EnrollmentRepository struct {
+	db *gorm.DB
+}
+
+// NewEnrollmentRepository 履修登録リポジトリを作成
+func NewEnrollmentRepository(db *gorm.DB) *EnrollmentRepository {
+	return &EnrollmentRepository{db: db}
+}
+
+// Create 履修登録を作成
+func (r *EnrollmentRepository) Create(ctx context.Context, enrollment *model.Enrollment) error {
+	return dbFrom(ctx, r.db).Create(enrollment).Error
+}
+
+// FindByID IDで履修登録を取得
+func (r *EnrollmentRepository) FindByID(ctx context.Context, id string) (*model.Enrollment, error) {
+	var enrollment model.Enrollment
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&enrollment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// FindByUserAndSubject ユーザーIDと教科IDで履修登録を取得
+func (r *EnrollmentRepository) FindByUserAndSubject(ctx context.Context, userID, subjectID string) (*model.Enrollment, error) {
+	var enrollment model.Enrollment
+	err := dbFrom(ctx, r.db).
+		Where("user_id = ? AND subject_id = ?", userID, subjectID).
+		First(&enrollment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// FindByOrgID 組織IDで履修登録一覧を取得（userID・subjectIDが空でない場合はその条件で絞り込む）
+func (r *EnrollmentRepository) FindByOrgID(ctx context.Context, orgID, userID, subjectID string) ([]model.Enrollment, error) {
+	var enrollments []model.Enrollment
+	query := dbFrom(ctx, r.db).Where("org_id = ?", orgID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if subjectID != "" {
+		query = query.Where("subject_id = ?", subjectID)
+	}
+	err := query.Order("created_at ASC").Find(&enrollments).Error
+	return enrollments, err
+}
+
+// FindSubjectIDsByUserID ユーザーが履修している教科IDの一覧を取得
+func (r *EnrollmentRepository) FindSubjectIDsByUserID(ctx context.Context, userID string) ([]string, error) {
+	var subjectIDs []string
+	err := dbFrom(ctx, r.db).Model(&model.Enrollment{}).
+		Where("user_id = ?", userID).
+		Pluck("subject_id", &subjectIDs).Error
+	return subjectIDs, err
+}
+
+// FindUserIDsBySubjectID 教科を履修しているユーザーIDの一覧を取得
+func (r *EnrollmentRepository) FindUserIDsBySubjectID(ctx context.Context, subjectID string) ([]string, error) {
+	var userIDs []string
+	err := dbFrom(ctx, r.db).Model(&model.Enrollment{}).
+		Where("subject_id = ?", subjectID).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// FindEnrolledSubjectIDs 組織内で履修登録のある教科IDの一覧を取得
+func (r *EnrollmentRepository) FindEnrolledSubjectIDs(ctx context.Context, orgID string) ([]string, error) {
+	var subjectIDs []string
+	err := dbFrom(ctx, r.db).Model(&model.Enrollment{}).
+		Where("org_id = ?", orgID).
+		Distinct("subject_id").
+		Pluck("subject_id", &subjectIDs).Error
+	return subjectIDs, err
+}
+
+// Delete 履修登録を削除
+func (r *EnrollmentRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.Enrollment{}).Error
+}
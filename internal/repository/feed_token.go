@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// FeedTokenRepository 時間割配信トークンリポジトリ
+type FeedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedTokenRepository 時間割配信トークンリポジトリを作成
+func NewFeedTokenRepository(db *gorm.DB) *FeedTokenRepository {
+	return &FeedTokenRepository{db: db}
+}
+
+// Create 配信トークンを作成
+func (r *FeedTokenRepository) Create(ctx context.Context, token *model.FeedToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+// FindByHash トークンのハッシュで配信トークンを取得
+func (r *FeedTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.FeedToken, error) {
+	var token model.FeedToken
+	err := dbFrom(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeByTarget 配信対象の未失効のトークンをすべて失効させる（失効させた件数を返す）
+func (r *FeedTokenRepository) RevokeByTarget(ctx context.Context, kind, targetID string, at time.Time) (int64, error) {
+	result := dbFrom(ctx, r.db).Model(&model.FeedToken{}).
+		Where("kind = ? AND target_id = ? AND revoked_at IS NULL", kind, targetID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
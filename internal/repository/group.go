@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// GroupRepository クラスリポジトリ
+type GroupRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository クラスリポジトリを作成
+func NewGroupRepository(db *gorm.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create クラスを作成
+func (r *GroupRepository) Create(ctx context.Context, group *model.Group) error {
+	return dbFrom(ctx, r.db).Create(group).Error
+}
+
+// FindByID IDでクラスを取得
+func (r *GroupRepository) FindByID(ctx context.Context, id string) (*model.Group, error) {
+	var group model.Group
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByOrgNameYear 組織ID・クラス名・年度でクラスを取得
+func (r *GroupRepository) FindByOrgNameYear(ctx context.Context, orgID, name string, year int) (*model.Group, error) {
+	var group model.Group
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND name = ? AND year = ?", orgID, name, year).
+		First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByOrgID 組織IDでクラス一覧を取得（yearが0の場合は全年度）
+func (r *GroupRepository) FindByOrgID(ctx context.Context, orgID string, year int) ([]model.Group, error) {
+	var groups []model.Group
+	query := dbFrom(ctx, r.db).Where("org_id = ?", orgID)
+	if year != 0 {
+		query = query.Where("year = ?", year)
+	}
+	err := query.Order("year DESC, name ASC").Find(&groups).Error
+	return groups, err
+}
+
+// Update クラスを更新
+func (r *GroupRepository) Update(ctx context.Context, group *model.Group) error {
+	return dbFrom(ctx, r.db).Save(group).Error
+}
+
+// Delete クラスを削除（授業の割り当ては解除する）
+func (r *GroupRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Lesson{}).Where("group_id = ?", id).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Group{}).Error
+	})
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// GroupMemberRepository クラス所属リポジトリ
+type GroupMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupMemberRepository クラス所属リポジトリを作成
+func NewGroupMemberRepository(db *gorm.DB) *GroupMemberRepository {
+	return &GroupMemberRepository{db: db}
+}
+
+// CreateIfNotExists クラス所属を作成（すでに所属している場合は何もしない）
+func (r *GroupMemberRepository) CreateIfNotExists(ctx context.Context, member *model.GroupMember) error {
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "group_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).
+		Create(member).Error
+}
+
+// FindByGroupAndUser クラスIDとユーザーIDでクラス所属を取得
+func (r *GroupMemberRepository) FindByGroupAndUser(ctx context.Context, groupID, userID string) (*model.GroupMember, error) {
+	var member model.GroupMember
+	err := dbFrom(ctx, r.db).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByGroupID クラスIDで所属一覧を取得（ユーザー情報を含む）
+func (r *GroupMemberRepository) FindByGroupID(ctx context.Context, groupID string) ([]model.GroupMember, error) {
+	var members []model.GroupMember
+	err := dbFrom(ctx, r.db).
+		Preload("User").
+		Joins("JOIN users ON users.id = group_members.user_id").
+		Where("group_members.group_id = ?", groupID).
+		Order("users.mail ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// FindUserIDsByGroupID クラスに所属するユーザーIDの一覧を取得
+func (r *GroupMemberRepository) FindUserIDsByGroupID(ctx context.Context, groupID string) ([]string, error) {
+	var userIDs []string
+	err := dbFrom(ctx, r.db).Model(&model.GroupMember{}).
+		Where("group_id = ?", groupID).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// FindGroupIDsByUserID ユーザーが所属するクラスIDの一覧を取得
+func (r *GroupMemberRepository) FindGroupIDsByUserID(ctx context.Context, userID string) ([]string, error) {
+	var groupIDs []string
+	err := dbFrom(ctx, r.db).Model(&model.GroupMember{}).
+		Where("user_id = ?", userID).
+		Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
+// DeleteByGroupAndUser クラス所属を削除
+func (r *GroupMemberRepository) DeleteByGroupAndUser(ctx context.Context, groupID, userID string) error {
+	return dbFrom(ctx, r.db).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&model.GroupMember{}).Error
+}
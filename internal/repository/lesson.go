@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
 	"time"
 
 	"gorm.io/gorm"
@@ -23,13 +22,13 @@ func NewLessonRepository(db *gorm.DB) *LessonRepository {
 
 // Create 授業を作成
 func (r *LessonRepository) Create(ctx context.Context, lesson *model.Lesson) error {
-	return r.db.WithContext(ctx).Create(lesson).Error
+	return dbFrom(ctx, r.db).Create(lesson).Error
 }
 
 // FindByID IDで授業を取得
 func (r *LessonRepository) FindByID(ctx context.Context, id string) (*model.Lesson, error) {
 	var lesson model.Lesson
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Subject", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "year", "org_id")
 		}).
@@ -50,7 +49,7 @@ func (r *LessonRepository) FindByID(ctx context.Context, id string) (*model.Less
 // FindByOrgID 組織IDで授業一覧を取得
 func (r *LessonRepository) FindByOrgID(ctx context.Context, orgID string) ([]model.Lesson, error) {
 	var lessons []model.Lesson
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Subject", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "year")
 		}).
@@ -63,117 +62,88 @@ func (r *LessonRepository) FindByOrgID(ctx context.Context, orgID string) ([]mod
 	return lessons, err
 }
 
-// FindByDate 特定の日付の授業を取得
-func (r *LessonRepository) FindByDate(ctx context.Context, orgID string, date time.Time) ([]model.Lesson, error) {
+// FindByRoomID 部屋IDで授業一覧を取得
+func (r *LessonRepository) FindByRoomID(ctx context.Context, roomID string) ([]model.Lesson, error) {
 	var lessons []model.Lesson
-
-	dayOfWeek := int(date.Weekday())
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Subject", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "name", "year")
+			return db.Select("id", "name", "year", "org_id")
 		}).
 		Preload("Room", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "org_room_id", "name", "mist_zone_id")
+			return db.Select("id", "org_id", "org_room_id", "name", "caption", "mist_zone_id")
 		}).
-		Where("org_id = ?", orgID).
-		Where("day_of_week = ?", dayOfWeek).
-		Where("start_time >= ? AND start_time < ?", startOfDay, endOfDay).
-		Order("start_time ASC").
+		Where("room_id = ?", roomID).
+		Order("day_of_week ASC, start_time ASC").
 		Find(&lessons).Error
-
 	return lessons, err
 }
 
-// FindByUserAndDate 特定ユーザーの特定日付の授業を取得
-// TODO: ユーザーと授業の紐付けテーブルが必要な場合はここを拡張
-func (r *LessonRepository) FindByUserAndDate(ctx context.Context, userID string, date time.Time) ([]model.Lesson, error) {
-	// 現状は組織の全授業を返す（ユーザーとLessonの中間テーブルがないため）
-	// 実装では、ユーザーの組織IDを取得してその組織の授業を返す
-
-	var user model.User
-	err := r.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrorRecordNotFound
-		}
-		return nil, err
-	}
-
-	return r.FindByDate(ctx, user.OrgID, date)
-}
-
-// FindMonitoringLessons 監視対象の授業を取得（開始5分前〜終了10分後）
-func (r *LessonRepository) FindMonitoringLessons(ctx context.Context, currentTime time.Time) ([]model.Lesson, error) {
+// FindByGroupID クラスIDで授業一覧を取得
+func (r *LessonRepository) FindByGroupID(ctx context.Context, groupID string) ([]model.Lesson, error) {
 	var lessons []model.Lesson
-
-	// 現在時刻の5分後から監視終了までの授業を取得
-	monitorStart := currentTime.Add(-5 * time.Minute)
-	monitorEnd := currentTime.Add(10 * time.Minute)
-
-	dayOfWeek := int(currentTime.Weekday())
-
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Subject", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "name")
+			return db.Select("id", "name", "year", "org_id")
 		}).
 		Preload("Room", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "org_room_id", "name", "mist_zone_id")
+			return db.Select("id", "org_id", "org_room_id", "name", "caption", "mist_zone_id")
 		}).
-		Where("day_of_week = ?", dayOfWeek).
-		Where(
-			"start_time <= ? AND end_time >= ?",
-			monitorEnd, monitorStart,
-		).
+		Where("group_id = ?", groupID).
+		Order("day_of_week ASC, start_time ASC").
 		Find(&lessons).Error
-
 	return lessons, err
 }
 
-// Update 授業を更新
-func (r *LessonRepository) Update(ctx context.Context, lesson *model.Lesson) error {
-	return r.db.WithContext(ctx).Save(lesson).Error
-}
+// FindWeeklyOn 指定日に実施される毎週の授業を取得
+// dayOfWeekの曜日の授業のうち、作成日が指定日以前かつ最終日が指定日以降のものが対象（特別時間割では別の曜日を指定する）
+func (r *LessonRepository) FindWeeklyOn(ctx context.Context, orgID string, date time.Time, dayOfWeek int) ([]model.Lesson, error) {
+	var lessons []model.Lesson
 
-// Delete 授業を削除
-func (r *LessonRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Lesson{}).Error
+	// 時刻はUTCで保存されている
+	endOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND date IS NULL AND day_of_week = ? AND start_time < ?", orgID, dayOfWeek, endOfDay).
+		Where("until IS NULL OR until >= ?", date.Format("2006-01-02")).
+		Find(&lessons).Error
+	return lessons, err
 }
 
-// FindByRoomAndTime 部屋IDと時刻から授業を検索
-func (r *LessonRepository) FindByRoomAndTime(ctx context.Context, roomID string, currentTime time.Time) (*model.Lesson, error) {
-	var lesson model.Lesson
+// FindDatedOn 指定日の日付指定の授業を取得
+func (r *LessonRepository) FindDatedOn(ctx context.Context, orgID string, date time.Time) ([]model.Lesson, error) {
+	var lessons []model.Lesson
 
-	dayOfWeek := int(currentTime.Weekday())
+	// 日付はUTCの0時で保存されている
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
-	// デバッグログ
-	log.Printf("[FindByRoomAndTime] 検索条件: RoomID=%s, CurrentTime=%s, DayOfWeek=%d",
-		roomID, currentTime.Format("2006-01-02 15:04:05"), dayOfWeek)
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND date >= ? AND date < ?", orgID, startOfDay, endOfDay).
+		Find(&lessons).Error
+	return lessons, err
+}
 
-	err := r.db.WithContext(ctx).
-		Preload("Subject", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "name", "year")
-		}).
-		Preload("Room", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "org_room_id", "name", "mist_zone_id")
-		}).
-		Where("room_id = ?", roomID).
-		Where("day_of_week = ?", dayOfWeek).
-		Where("start_time <= ?", currentTime).
-		Where("end_time >= ?", currentTime).
+// FindByICalUID iCalendarの予定のUIDと曜日で授業を取得
+func (r *LessonRepository) FindByICalUID(ctx context.Context, orgID, uid string, dayOfWeek int) (*model.Lesson, error) {
+	var lesson model.Lesson
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND ical_uid = ? AND day_of_week = ?", orgID, uid, dayOfWeek).
 		First(&lesson).Error
-
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Printf("[FindByRoomAndTime] 授業が見つかりませんでした")
 			return nil, ErrorRecordNotFound
 		}
-		log.Printf("[FindByRoomAndTime] エラー: %v", err)
 		return nil, err
 	}
-
-	log.Printf("[FindByRoomAndTime] 授業を発見: LessonID=%s, Subject=%s", lesson.ID, lesson.SubjectID)
 	return &lesson, nil
 }
+
+// Update 授業を更新
+func (r *LessonRepository) Update(ctx context.Context, lesson *model.Lesson) error {
+	return dbFrom(ctx, r.db).Save(lesson).Error
+}
+
+// Delete 授業を削除
+func (r *LessonRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.Lesson{}).Error
+}
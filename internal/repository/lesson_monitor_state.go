@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// LessonMonitorStateRepository 授業監視状態リポジトリ
+type LessonMonitorStateRepository struct {
+	db *gorm.DB
+}
+
+// NewLessonMonitorStateRepository 授業監視状態リポジトリを作成
+func NewLessonMonitorStateRepository(db *gorm.DB) *LessonMonitorStateRepository {
+	return &LessonMonitorStateRepository{db: db}
+}
+
+// FindByLessonAndDate 授業IDと日付で監視状態を取得
+func (r *LessonMonitorStateRepository) FindByLessonAndDate(ctx context.Context, lessonID string, lessonDate time.Time) (*model.LessonMonitorState, error) {
+	var state model.LessonMonitorState
+	err := dbFrom(ctx, r.db).
+		Where("lesson_id = ? AND lesson_date = ?", lessonID, lessonDate.Format("2006-01-02")).
+		First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Upsert 授業IDと日付をキーに監視状態を作成または更新
+func (r *LessonMonitorStateRepository) Upsert(ctx context.Context, state *model.LessonMonitorState) error {
+	return dbFrom(ctx, r.db).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "lesson_id"}, {Name: "lesson_date"}},
+				DoUpdates: clause.AssignmentColumns([]string{"phase", "observed_polls", "users", "updated_at"}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+		).
+		Create(state).Error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// LessonOccurrenceRepository 授業実施回リポジトリ
+type LessonOccurrenceRepository struct {
+	db *gorm.DB
+}
+
+// NewLessonOccurrenceRepository 授業実施回リポジトリを作成
+func NewLessonOccurrenceRepository(db *gorm.DB) *LessonOccurrenceRepository {
+	return &LessonOccurrenceRepository{db: db}
+}
+
+// Upsert 授業IDと日付をキーに実施回を作成または更新（時間割の変更を反映）
+func (r *LessonOccurrenceRepository) Upsert(ctx context.Context, occurrence *model.LessonOccurrence) error {
+	return dbFrom(ctx, r.db).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "lesson_id"}, {Name: "date"}},
+				DoUpdates: clause.AssignmentColumns([]string{"org_id", "subject_id", "room_id", "group_id", "start_at", "end_at", "period", "status", "reason", "overridden", "updated_at"}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+		).
+		Create(occurrence).Error
+}
+
+// FindByID IDで実施回を取得
+func (r *LessonOccurrenceRepository) FindByID(ctx context.Context, id string) (*model.LessonOccurrence, error) {
+	var occurrence model.LessonOccurrence
+	err := r.preload(dbFrom(ctx, r.db)).
+		Where("id = ?", id).
+		First(&occurrence).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+// FindByDate 指定日の実施予定の実施回一覧を取得（orgIDが空の場合は全組織が対象）
+func (r *LessonOccurrenceRepository) FindByDate(ctx context.Context, orgID string, date time.Time) ([]model.LessonOccurrence, error) {
+	var occurrences []model.LessonOccurrence
+	query := r.preload(dbFrom(ctx, r.db)).
+		Where("date = ? AND status = ?", date.Format("2006-01-02"), model.OccurrenceScheduled)
+	if orgID != "" {
+		query = query.Where("org_id = ?", orgID)
+	}
+	err := query.
+		Order("start_at ASC").
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// FindTimetable 組織の指定日の時間割を取得（実施予定と授業変更による休講の実施回）
+func (r *LessonOccurrenceRepository) FindTimetable(ctx context.Context, orgID string, date time.Time) ([]model.LessonOccurrence, error) {
+	var occurrences []model.LessonOccurrence
+	err := r.preload(dbFrom(ctx, r.db)).
+		Where("org_id = ? AND date = ?", orgID, date.Format("2006-01-02")).
+		Where("status = ? OR overridden = ?", model.OccurrenceScheduled, true).
+		Order("start_at ASC").
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// FindAllByOrgAndDate 組織の指定日の実施回一覧を休講も含めて取得
+func (r *LessonOccurrenceRepository) FindAllByOrgAndDate(ctx context.Context, orgID string, date time.Time) ([]model.LessonOccurrence, error) {
+	var occurrences []model.LessonOccurrence
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND date = ?", orgID, date.Format("2006-01-02")).
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// Cancel 実施回を休講にする
+func (r *LessonOccurrenceRepository) Cancel(ctx context.Context, id, reason string) error {
+	return dbFrom(ctx, r.db).
+		Model(&model.LessonOccurrence{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.OccurrenceCancelled,
+			"reason":     reason,
+			"overridden": false,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// FindOverlapping 指定期間と重なる実施予定の実施回一覧を取得
+func (r *LessonOccurrenceRepository) FindOverlapping(ctx context.Context, from, to time.Time) ([]model.LessonOccurrence, error) {
+	var occurrences []model.LessonOccurrence
+	err := r.preload(dbFrom(ctx, r.db)).
+		Where("start_at <= ? AND end_at >= ?", to, from).
+		Where("status = ?", model.OccurrenceScheduled).
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// FindByRoomAndTime 部屋IDと時刻から実施中の実施回を取得（休講は除く）
+func (r *LessonOccurrenceRepository) FindByRoomAndTime(ctx context.Context, roomID string, currentTime time.Time) (*model.LessonOccurrence, error) {
+	var occurrence model.LessonOccurrence
+	err := r.preload(dbFrom(ctx, r.db)).
+		Where("room_id = ? AND status = ?", roomID, model.OccurrenceScheduled).
+		Where("start_at <= ? AND end_at >= ?", currentTime, currentTime).
+		First(&occurrence).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+// preload 実施回の取得で共通のリレーションを読み込む
+func (r *LessonOccurrenceRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "subject_id", "room_id", "org_id", "day_of_week", "start_time", "end_time", "date", "period")
+		}).
+		Preload("Subject", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name", "year", "org_id")
+		}).
+		Preload("Room", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "org_id", "org_room_id", "name", "caption", "mist_zone_id")
+		})
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// LessonOverrideRepository 授業変更リポジトリ
+type LessonOverrideRepository struct {
+	db *gorm.DB
+}
+
+// NewLessonOverrideRepository 授業変更リポジトリを作成
+func NewLessonOverrideRepository(db *gorm.DB) *LessonOverrideRepository {
+	return &LessonOverrideRepository{db: db}
+}
+
+// Create 授業変更を作成
+func (r *LessonOverrideRepository) Create(ctx context.Context, override *model.LessonOverride) error {
+	return dbFrom(ctx, r.db).Create(override).Error
+}
+
+// FindByID IDで授業変更を取得
+func (r *LessonOverrideRepository) FindByID(ctx context.Context, id string) (*model.LessonOverride, error) {
+	var override model.LessonOverride
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// FindByLessonAndDate 授業IDと日付で授業変更を取得
+func (r *LessonOverrideRepository) FindByLessonAndDate(ctx context.Context, lessonID string, date time.Time) (*model.LessonOverride, error) {
+	var override model.LessonOverride
+	err := dbFrom(ctx, r.db).
+		Where("lesson_id = ? AND date = ?", lessonID, date.Format("2006-01-02")).
+		First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// FindByOrgAndDate 組織の指定日の授業変更一覧を取得
+func (r *LessonOverrideRepository) FindByOrgAndDate(ctx context.Context, orgID string, date time.Time) ([]model.LessonOverride, error) {
+	var overrides []model.LessonOverride
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND date = ?", orgID, date.Format("2006-01-02")).
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// FindByOrgID 組織IDで授業変更一覧を取得（from・toがゼロ値の場合は期間を限定しない）
+func (r *LessonOverrideRepository) FindByOrgID(ctx context.Context, orgID string, from, to time.Time) ([]model.LessonOverride, error) {
+	var overrides []model.LessonOverride
+	query := dbFrom(ctx, r.db).Where("org_id = ?", orgID)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to.Format("2006-01-02"))
+	}
+	err := query.Order("date ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+// Update 授業変更を更新
+func (r *LessonOverrideRepository) Update(ctx context.Context, override *model.LessonOverride) error {
+	return dbFrom(ctx, r.db).Save(override).Error
+}
+
+// Delete 授業変更を削除
+func (r *LessonOverrideRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.LessonOverride{}).Error
+}
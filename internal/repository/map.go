@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// MapRepository マップリポジトリ
+type MapRepository struct {
+	db *gorm.DB
+}
+
+// NewMapRepository マップリポジトリを作成
+func NewMapRepository(db *gorm.DB) *MapRepository {
+	return &MapRepository{db: db}
+}
+
+// Upsert MistマップIDをキーにマップを作成または更新
+// 既存の場合はIDと作成日時が既存の値で上書きされる
+func (r *MapRepository) Upsert(ctx context.Context, m *model.Map) error {
+	return dbFrom(ctx, r.db).
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "mist_map_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"name", "width", "height", "width_m", "height_m", "ppm",
+					"url", "thumbnail_url", "updated_at", "is_active",
+				}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+		).
+		Create(m).Error
+}
+
+// FindByID IDでマップを取得
+func (r *MapRepository) FindByID(ctx context.Context, id string) (*model.Map, error) {
+	var m model.Map
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&m).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindByMistMapID MistマップIDでマップを取得
+func (r *MapRepository) FindByMistMapID(ctx context.Context, mistMapID string) (*model.Map, error) {
+	var m model.Map
+	err := dbFrom(ctx, r.db).Where("mist_map_id = ?", mistMapID).First(&m).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindActive 有効なマップ一覧を取得
+func (r *MapRepository) FindActive(ctx context.Context) ([]model.Map, error) {
+	var maps []model.Map
+	err := dbFrom(ctx, r.db).Where("is_active = ?", true).Order("name").Find(&maps).Error
+	return maps, err
+}
+
+// DeactivateMissing 指定したMistマップID以外の有効なマップを無効化
+func (r *MapRepository) DeactivateMissing(ctx context.Context, mistMapIDs []string) (int64, error) {
+	query := dbFrom(ctx, r.db).Model(&model.Map{}).Where("is_active = ?", true)
+	if len(mistMapIDs) > 0 {
+		query = query.Where("mist_map_id NOT IN ?", mistMapIDs)
+	}
+	result := query.Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
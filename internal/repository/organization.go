@@ -21,13 +21,13 @@ func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
 
 // Create 組織を作成
 func (r *OrganizationRepository) Create(ctx context.Context, organization *model.Organization) error {
-	return r.db.WithContext(ctx).Create(organization).Error
+	return dbFrom(ctx, r.db).Create(organization).Error
 }
 
 // FindByID IDで組織を取得
 func (r *OrganizationRepository) FindByID(ctx context.Context, id string) (*model.Organization, error) {
 	var organization model.Organization
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&organization).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&organization).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrorRecordNotFound
@@ -40,7 +40,7 @@ func (r *OrganizationRepository) FindByID(ctx context.Context, id string) (*mode
 // FindByMail メールアドレスで組織を取得
 func (r *OrganizationRepository) FindByMail(ctx context.Context, mail string) (*model.Organization, error) {
 	var organization model.Organization
-	err := r.db.WithContext(ctx).Where("mail = ?", mail).First(&organization).Error
+	err := dbFrom(ctx, r.db).Where("mail = ?", mail).First(&organization).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrorRecordNotFound
@@ -53,21 +53,21 @@ func (r *OrganizationRepository) FindByMail(ctx context.Context, mail string) (*
 // FindAll 全組織を取得
 func (r *OrganizationRepository) FindAll(ctx context.Context) ([]model.Organization, error) {
 	var organizations []model.Organization
-	err := r.db.WithContext(ctx).Find(&organizations).Error
+	err := dbFrom(ctx, r.db).Find(&organizations).Error
 	return organizations, err
 }
 
 // Update 組織を更新
 func (r *OrganizationRepository) Update(ctx context.Context, organization *model.Organization) error {
-	return r.db.WithContext(ctx).Save(organization).Error
+	return dbFrom(ctx, r.db).Save(organization).Error
 }
 
 // SoftDelete 組織を削除（ソフトデリート）
 func (r *OrganizationRepository) SoftDelete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&model.Organization{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&model.Organization{}, "id = ?", id).Error
 }
 
 // HardDelete 組織を物理削除
 func (r *OrganizationRepository) HardDelete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Unscoped().Delete(&model.Organization{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Unscoped().Delete(&model.Organization{}, "id = ?", id).Error
 }
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// RefreshTokenRepository リフレッシュトークンリポジトリ
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository リフレッシュトークンリポジトリを作成
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create リフレッシュトークンを作成
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+// FindByHash トークンのハッシュでリフレッシュトークンを取得
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := dbFrom(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke 未失効のリフレッシュトークンを失効させる（失効させた場合はtrue）
+// 同じトークンを同時に使用した場合に1つだけが成功するよう、未失効の条件付きで更新する
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := dbFrom(ctx, r.db).Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeByAccountID アカウントの未失効のリフレッシュトークンをすべて失効させる
+func (r *RefreshTokenRepository) RevokeByAccountID(ctx context.Context, accountID string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&model.RefreshToken{}).
+		Where("account_id = ? AND revoked_at IS NULL", accountID).
+		Update("revoked_at", at).Error
+}
+
+// DeleteExpired 有効期限切れのリフレッシュトークンを削除
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	return dbFrom(ctx, r.db).Where("expires_at < ?", before).Delete(&model.RefreshToken{}).Error
+}
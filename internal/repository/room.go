@@ -21,13 +21,13 @@ func NewRoomRepository(db *gorm.DB) *RoomRepository {
 
 // Create 部屋を作成
 func (r *RoomRepository) Create(ctx context.Context, room *model.Room) error {
-	return r.db.WithContext(ctx).Create(room).Error
+	return dbFrom(ctx, r.db).Create(room).Error
 }
 
 // FindByID IDで部屋を取得
 func (r *RoomRepository) FindByID(ctx context.Context, id string) (*model.Room, error) {
 	var room model.Room
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -44,7 +44,7 @@ func (r *RoomRepository) FindByID(ctx context.Context, id string) (*model.Room,
 // FindByOrgID 組織IDで部屋一覧を取得
 func (r *RoomRepository) FindByOrgID(ctx context.Context, orgID string) ([]model.Room, error) {
 	var rooms []model.Room
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -55,7 +55,7 @@ func (r *RoomRepository) FindByOrgID(ctx context.Context, orgID string) ([]model
 // FindByOrgRoomID 組織IDと部屋IDで部屋を取得
 func (r *RoomRepository) FindByOrgRoomID(ctx context.Context, orgID, orgRoomID string) (*model.Room, error) {
 	var room model.Room
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -72,7 +72,7 @@ func (r *RoomRepository) FindByOrgRoomID(ctx context.Context, orgID, orgRoomID s
 // FindAll 全部屋を取得
 func (r *RoomRepository) FindAll(ctx context.Context) ([]model.Room, error) {
 	var rooms []model.Room
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -82,10 +82,10 @@ func (r *RoomRepository) FindAll(ctx context.Context) ([]model.Room, error) {
 
 // Update 部屋を更新
 func (r *RoomRepository) Update(ctx context.Context, room *model.Room) error {
-	return r.db.WithContext(ctx).Save(room).Error
+	return dbFrom(ctx, r.db).Save(room).Error
 }
 
 // Delete 部屋を削除
 func (r *RoomRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&model.Room{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&model.Room{}, "id = ?", id).Error
 }
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
 )
@@ -21,13 +23,57 @@ func NewStayRepository(db *gorm.DB) *StayRepository {
 
 // Create 滞在を作成
 func (r *StayRepository) Create(ctx context.Context, stay *model.Stay) error {
-	return r.db.WithContext(ctx).Create(stay).Error
+	return dbFrom(ctx, r.db).Create(stay).Error
+}
+
+// CreateOrGetByLessonDate 授業付きの滞在を作成（同じユーザー・授業・実施日の滞在が既にある場合は作成しない）
+// 既に存在した場合はstayを既存の滞在で置き換えてfalseを返す
+func (r *StayRepository) CreateOrGetByLessonDate(ctx context.Context, stay *model.Stay) (bool, error) {
+	if stay.LessonID == nil || stay.LessonDate == nil {
+		return true, r.Create(ctx, stay)
+	}
+
+	result := dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "user_id"}, {Name: "lesson_id"}, {Name: "lesson_date"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "lesson_id IS NOT NULL AND lesson_date IS NOT NULL"}}},
+			DoNothing:   true,
+		}).
+		Create(stay)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	existing, err := r.FindByUserLessonDate(ctx, stay.UserID, *stay.LessonID, *stay.LessonDate)
+	if err != nil {
+		return false, err
+	}
+	*stay = *existing
+	return false, nil
+}
+
+// FindByUserLessonDate ユーザーID・LessonID・授業実施日で滞在を取得
+func (r *StayRepository) FindByUserLessonDate(ctx context.Context, userID, lessonID string, lessonDate time.Time) (*model.Stay, error) {
+	var stay model.Stay
+	err := dbFrom(ctx, r.db).
+		Where("user_id = ? AND lesson_id = ? AND lesson_date = ?", userID, lessonID, lessonDate.Format("2006-01-02")).
+		First(&stay).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &stay, nil
 }
 
 // FindByID IDで滞在を取得
 func (r *StayRepository) FindByID(ctx context.Context, id int) (*model.Stay, error) {
 	var stay model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail", "created_at", "updated_at", "deleted_at")
 		}).
@@ -40,6 +86,9 @@ func (r *StayRepository) FindByID(ctx context.Context, id int) (*model.Stay, err
 		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "subject_id", "room_id", "org_id", "day_of_week", "start_time", "end_time", "period", "created_at", "updated_at")
 		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
 		Preload("Lesson.Subject", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "year", "org_id")
 		}).
@@ -60,7 +109,7 @@ func (r *StayRepository) FindByID(ctx context.Context, id int) (*model.Stay, err
 // FindByUserID ユーザーIDで滞在一覧を取得
 func (r *StayRepository) FindByUserID(ctx context.Context, userID string) ([]model.Stay, error) {
 	var stays []model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -73,6 +122,9 @@ func (r *StayRepository) FindByUserID(ctx context.Context, userID string) ([]mod
 		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "subject_id", "room_id", "day_of_week", "start_time", "end_time", "period")
 		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
 		Where("user_id = ?", userID).
 		Find(&stays).Error
 	return stays, err
@@ -81,7 +133,7 @@ func (r *StayRepository) FindByUserID(ctx context.Context, userID string) ([]mod
 // FindActiveByUserID ユーザーIDでアクティブな滞在を取得
 func (r *StayRepository) FindActiveByUserID(ctx context.Context, userID string) (*model.Stay, error) {
 	var stay model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -94,6 +146,9 @@ func (r *StayRepository) FindActiveByUserID(ctx context.Context, userID string)
 		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "subject_id", "room_id", "org_id", "day_of_week", "start_time", "end_time", "period")
 		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
 		Preload("Lesson.Subject", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "year")
 		}).
@@ -114,7 +169,7 @@ func (r *StayRepository) FindActiveByUserID(ctx context.Context, userID string)
 // FindActiveByUserAndLesson ユーザーIDとLessonIDでアクティブな滞在を取得
 func (r *StayRepository) FindActiveByUserAndLesson(ctx context.Context, userID string, lessonID string) (*model.Stay, error) {
 	var stay model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail")
 		}).
@@ -135,10 +190,25 @@ func (r *StayRepository) FindActiveByUserAndLesson(ctx context.Context, userID s
 	return &stay, nil
 }
 
+// FindActiveAutoWithLesson 授業に紐づく自動記録のアクティブな滞在一覧を取得
+func (r *StayRepository) FindActiveAutoWithLesson(ctx context.Context) ([]model.Stay, error) {
+	var stays []model.Stay
+	err := dbFrom(ctx, r.db).
+		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "subject_id", "room_id", "day_of_week", "start_time", "end_time", "period")
+		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
+		Where("is_active = ? AND source = ? AND lesson_id IS NOT NULL", true, "auto").
+		Find(&stays).Error
+	return stays, err
+}
+
 // FindByLessonID LessonIDで滞在一覧を取得
 func (r *StayRepository) FindByLessonID(ctx context.Context, lessonID string) ([]model.Stay, error) {
 	var stays []model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -151,15 +221,33 @@ func (r *StayRepository) FindByLessonID(ctx context.Context, lessonID string) ([
 		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "subject_id", "room_id", "day_of_week", "start_time", "end_time", "period")
 		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
 		Where("lesson_id = ?", lessonID).
 		Find(&stays).Error
 	return stays, err
 }
 
+// FindByLessonAndDate LessonIDと授業実施日で滞在一覧を取得
+func (r *StayRepository) FindByLessonAndDate(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.Stay, error) {
+	var stays []model.Stay
+	err := dbFrom(ctx, r.db).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "org_id", "mail")
+		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
+		Where("lesson_id = ? AND lesson_date = ?", lessonID, lessonDate.Format("2006-01-02")).
+		Find(&stays).Error
+	return stays, err
+}
+
 // FindByRoomID 部屋IDで滞在一覧を取得
 func (r *StayRepository) FindByRoomID(ctx context.Context, roomID string) ([]model.Stay, error) {
 	var stays []model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "org_id", "mail")
 		}).
@@ -172,6 +260,9 @@ func (r *StayRepository) FindByRoomID(ctx context.Context, roomID string) ([]mod
 		Preload("Lesson", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "start_time", "end_time", "period")
 		}).
+		Preload("Occurrence", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "lesson_id", "date", "start_at", "end_at", "room_id")
+		}).
 		Where("room_id = ?", roomID).
 		Find(&stays).Error
 	return stays, err
@@ -180,7 +271,7 @@ func (r *StayRepository) FindByRoomID(ctx context.Context, roomID string) ([]mod
 // FindActiveByRoomID 部屋IDでアクティブな滞在一覧を取得
 func (r *StayRepository) FindActiveByRoomID(ctx context.Context, roomID string) ([]model.Stay, error) {
 	var stays []model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail")
 		}).
@@ -198,7 +289,7 @@ func (r *StayRepository) FindActiveByRoomID(ctx context.Context, roomID string)
 // FindAll 全滞在を取得
 func (r *StayRepository) FindAll(ctx context.Context) ([]model.Stay, error) {
 	var stays []model.Stay
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail")
 		}).
@@ -214,17 +305,47 @@ func (r *StayRepository) FindAll(ctx context.Context) ([]model.Stay, error) {
 
 // Update 滞在を更新
 func (r *StayRepository) Update(ctx context.Context, stay *model.Stay) error {
-	return r.db.WithContext(ctx).Save(stay).Error
+	return dbFrom(ctx, r.db).Save(stay).Error
 }
 
 // Delete 滞在を削除
 func (r *StayRepository) Delete(ctx context.Context, id int) error {
-	return r.db.WithContext(ctx).Delete(&model.Stay{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&model.Stay{}, "id = ?", id).Error
+}
+
+// UpdateAttendance 滞在時間と出席判定結果を更新
+func (r *StayRepository) UpdateAttendance(ctx context.Context, id int, dwellMinutes int, attended *bool) error {
+	return dbFrom(ctx, r.db).Model(&model.Stay{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"dwell_minutes": dwellMinutes,
+			"attended":      attended,
+		}).Error
+}
+
+// CloseStay 指定した時刻で滞在を終了する
+func (r *StayRepository) CloseStay(ctx context.Context, id int, leavedAt time.Time) error {
+	return dbFrom(ctx, r.db).Model(&model.Stay{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_active": false,
+			"leaved_at": leavedAt,
+		}).Error
+}
+
+// ReopenStay 終了した滞在を再開する
+func (r *StayRepository) ReopenStay(ctx context.Context, id int) error {
+	return dbFrom(ctx, r.db).Model(&model.Stay{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_active": true,
+			"leaved_at": nil,
+		}).Error
 }
 
 // EndStay 滞在を終了する
 func (r *StayRepository) EndStay(ctx context.Context, id int) error {
-	err := r.db.WithContext(ctx).Model(&model.Stay{}).Where("id = ?", id).Updates(map[string]interface{}{
+	err := dbFrom(ctx, r.db).Model(&model.Stay{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"is_active": false,
 		"leaved_at": "NOW()",
 	}).Error
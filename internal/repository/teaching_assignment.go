@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// TeachingAssignmentRepository 教員の担当リポジトリ
+type TeachingAssignmentRepository struct {
+	db *gorm.DB
+}
+
+// NewTeachingAssignmentRepository 教員の担当リポジトリを作成
+func NewTeachingAssignmentRepository(db *gorm.DB) *TeachingAssignmentRepository {
+	return &TeachingAssignmentRepository{db: db}
+}
+
+// Create 教員の担当を作成
+func (r *TeachingAssignmentRepository) Create(ctx context.Context, assignment *model.TeachingAssignment) error {
+	return dbFrom(ctx, r.db).Create(assignment).Error
+}
+
+// FindByID IDで教員の担当を取得
+func (r *TeachingAssignmentRepository) FindByID(ctx context.Context, id string) (*model.TeachingAssignment, error) {
+	var assignment model.TeachingAssignment
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// FindByTarget 教員・教科・授業が一致する担当を取得（lessonIDがnilの場合は教科全体の担当）
+func (r *TeachingAssignmentRepository) FindByTarget(ctx context.Context, userID, subjectID string, lessonID *string) (*model.TeachingAssignment, error) {
+	var assignment model.TeachingAssignment
+	query := dbFrom(ctx, r.db).Where("user_id = ? AND subject_id = ?", userID, subjectID)
+	if lessonID != nil {
+		query = query.Where("lesson_id = ?", *lessonID)
+	} else {
+		query = query.Where("lesson_id IS NULL")
+	}
+	err := query.First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// FindByOrgID 組織IDで教員の担当一覧を取得（userIDが空でない場合はその教員で絞り込む）
+func (r *TeachingAssignmentRepository) FindByOrgID(ctx context.Context, orgID, userID string) ([]model.TeachingAssignment, error) {
+	var assignments []model.TeachingAssignment
+	query := dbFrom(ctx, r.db).Where("org_id = ?", orgID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("created_at ASC").Find(&assignments).Error
+	return assignments, err
+}
+
+// FindByUserID 教員の担当一覧を取得
+func (r *TeachingAssignmentRepository) FindByUserID(ctx context.Context, userID string) ([]model.TeachingAssignment, error) {
+	var assignments []model.TeachingAssignment
+	err := dbFrom(ctx, r.db).Where("user_id = ?", userID).Find(&assignments).Error
+	return assignments, err
+}
+
+// Delete 教員の担当を削除
+func (r *TeachingAssignmentRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.TeachingAssignment{}).Error
+}
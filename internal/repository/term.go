@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// TermRepository 学期リポジトリ
+type TermRepository struct {
+	db *gorm.DB
+}
+
+// NewTermRepository 学期リポジトリを作成
+func NewTermRepository(db *gorm.DB) *TermRepository {
+	return &TermRepository{db: db}
+}
+
+// Create 学期を作成
+func (r *TermRepository) Create(ctx context.Context, term *model.Term) error {
+	return dbFrom(ctx, r.db).Create(term).Error
+}
+
+// FindByID IDで学期を取得
+func (r *TermRepository) FindByID(ctx context.Context, id string) (*model.Term, error) {
+	var term model.Term
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&term).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &term, nil
+}
+
+// FindByOrgID 組織IDで学期一覧を取得
+func (r *TermRepository) FindByOrgID(ctx context.Context, orgID string) ([]model.Term, error) {
+	var terms []model.Term
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ?", orgID).
+		Order("start_date ASC").
+		Find(&terms).Error
+	return terms, err
+}
+
+// FindContaining 指定日を含む組織の学期を取得
+func (r *TermRepository) FindContaining(ctx context.Context, orgID string, date time.Time) (*model.Term, error) {
+	var term model.Term
+	day := date.Format("2006-01-02")
+	err := dbFrom(ctx, r.db).
+		Where("org_id = ? AND start_date <= ? AND end_date >= ?", orgID, day, day).
+		Order("start_date ASC").
+		First(&term).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &term, nil
+}
+
+// CountByOrgID 組織の学期数を取得
+func (r *TermRepository) CountByOrgID(ctx context.Context, orgID string) (int64, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&model.Term{}).Where("org_id = ?", orgID).Count(&count).Error
+	return count, err
+}
+
+// Update 学期を更新
+func (r *TermRepository) Update(ctx context.Context, term *model.Term) error {
+	return dbFrom(ctx, r.db).Save(term).Error
+}
+
+// Delete 学期を削除
+func (r *TermRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&model.Term{}).Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey ctxに紐付けたトランザクションのキー
+type txKey struct{}
+
+// Transactor トランザクションの実行
+// Runに渡した関数内では、同じctxを渡したリポジトリの処理がすべて同じトランザクションで実行される
+type Transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor トランザクションの実行を作成
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Run トランザクション内でfnを実行（fnがエラーを返した場合はロールバック）
+// すでにトランザクション内の場合はそのトランザクションで実行する
+func (t *Transactor) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Savepoint トランザクション内でセーブポイントを作成してfnを実行（fnがエラーを返した場合はセーブポイントまで戻す）
+// エラーになった処理の後もトランザクションを継続できる。トランザクション外の場合はfnをそのまま実行する
+func (t *Transactor) Savepoint(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok {
+		return fn(ctx)
+	}
+	if err := tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if rbErr := tx.RollbackTo(name).Error; rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+	return nil
+}
+
+// dbFrom ctxにトランザクションが紐付いていればそれを、なければdbを使用する
+func dbFrom(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
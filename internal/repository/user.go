@@ -23,13 +23,13 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 // Create ユーザーを作成
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return dbFrom(ctx, r.db).Create(user).Error
 }
 
 // FindByID IDでユーザーを取得
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -47,7 +47,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User,
 // FindByMail メールアドレスでユーザーを取得（最初の1件のみ）
 func (r *UserRepository) FindByMail(ctx context.Context, mail string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -64,7 +64,7 @@ func (r *UserRepository) FindByMail(ctx context.Context, mail string) (*model.Us
 // FindAllByMail メールアドレスでユーザー全件を取得（複数組織対応）
 func (r *UserRepository) FindAllByMail(ctx context.Context, mail string) ([]model.User, error) {
 	var users []model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -75,7 +75,7 @@ func (r *UserRepository) FindAllByMail(ctx context.Context, mail string) ([]mode
 // FindByOrgIDAndMail 組織IDとメールアドレスでユーザーを取得
 func (r *UserRepository) FindByOrgIDAndMail(ctx context.Context, orgID, mail string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -92,7 +92,7 @@ func (r *UserRepository) FindByOrgIDAndMail(ctx context.Context, orgID, mail str
 // FindByOrgID 組織IDでユーザー一覧を取得
 func (r *UserRepository) FindByOrgID(ctx context.Context, orgID string) ([]model.User, error) {
 	var users []model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -103,7 +103,7 @@ func (r *UserRepository) FindByOrgID(ctx context.Context, orgID string) ([]model
 // FindAll 全ユーザーを取得
 func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
 	var users []model.User
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Organization", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "mail", "name", "created_at", "updated_at")
 		}).
@@ -113,15 +113,15 @@ func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
 
 // Update ユーザーを更新
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return dbFrom(ctx, r.db).Save(user).Error
 }
 
 // SoftDelete ユーザーを削除（ソフトデリート）
 func (r *UserRepository) SoftDelete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&model.User{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&model.User{}, "id = ?", id).Error
 }
 
 // HardDelete ユーザーを物理削除
 func (r *UserRepository) HardDelete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Unscoped().Delete(&model.User{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Unscoped().Delete(&model.User{}, "id = ?", id).Error
 }
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// ZoneRepository ゾーンリポジトリ
+type ZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewZoneRepository ゾーンリポジトリを作成
+func NewZoneRepository(db *gorm.DB) *ZoneRepository {
+	return &ZoneRepository{db: db}
+}
+
+// Upsert MistゾーンIDをキーにゾーンを作成または更新
+// 既存の場合はIDと作成日時が既存の値で上書きされる
+func (r *ZoneRepository) Upsert(ctx context.Context, zone *model.Zone) error {
+	return dbFrom(ctx, r.db).
+		Omit("Map").
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "mist_zone_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"name", "map_id", "mist_map_id", "vertices", "updated_at", "is_active",
+				}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+		).
+		Create(zone).Error
+}
+
+// FindByMistZoneID MistゾーンIDでゾーンを取得
+func (r *ZoneRepository) FindByMistZoneID(ctx context.Context, mistZoneID string) (*model.Zone, error) {
+	var zone model.Zone
+	err := dbFrom(ctx, r.db).Where("mist_zone_id = ?", mistZoneID).First(&zone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// FindActive 有効なゾーン一覧を取得
+func (r *ZoneRepository) FindActive(ctx context.Context) ([]model.Zone, error) {
+	var zones []model.Zone
+	err := dbFrom(ctx, r.db).Where("is_active = ?", true).Order("name").Find(&zones).Error
+	return zones, err
+}
+
+// FindActiveByMistMapID MistマップIDで有効なゾーン一覧を取得
+func (r *ZoneRepository) FindActiveByMistMapID(ctx context.Context, mistMapID string) ([]model.Zone, error) {
+	var zones []model.Zone
+	err := dbFrom(ctx, r.db).Where("mist_map_id = ? AND is_active = ?", mistMapID, true).Order("name").Find(&zones).Error
+	return zones, err
+}
+
+// DeactivateMissing 指定したMistゾーンID以外の有効なゾーンを無効化
+func (r *ZoneRepository) DeactivateMissing(ctx context.Context, mistZoneIDs []string) (int64, error) {
+	query := dbFrom(ctx, r.db).Model(&model.Zone{}).Where("is_active = ?", true)
+	if len(mistZoneIDs) > 0 {
+		query = query.Where("mist_zone_id NOT IN ?", mistZoneIDs)
+	}
+	result := query.Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
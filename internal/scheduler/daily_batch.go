@@ -40,7 +40,14 @@ func (d *DailyBatchScheduler) Start() {
 		nextMidnight.Format("2006-01-02 15:04:05"), initialDelay.Hours())
 
 	// 初回実行まで待機
-	time.Sleep(initialDelay)
+	timer := time.NewTimer(initialDelay)
+	select {
+	case <-d.stopChan:
+		timer.Stop()
+		log.Println("[DailyBatchScheduler] 日次バッチスケジューラーを停止しました")
+		return
+	case <-timer.C:
+	}
 
 	// 毎日深夜0時に実行
 	ticker := time.NewTicker(24 * time.Hour)
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LeaderElector Postgresのアドバイザリロックによるリーダー選出
+//
+// ロックはセッション単位のため専用の接続を保持し続け、リーダーのプロセスが停止して
+// 接続が切れるとPostgresがロックを解放する。他のレプリカは一定間隔でロックの取得を
+// 試みるため、リーダーが停止すると自動的に引き継がれる。
+type LeaderElector struct {
+	db       *gorm.DB
+	lockKey  int64
+	interval time.Duration // ロック取得の再試行・接続確認の間隔
+}
+
+// NewLeaderElector リーダー選出を作成
+func NewLeaderElector(db *gorm.DB, lockKey int64, interval time.Duration) *LeaderElector {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &LeaderElector{
+		db:       db,
+		lockKey:  lockKey,
+		interval: interval,
+	}
+}
+
+// Run ctxがキャンセルされるまでリーダー選出を繰り返す
+// リーダーになるとonElectedを呼び出し、リーダーでなくなった場合はonElectedに渡したctxをキャンセルする
+// onElectedは渡されたctxがキャンセルされるまで戻らないこと
+func (l *LeaderElector) Run(ctx context.Context, onElected func(ctx context.Context)) {
+	log.Printf("[LeaderElector] 開始 (ロックキー: %d, 間隔: %s)", l.lockKey, l.interval)
+
+	for {
+		conn, err := l.tryAcquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[LeaderElector] ロック取得エラー: %v", err)
+		}
+
+		if conn != nil {
+			log.Println("[LeaderElector] リーダーになりました")
+			l.lead(ctx, conn, onElected)
+			log.Println("[LeaderElector] リーダーを降りました")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[LeaderElector] 停止")
+			return
+		case <-time.After(l.interval):
+		}
+	}
+}
+
+// tryAcquire 専用の接続でロックの取得を試みる（取得できなかった場合はnilを返す）
+func (l *LeaderElector) tryAcquire(ctx context.Context) (*sql.Conn, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.lockKey).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, nil
+	}
+	return conn, nil
+}
+
+// lead リーダーとしてonElectedを実行し、接続が切れるかctxがキャンセルされたら終了を待ってロックを解放
+func (l *LeaderElector) lead(ctx context.Context, conn *sql.Conn, onElected func(ctx context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		onElected(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-leaderCtx.Done():
+			break loop
+		case <-done:
+			break loop
+		case <-ticker.C:
+			// 接続が切れている場合はロックも失われているため、他のレプリカに引き継ぐ
+			pingCtx, pingCancel := context.WithTimeout(ctx, l.interval)
+			err := conn.PingContext(pingCtx)
+			pingCancel()
+			if err != nil {
+				log.Printf("[LeaderElector] ロック用の接続が切れました: %v", err)
+				break loop
+			}
+		}
+	}
+
+	cancel()
+	<-done
+
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unlockCancel()
+	if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", l.lockKey); err != nil {
+		log.Printf("[LeaderElector] ロック解放エラー: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		log.Printf("close error: %v\n", err)
+	}
+}
@@ -2,28 +2,67 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
 )
 
 // LessonMonitor 授業監視ワーカー
+// Mist APIは直接呼び出さず、SitePollerから配信されるスナップショットを処理する
 type LessonMonitor struct {
-	lesson        model.Lesson
+	lesson        model.Lesson // 開始・終了時刻は実施日の時刻
+	occurrenceID  string
+	lessonDate    time.Time // 監視対象の日付（状態の保存キー）
 	scheduler     *LessonScheduler
-	recordedUsers map[string]bool // すでに記録したユーザー
+	recordedUsers map[string]bool             // すでに記録したユーザー
+	zoneID        string                      // 監視対象のMistゾーンID（checkZoneで更新）
+	sightings     chan zoneSighting           // Webhookなどで通知されたデバイス検知
+	reschedules   chan model.LessonOccurrence // 授業変更（教室変更・時間変更）が反映された実施回
+	scheduleKey   string                      // 監視中の実施回の部屋・時刻（スケジューラーのみが参照）
 	stopChan      chan struct{}
+	stopOnce      sync.Once
+	config        usecase.AttendanceConfig
+	roster        model.LessonRoster // 授業の対象者（履修者・クラスの所属者）。checkZoneで更新
+
+	// 在室状況（途中退室の判定用）
+	stayIDs   map[string]int       // ユーザーID → 自動作成した滞在ログID
+	lastSeen  map[string]time.Time // ユーザーID → 最後に検知した時刻
+	leftUsers map[string]bool      // 不在が続いたため滞在ログを終了したユーザー
+
+	// 滞在時間の集計（授業時間内のスナップショットのみ）
+	presentPolls  map[string]int // ユーザーID → 在室を検知したスナップショット数
+	observedPolls int            // 受信したスナップショット数
+
+	state *model.LessonMonitorState // 永続化した監視状態
+	dirty bool                      // 未保存の変更があるか
 }
 
-// NewLessonMonitor 授業監視ワーカーを作成
-func NewLessonMonitor(lesson model.Lesson, scheduler *LessonScheduler) *LessonMonitor {
+// NewLessonMonitor 授業の実施回の監視ワーカーを作成
+func NewLessonMonitor(occurrence model.LessonOccurrence, scheduler *LessonScheduler) *LessonMonitor {
+	lesson := occurrence.LessonOnDate()
+	y, mo, d := occurrence.Date.Date()
 	return &LessonMonitor{
 		lesson:        lesson,
+		occurrenceID:  occurrence.ID,
+		lessonDate:    time.Date(y, mo, d, 0, 0, 0, 0, time.Local),
 		scheduler:     scheduler,
 		recordedUsers: make(map[string]bool),
+		zoneID:        lesson.Room.MistZoneID,
+		sightings:     make(chan zoneSighting, 256),
+		reschedules:   make(chan model.LessonOccurrence, 1),
+		scheduleKey:   scheduleKey(occurrence),
 		stopChan:      make(chan struct{}),
+		config:        usecase.DefaultAttendanceConfig(),
+		stayIDs:       make(map[string]int),
+		lastSeen:      make(map[string]time.Time),
+		leftUsers:     make(map[string]bool),
+		presentPolls:  make(map[string]int),
 	}
 }
 
@@ -32,53 +71,108 @@ func (m *LessonMonitor) Start() {
 	defer m.cleanup()
 
 	// 監視期間
-	monitorStart := m.lesson.StartTime.Add(-5 * time.Minute)
-	monitorEnd := m.lesson.EndTime.Add(10 * time.Minute)
+	monitorStart, monitorEnd := monitorPeriod(m.lesson)
 
+	// 監視終了の判定用
 	ticker := time.NewTicker(60 * time.Second)
 	defer ticker.Stop()
 
+	// 再起動前の監視状態を復元（終了処理済みなら何もしない）
+	if finished := m.restoreState(); finished {
+		log.Printf("[LessonMonitor] 本日の授業終了処理は完了済みです: Lesson=%s", m.lesson.ID)
+		m.scheduler.markFinished(m.lesson.ID, m.lessonDate)
+		return
+	}
+
+	// 授業の対象者（履修者・クラスの所属者）以外は出席を記録しない
+	m.refreshRoster(context.Background())
+
+	snapshots := m.scheduler.poller.Subscribe(m.lesson.ID)
+	defer m.scheduler.poller.Unsubscribe(m.lesson.ID)
+
 	log.Printf("[LessonMonitor] 監視開始: Lesson=%s, 期間=%s〜%s",
 		m.lesson.ID,
 		monitorStart.Format("15:04"),
 		monitorEnd.Format("15:04"))
 
-	// 即座に1回チェック
-	m.checkZone()
-
 	for {
 		select {
 		case <-m.stopChan:
 			log.Printf("[LessonMonitor] 停止: Lesson=%s", m.lesson.ID)
 			return
-		case <-ticker.C:
-			now := time.Now()
+		case snapshot := <-snapshots:
+			// 監視開始前なら無視
+			if snapshot.TakenAt.Before(monitorStart) {
+				continue
+			}
+
+			// Zone監視実行
+			m.checkZone(snapshot)
+		case sighting := <-m.sightings:
+			// 監視開始前または別ゾーンの検知なら無視
+			if sighting.seenAt.Before(monitorStart) || m.zoneID == "" || sighting.zoneID != m.zoneID {
+				continue
+			}
 
+			m.processDevice(sighting.deviceID, sighting.seenAt)
+			if m.dirty {
+				m.saveState(model.MonitorPhaseMonitoring)
+			}
+		case occurrence := <-m.reschedules:
+			// 教室変更・時間変更を反映（記録済みの出席はそのまま引き継ぐ）
+			m.lesson = occurrence.LessonOnDate()
+			m.occurrenceID = occurrence.ID
+			m.zoneID = m.lesson.Room.MistZoneID
+			monitorStart, monitorEnd = monitorPeriod(m.lesson)
+			log.Printf("[LessonMonitor] 授業変更を反映: Lesson=%s, Room=%s, 期間=%s〜%s",
+				m.lesson.ID, m.lesson.RoomID, monitorStart.Format("15:04"), monitorEnd.Format("15:04"))
+		case <-ticker.C:
 			// 監視終了チェック
-			if now.After(monitorEnd) {
+			if time.Now().After(monitorEnd) {
 				log.Printf("[LessonMonitor] 監視終了: Lesson=%s", m.lesson.ID)
 				m.finishLesson()
 				return
 			}
-
-			// 監視開始前なら待機
-			if now.Before(monitorStart) {
-				continue
-			}
-
-			// Zone監視実行
-			m.checkZone()
 		}
 	}
 }
 
 // Stop 監視を停止
 func (m *LessonMonitor) Stop() {
-	close(m.stopChan)
+	m.stopOnce.Do(func() { close(m.stopChan) })
+}
+
+// monitorPeriod 授業の監視期間（終了時刻を過ぎたら終了処理を行う）
+func monitorPeriod(lesson model.Lesson) (time.Time, time.Time) {
+	return lesson.StartTime.Add(-model.MonitorLeadTime), lesson.EndTime.Add(model.MonitorTrailTime)
+}
+
+// reschedule 授業変更が反映された実施回を通知（未処理の通知は新しいもので置き換える）
+func (m *LessonMonitor) reschedule(occurrence model.LessonOccurrence) {
+	select {
+	case <-m.reschedules:
+	default:
+	}
+	m.reschedules <- occurrence
+}
+
+// scheduleKey 実施回の部屋・時刻の組み合わせ（変更の検知用）
+func scheduleKey(occurrence model.LessonOccurrence) string {
+	return occurrence.RoomID + "/" + occurrence.StartAt.Format(time.RFC3339) + "/" + occurrence.EndAt.Format(time.RFC3339)
+}
+
+// notify デバイス検知を通知（監視ゴルーチンで処理される）
+func (m *LessonMonitor) notify(sighting zoneSighting) {
+	select {
+	case m.sightings <- sighting:
+	default:
+		// 溢れた分は次回のポーリングで拾う
+		log.Printf("[LessonMonitor] 検知通知のキューが一杯のため破棄しました: Lesson=%s, Device=%s", m.lesson.ID, sighting.deviceID)
+	}
 }
 
-// checkZone Zone内のデバイスをチェック
-func (m *LessonMonitor) checkZone() {
+// checkZone スナップショットからZone内のデバイスをチェック
+func (m *LessonMonitor) checkZone(snapshot *ZoneSnapshot) {
 	ctx := context.Background()
 
 	// Room情報を取得
@@ -92,43 +186,78 @@ func (m *LessonMonitor) checkZone() {
 		log.Printf("[LessonMonitor] Room(%s)にMistZoneIDが設定されていません", room.ID)
 		return
 	}
+	m.zoneID = room.MistZoneID
+	m.refreshRoster(ctx)
 
-	// Mist APIでZone内のデバイス一覧を取得
-	sdkClients, wirelessClients, err := m.scheduler.mistClient.GetZoneClients(
-		m.scheduler.mistClient.SiteID,
-		room.MistZoneID,
-	)
-	if err != nil {
-		log.Printf("[LessonMonitor] Zone監視エラー: %v", err)
+	if _, ok := snapshot.Zones[room.MistZoneID]; !ok {
+		log.Printf("[LessonMonitor] Zone(%s)がMistに存在しません (Room=%s)", room.MistZoneID, room.ID)
 		return
 	}
 
-	// BLEデバイスも検知
-	var bleDevices []string
-	if room.MapID != "" {
-		bleDeviceList, err := m.scheduler.mistClient.GetZoneBLEDevices(
-			m.scheduler.mistClient.SiteID,
-			room.MistZoneID,
-			room.MapID,
-		)
-		if err != nil {
-			log.Printf("[LessonMonitor] BLEデバイス取得エラー: %v", err)
-		} else {
-			for _, bleDevice := range bleDeviceList {
-				bleDevices = append(bleDevices, bleDevice.Mac)
-			}
+	devices := snapshot.DevicesInZone(room.MistZoneID)
+
+	log.Printf("[LessonMonitor] 検知デバイス数: %d (Lesson=%s, Zone=%s, 取得時刻=%s)",
+		len(devices), m.lesson.ID, room.MistZoneID, snapshot.TakenAt.Format("15:04:05"))
+
+	// 授業時間内のスナップショットのみ滞在時間に数える
+	inLesson := !snapshot.TakenAt.Before(m.lesson.StartTime) && !snapshot.TakenAt.After(m.lesson.EndTime)
+	if inLesson {
+		m.observedPolls++
+	}
+
+	// 各デバイスについて処理（複数デバイスを持つユーザーは1回だけ数える）
+	presentUsers := make(map[string]bool)
+	for _, deviceID := range devices {
+		if userID := m.processDevice(deviceID, snapshot.TakenAt); userID != "" {
+			presentUsers[userID] = true
 		}
 	}
 
-	allDevices := append(sdkClients, wirelessClients...)
-	allDevices = append(allDevices, bleDevices...)
+	if inLesson {
+		for userID := range presentUsers {
+			m.presentPolls[userID]++
+		}
+	}
+
+	// 一定時間検知されていないユーザーの滞在ログを終了
+	m.closeAbsentStays(snapshot.TakenAt)
+
+	m.saveState(model.MonitorPhaseMonitoring)
+}
+
+// refreshRoster 授業の対象者を再取得（取得できない場合は前回の対象者を使用）
+func (m *LessonMonitor) refreshRoster(ctx context.Context) {
+	roster, err := m.scheduler.enrollmentService.GetRoster(ctx, m.lesson.SubjectID, m.lesson.GroupID)
+	if err != nil {
+		log.Printf("[LessonMonitor] 履修者取得エラー: Lesson=%s, %v", m.lesson.ID, err)
+		return
+	}
+	m.roster = roster
+}
+
+// closeAbsentStays 猶予時間を超えて検知されていないユーザーの滞在ログを最終検知時刻で終了
+// スナップショットを受信できている間のみ呼び出す（ポーリング停止中に誤って退室扱いしないため）
+func (m *LessonMonitor) closeAbsentStays(now time.Time) {
+	ctx := context.Background()
+	grace := time.Duration(m.config.AbsenceGraceMinutes) * time.Minute
 
-	log.Printf("[LessonMonitor] 検知デバイス数: %d (WiFi/SDK: %d, BLE: %d) (Lesson=%s, Zone=%s)",
-		len(allDevices), len(sdkClients)+len(wirelessClients), len(bleDevices), m.lesson.ID, room.MistZoneID)
+	for userID, stayID := range m.stayIDs {
+		if m.leftUsers[userID] {
+			continue
+		}
+		lastSeen := m.lastSeen[userID]
+		if now.Sub(lastSeen) <= grace {
+			continue
+		}
 
-	// 各デバイスについて処理
-	for _, deviceID := range allDevices {
-		m.processDevice(deviceID)
+		if err := m.scheduler.stayService.CloseStay(ctx, stayID, lastSeen); err != nil {
+			log.Printf("[LessonMonitor] 退出処理エラー: User=%s, %v", userID, err)
+			continue
+		}
+		m.leftUsers[userID] = true
+		m.dirty = true
+		log.Printf("[LessonMonitor] 退室検知: User=%s, Lesson=%s, 最終検知=%s",
+			userID, m.lesson.ID, lastSeen.Format("15:04:05"))
 	}
 }
 
@@ -138,7 +267,8 @@ func normalizeMACAddress(mac string) string {
 }
 
 // processDevice デバイスを処理して出席記録
-func (m *LessonMonitor) processDevice(deviceID string) {
+// 本日認証済みのデバイスであれば紐づくユーザーIDを返す（それ以外は空文字）
+func (m *LessonMonitor) processDevice(deviceID string, seenAt time.Time) string {
 	ctx := context.Background()
 
 	// MACアドレス形式を統一
@@ -148,43 +278,82 @@ func (m *LessonMonitor) processDevice(deviceID string) {
 	device, err := m.scheduler.deviceService.GetByDeviceID(ctx, normalizedDeviceID)
 	if err != nil {
 		// デバイスが登録されていない
-		return
+		return ""
 	}
 
 	userID := device.UserID
 
-	// すでに記録済みかチェック
-	if m.recordedUsers[userID] {
-		return
-	}
-
 	// 本日認証済みかチェック
 	if !device.IsAuthenticatedToday() {
 		log.Printf("[LessonMonitor] 未認証デバイス: User=%s, Device=%s", userID, deviceID)
-		return
+		return ""
+	}
+
+	// 授業の対象者でないユーザー（同じ部屋にいる別の授業の受講者など）は記録しない
+	if !m.roster.Includes(userID) {
+		return ""
+	}
+
+	if seenAt.After(m.lastSeen[userID]) {
+		m.lastSeen[userID] = seenAt
+		m.dirty = true
+	}
+
+	// すでに記録済みかチェック
+	if m.recordedUsers[userID] {
+		// 退室扱いにした後に戻ってきた場合は滞在ログを再開
+		if m.leftUsers[userID] {
+			if err := m.scheduler.stayService.ReopenStay(ctx, m.stayIDs[userID]); err != nil {
+				log.Printf("[LessonMonitor] 滞在ログ再開エラー: User=%s, %v", userID, err)
+			} else {
+				delete(m.leftUsers, userID)
+				log.Printf("[LessonMonitor] 再入室: User=%s, Lesson=%s", userID, m.lesson.ID)
+			}
+		}
+		return userID
 	}
 
-	// 滞在ログを作成
-	now := time.Now()
+	// 滞在ログを作成（入室時刻は検知時刻。遅れて届いたWebhookでも発生時刻で記録する）
+	now := seenAt
 	lessonID := m.lesson.ID
+	occurrenceID := m.occurrenceID
+	lessonDate := m.lessonDate
 	stay := &model.Stay{
-		UserID:    userID,
-		RoomID:    m.lesson.RoomID,
-		SubjectID: m.lesson.SubjectID,
-		LessonID:  &lessonID,
-		Source:    "auto",
-		IsActive:  true,
-		CreatedAt: now,
+		UserID:       userID,
+		RoomID:       m.lesson.RoomID,
+		SubjectID:    m.lesson.SubjectID,
+		LessonID:     &lessonID,
+		OccurrenceID: &occurrenceID,
+		LessonDate:   &lessonDate,
+		Source:       "auto",
+		IsActive:     true,
+		CreatedAt:    now,
 	}
 
-	err = m.scheduler.stayService.CreateWithLesson(ctx, stay)
+	created, err := m.scheduler.stayService.CreateWithLesson(ctx, stay)
 	if err != nil {
 		log.Printf("[LessonMonitor] 滞在ログ作成エラー: %v", err)
-		return
+		return ""
+	}
+	if !created {
+		// 以前の監視で自動記録済みの場合はその滞在ログを引き継ぐ
+		// 手動入室の滞在ログは本人の退室操作に任せ、記録済みとしてマークするだけにする
+		log.Printf("[LessonMonitor] 記録済みの滞在ログを使用: User=%s, Lesson=%s, Stay=%d, Source=%s", userID, m.lesson.ID, stay.ID, stay.Source)
+		m.recordedUsers[userID] = true
+		if stay.Source == "auto" {
+			m.stayIDs[userID] = stay.ID
+			if !stay.IsActive {
+				m.leftUsers[userID] = true
+			}
+		}
+		m.dirty = true
+		return userID
 	}
 
 	// 記録済みとしてマーク
 	m.recordedUsers[userID] = true
+	m.stayIDs[userID] = stay.ID
+	m.dirty = true
 
 	// 遅刻判定
 	lateMinutes := 0
@@ -199,35 +368,163 @@ func (m *LessonMonitor) processDevice(deviceID string) {
 		log.Printf("[LessonMonitor] 出席記録（定刻）: User=%s, Lesson=%s, Time=%s",
 			userID, m.lesson.ID, now.Format("15:04:05"))
 	}
+	return userID
 }
 
 // finishLesson 授業を終了
+// 授業時間内の滞在割合から出席を確定し、滞在ログを終了する
 func (m *LessonMonitor) finishLesson() {
 	ctx := context.Background()
+	config := m.config
 
-	log.Printf("[LessonMonitor] 授業終了処理開始: Lesson=%s, 出席者数=%d",
-		m.lesson.ID, len(m.recordedUsers))
+	log.Printf("[LessonMonitor] 授業終了処理開始: Lesson=%s, 入室者数=%d, 集計スナップショット数=%d",
+		m.lesson.ID, len(m.recordedUsers), m.observedPolls)
 
-	// すべての滞在ログを終了
-	for userID := range m.recordedUsers {
-		stay, err := m.scheduler.stayService.GetActiveByUserAndLesson(ctx, userID, m.lesson.ID)
-		if err != nil || stay == nil {
+	stays, err := m.scheduler.stayService.GetByLessonAndDate(ctx, m.lesson.ID, m.lessonDate)
+	if err != nil {
+		log.Printf("[LessonMonitor] 滞在ログ取得エラー: Lesson=%s, %v", m.lesson.ID, err)
+		return
+	}
+
+	attendedCount := 0
+	for i := range stays {
+		stay := &stays[i]
+		userID := stay.UserID
+
+		// 自動記録した滞在のみ対象（手動入室は判定しない）
+		if !m.recordedUsers[userID] || stay.Source != "auto" {
 			continue
 		}
 
-		stay.IsActive = false
-		now := time.Now()
-		stay.LeavedAt = &now
+		// 滞在時間と出席判定（スナップショットを1件も受信できなかった場合は判定しない）
+		dwellMinutes, attended := decideAttendance(m.presentPolls[userID], m.observedPolls, m.scheduler.poller.interval, config.MinPresenceRatio)
+		if attended != nil {
+			if *attended {
+				attendedCount++
+			}
+			log.Printf("[LessonMonitor] 出席判定: User=%s, Lesson=%s, 滞在=%d分, 在室割合=%.0f%%, 出席=%v",
+				userID, m.lesson.ID, dwellMinutes, float64(m.presentPolls[userID])/float64(m.observedPolls)*100, *attended)
+		}
+
+		if err := m.scheduler.stayService.RecordAttendance(ctx, stay, dwellMinutes, attended); err != nil {
+			log.Printf("[LessonMonitor] 出席判定の記録エラー: User=%s, %v", userID, err)
+		}
+
+		if !stay.IsActive {
+			continue
+		}
 
-		err = m.scheduler.stayService.Update(ctx, stay, stay.SubjectID, stay.Description)
-		if err != nil {
+		// 滞在ログを最終検知時刻で終了
+		leavedAt := time.Now()
+		if lastSeen, ok := m.lastSeen[userID]; ok && lastSeen.Before(leavedAt) {
+			leavedAt = lastSeen
+		}
+		if err := m.scheduler.stayService.CloseStay(ctx, stay.ID, leavedAt); err != nil {
 			log.Printf("[LessonMonitor] 退出処理エラー: User=%s, %v", userID, err)
 		} else {
 			log.Printf("[LessonMonitor] 自動退出: User=%s, Lesson=%s", userID, m.lesson.ID)
 		}
 	}
 
-	log.Printf("[LessonMonitor] 授業終了処理完了: Lesson=%s", m.lesson.ID)
+	m.saveState(model.MonitorPhaseFinished)
+	m.scheduler.markFinished(m.lesson.ID, m.lessonDate)
+
+	log.Printf("[LessonMonitor] 授業終了処理完了: Lesson=%s, 出席者数=%d", m.lesson.ID, attendedCount)
+}
+
+// decideAttendance 在室を検知したスナップショット数から滞在時間（分）と出席を判定
+// スナップショットを1件も受信できなかった場合は出席を判定しない（nil）
+func decideAttendance(presentPolls, observedPolls int, interval time.Duration, minPresenceRatio float64) (int, *bool) {
+	dwellMinutes := int((time.Duration(presentPolls) * interval).Minutes())
+	if observedPolls <= 0 {
+		return dwellMinutes, nil
+	}
+	attended := float64(presentPolls)/float64(observedPolls) >= minPresenceRatio
+	return dwellMinutes, &attended
+}
+
+// restoreState 保存済みの監視状態を復元し、終了処理済みかどうかを返す
+// 状態が保存されていない場合は本日の自動記録の滞在ログから記録済みユーザーを復元する
+func (m *LessonMonitor) restoreState() bool {
+	ctx := context.Background()
+
+	state, err := m.scheduler.monitorStateService.Get(ctx, m.lesson.ID, m.lessonDate)
+	if err == nil {
+		m.state = state
+		m.observedPolls = state.ObservedPolls
+		for userID, u := range state.Users {
+			m.recordedUsers[userID] = true
+			if u.StayID != 0 {
+				// 手動入室のユーザーは滞在ログIDを保存していない
+				m.stayIDs[userID] = u.StayID
+			}
+			m.lastSeen[userID] = u.LastSeen
+			m.presentPolls[userID] = u.PresentPolls
+			if u.Left {
+				m.leftUsers[userID] = true
+			}
+		}
+		if len(state.Users) > 0 {
+			log.Printf("[LessonMonitor] 監視状態を復元: Lesson=%s, 記録済み=%d, 集計スナップショット数=%d",
+				m.lesson.ID, len(state.Users), state.ObservedPolls)
+		}
+		return state.Phase == model.MonitorPhaseFinished
+	}
+	if !errors.Is(err, repository.ErrorRecordNotFound) {
+		log.Printf("[LessonMonitor] 監視状態取得エラー: Lesson=%s, %v", m.lesson.ID, err)
+	}
+
+	// 状態が残っていない場合は滞在ログから二重作成だけは防ぐ
+	stays, err := m.scheduler.stayService.GetByLessonAndDate(ctx, m.lesson.ID, m.lessonDate)
+	if err != nil {
+		log.Printf("[LessonMonitor] 滞在ログ取得エラー: Lesson=%s, %v", m.lesson.ID, err)
+		return false
+	}
+	for _, stay := range stays {
+		if stay.Source != "auto" {
+			continue
+		}
+		m.recordedUsers[stay.UserID] = true
+		m.stayIDs[stay.UserID] = stay.ID
+		m.lastSeen[stay.UserID] = stay.CreatedAt
+		if !stay.IsActive {
+			m.leftUsers[stay.UserID] = true
+		}
+	}
+	if len(m.recordedUsers) > 0 {
+		log.Printf("[LessonMonitor] 滞在ログから記録済みユーザーを復元: Lesson=%s, 記録済み=%d", m.lesson.ID, len(m.recordedUsers))
+		m.dirty = true
+	}
+	return false
+}
+
+// saveState 現在の監視状態を保存
+func (m *LessonMonitor) saveState(phase string) {
+	if m.state == nil {
+		m.state = &model.LessonMonitorState{
+			LessonID:   m.lesson.ID,
+			LessonDate: m.lessonDate,
+		}
+	}
+
+	users := make(map[string]model.MonitorUserState, len(m.recordedUsers))
+	for userID := range m.recordedUsers {
+		users[userID] = model.MonitorUserState{
+			StayID:       m.stayIDs[userID],
+			LastSeen:     m.lastSeen[userID],
+			Left:         m.leftUsers[userID],
+			PresentPolls: m.presentPolls[userID],
+		}
+	}
+	m.state.Phase = phase
+	m.state.ObservedPolls = m.observedPolls
+	m.state.Users = users
+
+	if err := m.scheduler.monitorStateService.Save(context.Background(), m.state); err != nil {
+		log.Printf("[LessonMonitor] 監視状態保存エラー: Lesson=%s, %v", m.lesson.ID, err)
+		return
+	}
+	m.dirty = false
 }
 
 // cleanup クリーンアップ
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+// TestMonitorFinishesAfterLessonEnds 時刻を授業終了後まで進め、スケジューラーが監視を止める前に終了処理（出席判定）が行われることを確認
+func TestMonitorFinishesAfterLessonEnds(t *testing.T) {
+	date := time.Date(2026, 4, 13, 0, 0, 0, 0, time.Local)
+	occurrence := model.LessonOccurrence{
+		LessonID: "lesson-1",
+		Date:     date,
+		StartAt:  date.Add(9 * time.Hour),
+		EndAt:    date.Add(10*time.Hour + 30*time.Minute),
+	}
+	lesson := occurrence.LessonOnDate()
+	monitorStart, monitorEnd := monitorPeriod(lesson)
+
+	// 監視ワーカーとスケジューラーはどちらも1分ごとに確認する
+	const interval = time.Minute
+	lessonPolls := int(occurrence.EndAt.Sub(occurrence.StartAt) / interval)
+
+	started, finished := false, false
+	for now := occurrence.StartAt.Add(-30 * time.Minute); now.Before(occurrence.EndAt.Add(time.Hour)); now = now.Add(interval) {
+		// LessonOccurrenceRepository.FindOverlappingと同じ条件
+		from, to := model.MonitoringRange(now)
+		listed := !occurrence.StartAt.After(to) && !occurrence.EndAt.Before(from)
+
+		if !started {
+			if listed {
+				started = true
+				if now.After(monitorStart.Add(interval)) {
+					t.Fatalf("監視開始が遅い: %s (監視期間の開始 %s)", now.Format("15:04"), monitorStart.Format("15:04"))
+				}
+			}
+			continue
+		}
+
+		// 監視ワーカーのティック：監視期間を過ぎたら終了処理
+		if now.After(monitorEnd) {
+			finished = true
+			if now.Before(occurrence.EndAt) {
+				t.Fatalf("授業終了前に終了処理が行われた: %s", now.Format("15:04"))
+			}
+			break
+		}
+
+		// スケジューラーのティック：監視対象から外れた場合は終了処理の前に停止される
+		if !listed {
+			t.Fatalf("終了処理の前に監視対象から外れた: %s (授業終了 %s, 監視期間の終了 %s)",
+				now.Format("15:04"), occurrence.EndAt.Format("15:04"), monitorEnd.Format("15:04"))
+		}
+	}
+	if !finished {
+		t.Fatalf("授業終了後も終了処理が行われなかった")
+	}
+
+	// 終了処理では在室割合から出席を記録する
+	tests := []struct {
+		name         string
+		presentPolls int
+		wantDwell    int
+		wantAttended bool
+	}{
+		{name: "授業中ずっと在室", presentPolls: lessonPolls, wantDwell: 90, wantAttended: true},
+		{name: "半分だけ在室", presentPolls: lessonPolls / 2, wantDwell: 45, wantAttended: true},
+		{name: "通りがかっただけ", presentPolls: 1, wantDwell: 1, wantAttended: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dwell, attended := decideAttendance(tt.presentPolls, lessonPolls, interval, 0.5)
+			if attended == nil {
+				t.Fatalf("decideAttendance() attended = nil, want %v", tt.wantAttended)
+			}
+			if dwell != tt.wantDwell || *attended != tt.wantAttended {
+				t.Errorf("decideAttendance() = %d, %v, want %d, %v", dwell, *attended, tt.wantDwell, tt.wantAttended)
+			}
+		})
+	}
+}
+
+func TestDecideAttendanceWithoutSnapshots(t *testing.T) {
+	dwell, attended := decideAttendance(0, 0, time.Minute, 0.5)
+	if dwell != 0 || attended != nil {
+		t.Errorf("decideAttendance(0, 0) = %d, %v, want 0, nil", dwell, attended)
+	}
+}
@@ -2,41 +2,70 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/internal/usecase"
 	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
 )
 
 // LessonScheduler 授業スケジューラー
 type LessonScheduler struct {
-	lessonService *service.LessonService
-	roomService   *service.RoomService
-	deviceService *service.DeviceService
-	stayService   *service.StayService
-	mistClient    *mistapi.Client
+	occurrenceService *service.LessonOccurrenceService
+	roomService       *service.RoomService
+	deviceService     *service.DeviceService
+	stayService       *service.StayService
+	enrollmentService *service.EnrollmentService
+	mistClient        *mistapi.Client
+	poller            *SitePoller
 
-	activeMonitors sync.Map // map[lessonID]*LessonMonitor
-	stopChan       chan struct{}
+	monitorStateService *service.LessonMonitorStateService
+
+	activeMonitors  sync.Map // map[lessonID]*LessonMonitor
+	finishedLessons sync.Map // map[lessonID/日付]struct{} 本日の終了処理が完了した授業
+	stopChan        chan struct{}
+}
+
+// zoneSighting ゾーン内でのデバイス検知
+type zoneSighting struct {
+	zoneID   string
+	deviceID string
+	seenAt   time.Time // 検知時刻（Webhookはイベントの発生時刻）
+}
+
+// sightingTime イベントの発生時刻を検知時刻として使う（未来の時刻は受信時刻に丸める）
+func sightingTime(eventAt, receivedAt time.Time) time.Time {
+	if eventAt.IsZero() || eventAt.After(receivedAt) {
+		return receivedAt
+	}
+	return eventAt
 }
 
 // NewLessonScheduler 授業スケジューラーを作成
 func NewLessonScheduler(
-	lessonService *service.LessonService,
+	occurrenceService *service.LessonOccurrenceService,
 	roomService *service.RoomService,
 	deviceService *service.DeviceService,
 	stayService *service.StayService,
+	enrollmentService *service.EnrollmentService,
+	monitorStateService *service.LessonMonitorStateService,
 	mistClient *mistapi.Client,
 ) *LessonScheduler {
 	return &LessonScheduler{
-		lessonService: lessonService,
-		roomService:   roomService,
-		deviceService: deviceService,
-		stayService:   stayService,
-		mistClient:    mistClient,
-		stopChan:      make(chan struct{}),
+		occurrenceService:   occurrenceService,
+		roomService:         roomService,
+		deviceService:       deviceService,
+		stayService:         stayService,
+		enrollmentService:   enrollmentService,
+		monitorStateService: monitorStateService,
+		mistClient:          mistClient,
+		poller:              NewSitePoller(mistClient, 60*time.Second),
+		stopChan:            make(chan struct{}),
 	}
 }
 
@@ -44,6 +73,13 @@ func NewLessonScheduler(
 func (s *LessonScheduler) Start() {
 	log.Println("[LessonScheduler] 開始")
 
+	// サイト全体のポーリングを開始（監視中の授業がある間のみAPIを呼び出す）
+	go s.poller.Start()
+
+	// 再起動時は次のティックを待たずに監視を再開する
+	s.reconcileOrphanedStays()
+	s.checkAndStartMonitors()
+
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
@@ -54,13 +90,21 @@ func (s *LessonScheduler) Start() {
 			return
 		case <-ticker.C:
 			s.checkAndStartMonitors()
+			s.reconcileOrphanedStays()
 		}
 	}
 }
 
 // Stop スケジューラーを停止
+// 監視中の授業も停止する（監視状態は保存済みのため再開時に引き継がれる）
 func (s *LessonScheduler) Stop() {
 	close(s.stopChan)
+	s.activeMonitors.Range(func(key, value interface{}) bool {
+		value.(*LessonMonitor).Stop()
+		s.activeMonitors.Delete(key)
+		return true
+	})
+	s.poller.Stop()
 }
 
 // checkAndStartMonitors 監視対象の授業をチェックして監視を開始
@@ -68,19 +112,19 @@ func (s *LessonScheduler) checkAndStartMonitors() {
 	ctx := context.Background()
 	now := time.Now()
 
-	// 現在時刻で監視対象の授業を取得
-	lessons, err := s.lessonService.GetMonitoringLessons(ctx, now)
+	// 現在時刻で監視対象の授業（実施回）を取得
+	occurrences, err := s.occurrenceService.GetMonitoring(ctx, now)
 	if err != nil {
 		log.Printf("[LessonScheduler] 監視対象授業取得エラー: %v", err)
 		return
 	}
 
-	log.Printf("[LessonScheduler] 監視対象授業数: %d", len(lessons))
+	log.Printf("[LessonScheduler] 監視対象授業数: %d", len(occurrences))
 
 	// 監視対象の授業IDのセットを作成
 	monitoringLessonIDs := make(map[string]bool)
-	for _, lesson := range lessons {
-		monitoringLessonIDs[lesson.ID] = true
+	for _, occurrence := range occurrences {
+		monitoringLessonIDs[occurrence.LessonID] = true
 	}
 
 	// 既存の監視プロセスをチェック
@@ -97,25 +141,188 @@ func (s *LessonScheduler) checkAndStartMonitors() {
 		return true
 	})
 
-	for _, lesson := range lessons {
-		// すでに監視中かチェック
-		if _, exists := s.activeMonitors.Load(lesson.ID); exists {
+	for _, occurrence := range occurrences {
+		// すでに監視中かチェック（教室変更・時間変更があれば監視中のワーカーに反映）
+		if value, exists := s.activeMonitors.Load(occurrence.LessonID); exists {
+			monitor := value.(*LessonMonitor)
+			if key := scheduleKey(occurrence); key != monitor.scheduleKey {
+				log.Printf("[LessonScheduler] 授業変更を検知: Lesson=%s, Room=%s, Time=%s-%s",
+					occurrence.LessonID, occurrence.RoomID, occurrence.StartAt.Format("15:04"), occurrence.EndAt.Format("15:04"))
+				monitor.scheduleKey = key
+				monitor.reschedule(occurrence)
+			}
+			continue
+		}
+
+		// 実施回の終了処理が完了済みならスキップ
+		if _, finished := s.finishedLessons.Load(finishedKey(occurrence.LessonID, occurrence.Date)); finished {
 			continue
 		}
 
 		// 新しい授業の監視を開始
-		log.Printf("[LessonScheduler] 監視開始: Lesson=%s, Subject=%s, Room=%s, Time=%s-%s",
-			lesson.ID, lesson.SubjectID, lesson.RoomID,
-			lesson.StartTime.Format("15:04"), lesson.EndTime.Format("15:04"))
+		log.Printf("[LessonScheduler] 監視開始: Lesson=%s, Occurrence=%s, Subject=%s, Room=%s, Time=%s %s-%s",
+			occurrence.LessonID, occurrence.ID, occurrence.SubjectID, occurrence.RoomID,
+			occurrence.Date.Format("2006-01-02"), occurrence.StartAt.Format("15:04"), occurrence.EndAt.Format("15:04"))
 
-		monitor := NewLessonMonitor(lesson, s)
-		s.activeMonitors.Store(lesson.ID, monitor)
+		monitor := NewLessonMonitor(occurrence, s)
+		s.activeMonitors.Store(occurrence.LessonID, monitor)
 
 		go monitor.Start()
 	}
 }
 
+// reconcileOrphanedStays 監視されないまま授業が終了した自動記録の滞在ログを終了
+// 再起動などで終了処理が実行されなかった場合に、保存済みの監視状態から出席を判定し、
+// 最終検知時刻（不明な場合は授業終了時刻）で退室させる
+func (s *LessonScheduler) reconcileOrphanedStays() {
+	ctx := context.Background()
+	now := time.Now()
+	config := usecase.DefaultAttendanceConfig()
+
+	stays, err := s.stayService.GetActiveAutoWithLesson(ctx)
+	if err != nil {
+		log.Printf("[LessonScheduler] 未終了の滞在ログ取得エラー: %v", err)
+		return
+	}
+
+	// 監視状態は授業・実施日ごとに1回だけ取得する（保存されていない場合はnil）
+	states := make(map[string]*model.LessonMonitorState)
+
+	closed := 0
+	for i := range stays {
+		stay := &stays[i]
+		if stay.Lesson == nil {
+			continue
+		}
+		// 監視中の授業は監視ワーカーに任せる
+		if _, monitoring := s.activeMonitors.Load(stay.Lesson.ID); monitoring {
+			continue
+		}
+
+		// 実施回の授業終了時刻（監視期間が終わるまでは待つ）
+		lessonDate := model.LessonDateOf(stay.CreatedAt)
+		if stay.LessonDate != nil {
+			lessonDate = *stay.LessonDate
+		}
+		_, lessonEnd := stay.Lesson.TimesOn(lessonDate)
+		if stay.Occurrence != nil {
+			lessonEnd = stay.Occurrence.EndAt
+		}
+		if now.Before(lessonEnd.Add(model.MonitorTrailTime)) {
+			continue
+		}
+
+		key := finishedKey(stay.Lesson.ID, lessonDate)
+		state, loaded := states[key]
+		if !loaded {
+			state, err = s.monitorStateService.Get(ctx, stay.Lesson.ID, lessonDate)
+			if err != nil && !errors.Is(err, repository.ErrorRecordNotFound) {
+				log.Printf("[LessonScheduler] 監視状態取得エラー: Lesson=%s, %v", stay.Lesson.ID, err)
+			}
+			states[key] = state
+		}
+
+		leavedAt := lessonEnd
+		if state != nil {
+			u := state.Users[stay.UserID]
+			if !u.LastSeen.IsZero() && u.LastSeen.Before(leavedAt) {
+				leavedAt = u.LastSeen
+			}
+
+			// 終了処理と同じく授業時間内の在室割合から出席を判定する
+			if stay.Attended == nil {
+				dwellMinutes, attended := decideAttendance(u.PresentPolls, state.ObservedPolls, s.poller.interval, config.MinPresenceRatio)
+				if err := s.stayService.RecordAttendance(ctx, stay, dwellMinutes, attended); err != nil {
+					log.Printf("[LessonScheduler] 出席判定の記録エラー: Stay=%d, %v", stay.ID, err)
+				} else if attended != nil {
+					log.Printf("[LessonScheduler] 未終了の滞在ログの出席判定: Stay=%d, User=%s, 滞在=%d分, 出席=%v",
+						stay.ID, stay.UserID, dwellMinutes, *attended)
+				}
+			}
+		}
+		if leavedAt.Before(stay.CreatedAt) {
+			leavedAt = stay.CreatedAt
+		}
+
+		if err := s.stayService.CloseStay(ctx, stay.ID, leavedAt); err != nil {
+			log.Printf("[LessonScheduler] 未終了の滞在ログの退出処理エラー: Stay=%d, %v", stay.ID, err)
+			continue
+		}
+		closed++
+		log.Printf("[LessonScheduler] 未終了の滞在ログを終了: Stay=%d, User=%s, Lesson=%s, 退出=%s",
+			stay.ID, stay.UserID, stay.Lesson.ID, leavedAt.Format("2006-01-02 15:04"))
+	}
+
+	if closed > 0 {
+		log.Printf("[LessonScheduler] 未終了の滞在ログを%d件終了しました", closed)
+	}
+}
+
+// markFinished 本日の終了処理が完了した授業として記録
+func (s *LessonScheduler) markFinished(lessonID string, lessonDate time.Time) {
+	s.finishedLessons.Store(finishedKey(lessonID, lessonDate), struct{}{})
+}
+
+// finishedKey 終了処理済みの授業のキー
+func finishedKey(lessonID string, date time.Time) string {
+	return lessonID + "/" + date.Format("2006-01-02")
+}
+
 // removeMonitor 監視を削除
 func (s *LessonScheduler) removeMonitor(lessonID string) {
 	s.activeMonitors.Delete(lessonID)
 }
+
+// HandleZoneEvent Webhookのゾーンイベントを監視中の授業に振り分け
+// 退室イベントはポーリングと授業終了処理で扱うため、ここでは入室のみ処理する
+func (s *LessonScheduler) HandleZoneEvent(event mistapi.ZoneEvent) {
+	if !event.IsEnter() {
+		return
+	}
+	s.dispatchSighting(zoneSighting{zoneID: event.ZoneID, deviceID: event.DeviceID(), seenAt: sightingTime(event.Time(), time.Now())})
+}
+
+// HandleLocationEvent Webhookの位置情報イベントを座標を含むゾーンの授業に振り分け
+func (s *LessonScheduler) HandleLocationEvent(event mistapi.LocationEvent) {
+	seenAt := sightingTime(event.Time(), time.Now())
+	for _, zoneID := range s.poller.ZonesAt(event.MapID, event.X, event.Y) {
+		s.dispatchSighting(zoneSighting{zoneID: zoneID, deviceID: event.DeviceID(), seenAt: seenAt})
+	}
+}
+
+// HandleClientJoinEvent WiFi接続イベントを受けて次のティックを待たずにポーリングを要求
+// 接続イベントには所在ゾーンが含まれないため、ゾーン統計から判定する
+func (s *LessonScheduler) HandleClientJoinEvent(event mistapi.ClientJoinEvent) {
+	s.poller.RequestRefresh()
+}
+
+// EnableStream WebSocketストリームで位置情報を受信するようにする（Startの前に呼び出すこと）
+func (s *LessonScheduler) EnableStream() error {
+	if s.mistClient == nil {
+		return errors.New("mist APIクライアントが無効です")
+	}
+	stream, err := s.mistClient.NewStreamClient()
+	if err != nil {
+		return err
+	}
+	s.poller.UseStream(stream, s.handleStreamUpdate)
+	return nil
+}
+
+// handleStreamUpdate ストリームの位置情報を座標を含むゾーンの授業に振り分け
+func (s *LessonScheduler) handleStreamUpdate(update mistapi.LocationUpdate) {
+	for _, zoneID := range s.poller.ZonesAt(update.MapID, update.X, update.Y) {
+		s.dispatchSighting(zoneSighting{zoneID: zoneID, deviceID: update.DeviceID(), seenAt: sightingTime(update.ReceivedAt, time.Now())})
+	}
+}
+
+// dispatchSighting デバイス検知を監視中の全授業に通知（ゾーンの判定は各監視ワーカーで行う）
+func (s *LessonScheduler) dispatchSighting(sighting zoneSighting) {
+	if sighting.zoneID == "" || sighting.deviceID == "" {
+		return
+	}
+	s.activeMonitors.Range(func(key, value interface{}) bool {
+		value.(*LessonMonitor).notify(sighting)
+		return true
+	})
+}
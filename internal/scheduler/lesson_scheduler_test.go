@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+)
+
+func TestSightingTime(t *testing.T) {
+	receivedAt := time.Date(2026, 4, 13, 9, 20, 0, 0, time.Local)
+	enteredAt := receivedAt.Add(-15 * time.Minute)
+
+	tests := []struct {
+		name  string
+		event mistapi.ZoneEvent
+		want  time.Time
+	}{
+		{
+			name:  "遅れて届いたイベントは発生時刻",
+			event: mistapi.ZoneEvent{Trigger: "enter", Timestamp: float64(enteredAt.Unix())},
+			want:  enteredAt,
+		},
+		{
+			name:  "発生時刻が未来の場合は受信時刻",
+			event: mistapi.ZoneEvent{Trigger: "enter", Timestamp: float64(receivedAt.Add(time.Minute).Unix())},
+			want:  receivedAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sightingTime(tt.event.Time(), receivedAt); !got.Equal(tt.want) {
+				t.Errorf("sightingTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 発生時刻がない場合は受信時刻
+	if got := sightingTime(time.Time{}, receivedAt); !got.Equal(receivedAt) {
+		t.Errorf("sightingTime(zero) = %v, want %v", got, receivedAt)
+	}
+}
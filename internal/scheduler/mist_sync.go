@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+// MistSyncScheduler Mistのマップ・ゾーン定義の定期同期スケジューラー
+type MistSyncScheduler struct {
+	mistSyncService *service.MistSyncService
+	interval        time.Duration
+	stopChan        chan struct{}
+}
+
+// NewMistSyncScheduler Mist同期スケジューラーを作成
+func NewMistSyncScheduler(mistSyncService *service.MistSyncService, interval time.Duration) *MistSyncScheduler {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	return &MistSyncScheduler{
+		mistSyncService: mistSyncService,
+		interval:        interval,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start 同期を開始（起動直後に1回実行し、以降は一定間隔で実行）
+func (s *MistSyncScheduler) Start() {
+	log.Printf("[MistSyncScheduler] 開始 (間隔: %s)", s.interval)
+
+	s.runSync()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			log.Println("[MistSyncScheduler] 停止")
+			return
+		case <-ticker.C:
+			s.runSync()
+		}
+	}
+}
+
+// Stop 同期を停止
+func (s *MistSyncScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// runSync 同期を実行
+func (s *MistSyncScheduler) runSync() {
+	ctx := context.Background()
+
+	result, err := s.mistSyncService.Sync(ctx)
+	if err != nil {
+		logMistError("[MistSyncScheduler]", "同期エラー", err)
+		return
+	}
+
+	log.Printf("[MistSyncScheduler] 同期完了: マップ=%d, ゾーン=%d (スキップ=%d), 無効化 (マップ=%d, ゾーン=%d)",
+		result.Maps, result.Zones, result.SkippedZones, result.DeactivatedMaps, result.DeactivatedZones)
+}
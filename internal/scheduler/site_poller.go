@@ -0,0 +1,394 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+)
+
+// ZoneSnapshot 1回のポーリングで取得したサイト全体のゾーン状況
+type ZoneSnapshot struct {
+	TakenAt     time.Time
+	Zones       map[string]mistapi.MistZone // zoneID → ゾーン定義
+	ZoneDevices map[string][]string         // zoneID → ゾーン内のデバイスID（WiFi/SDK/BLE）
+	DeviceZones map[string][]string         // デバイスID → 所在ゾーンID
+}
+
+// DevicesInZone ゾーン内のデバイスID一覧を取得
+func (s *ZoneSnapshot) DevicesInZone(zoneID string) []string {
+	return s.ZoneDevices[zoneID]
+}
+
+// ZonesOfDevice デバイスが所在するゾーンID一覧を取得
+func (s *ZoneSnapshot) ZonesOfDevice(deviceID string) []string {
+	return s.DeviceZones[deviceID]
+}
+
+// addDevice デバイスをゾーンに追加（重複は無視）
+func (s *ZoneSnapshot) addDevice(zoneID, deviceID string) {
+	for _, id := range s.ZoneDevices[zoneID] {
+		if id == deviceID {
+			return
+		}
+	}
+	s.ZoneDevices[zoneID] = append(s.ZoneDevices[zoneID], deviceID)
+	s.DeviceZones[deviceID] = append(s.DeviceZones[deviceID], zoneID)
+}
+
+// SitePoller サイト全体を1ティックに1回だけポーリングし、結果を各LessonMonitorに配信する
+//
+// 購読者（監視中の授業）がいない間はMist APIを呼び出さない。
+type SitePoller struct {
+	mistClient *mistapi.Client
+	interval   time.Duration
+
+	mu          sync.RWMutex
+	subscribers map[string]chan *ZoneSnapshot // 購読ID → 配信チャンネル
+	latest      *ZoneSnapshot
+	zones       []mistapi.MistZone // 前回取得したゾーン定義（取得失敗時に再利用）
+
+	// WebSocketストリーム（nilの場合はBLEアセットもポーリングで取得）
+	stream         *mistapi.StreamClient
+	onStreamUpdate func(mistapi.LocationUpdate)
+	positions      map[string]mistapi.LocationUpdate // デバイスID → ストリームで受信した最新位置
+	mapUpdatedAt   map[string]time.Time              // マップID → ストリームで最後に位置を受信した時刻
+
+	refreshChan chan struct{}
+	stopChan    chan struct{}
+}
+
+// NewSitePoller サイトポーラーを作成
+func NewSitePoller(mistClient *mistapi.Client, interval time.Duration) *SitePoller {
+	return &SitePoller{
+		mistClient:   mistClient,
+		interval:     interval,
+		subscribers:  make(map[string]chan *ZoneSnapshot),
+		positions:    make(map[string]mistapi.LocationUpdate),
+		mapUpdatedAt: make(map[string]time.Time),
+		refreshChan:  make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start ポーリングを開始
+func (p *SitePoller) Start() {
+	log.Printf("[SitePoller] 開始 (間隔: %s)", p.interval)
+
+	// 出席監視用のため高優先度でAPIを呼び出す
+	ctx, cancel := context.WithCancel(mistapi.WithPriority(context.Background(), mistapi.PriorityHigh))
+	defer cancel()
+	go func() {
+		<-p.stopChan
+		cancel()
+	}()
+
+	if p.stream != nil {
+		go func() {
+			if err := p.stream.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("[SitePoller] ストリーム終了: %v", err)
+			}
+		}()
+		go p.consumeStream()
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			log.Println("[SitePoller] 停止")
+			return
+		case <-ticker.C:
+			p.pollIfSubscribed(ctx)
+		case <-p.refreshChan:
+			p.pollIfSubscribed(ctx)
+		}
+	}
+}
+
+// UseStream WebSocketストリームで位置情報を受信する（Startの前に呼び出すこと）
+// ストリームで位置を受信できているマップはBLEアセットのポーリングを省略し、
+// 受信した位置情報はonUpdateにも渡される
+func (p *SitePoller) UseStream(stream *mistapi.StreamClient, onUpdate func(mistapi.LocationUpdate)) {
+	p.stream = stream
+	p.onStreamUpdate = onUpdate
+}
+
+// consumeStream ストリームの位置情報を保持して通知
+func (p *SitePoller) consumeStream() {
+	for update := range p.stream.Updates() {
+		deviceID := update.DeviceID()
+		if deviceID == "" {
+			continue
+		}
+
+		p.mu.Lock()
+		p.positions[deviceID] = update
+		if update.MapID != "" {
+			p.mapUpdatedAt[update.MapID] = update.ReceivedAt
+		}
+		p.mu.Unlock()
+
+		if p.onStreamUpdate != nil {
+			p.onStreamUpdate(update)
+		}
+	}
+}
+
+// streamAssets ストリームで受信したマップ上のBLEアセットの位置（一定時間内に受信したもののみ）
+// ストリームが未接続・未購読、または購読直後や切断後で直近にそのマップの位置を受信していない場合はokがfalseになる
+func (p *SitePoller) streamAssets(mapID string) (assets []mistapi.LocationUpdate, ok bool) {
+	if p.stream == nil || !p.stream.Connected() || !p.stream.Subscribed(mapID) {
+		return nil, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 2ティック以上更新のない位置は退出したものとみなす
+	threshold := time.Now().Add(-2 * p.interval)
+
+	// マップの位置を直近に受信していなければ、ストリームが届いていない可能性があるためポーリングに任せる
+	if updatedAt, received := p.mapUpdatedAt[mapID]; !received || updatedAt.Before(threshold) {
+		return nil, false
+	}
+
+	for deviceID, update := range p.positions {
+		if update.ReceivedAt.Before(threshold) {
+			delete(p.positions, deviceID)
+			continue
+		}
+		if update.Kind == mistapi.StreamKindAsset && update.MapID == mapID {
+			assets = append(assets, update)
+		}
+	}
+	return assets, true
+}
+
+// Stop ポーリングを停止
+func (p *SitePoller) Stop() {
+	close(p.stopChan)
+}
+
+// Subscribe スナップショットの配信を購読
+// 最新のスナップショットが十分新しければ即座に配信し、古ければ再取得を要求する
+func (p *SitePoller) Subscribe(id string) <-chan *ZoneSnapshot {
+	ch := make(chan *ZoneSnapshot, 1)
+
+	p.mu.Lock()
+	p.subscribers[id] = ch
+	fresh := p.latest != nil && time.Since(p.latest.TakenAt) < p.interval
+	if fresh {
+		// 作成直後の空のバッファなのでブロックしない
+		ch <- p.latest
+	}
+	p.mu.Unlock()
+
+	if !fresh {
+		p.RequestRefresh()
+	}
+	return ch
+}
+
+// Unsubscribe 購読を解除
+func (p *SitePoller) Unsubscribe(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.subscribers, id)
+}
+
+// Latest 最新のスナップショットを取得（未取得の場合はnil）
+func (p *SitePoller) Latest() *ZoneSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.latest
+}
+
+// ZonesAt 前回取得したゾーン定義から、マップ上の座標を含むゾーンID一覧を取得
+func (p *SitePoller) ZonesAt(mapID string, x, y float64) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var zoneIDs []string
+	for _, zone := range p.zones {
+		if zone.MapID == mapID && zone.Contains(x, y) {
+			zoneIDs = append(zoneIDs, zone.ID)
+		}
+	}
+	return zoneIDs
+}
+
+// RequestRefresh 次のティックを待たずにポーリングを要求
+func (p *SitePoller) RequestRefresh() {
+	select {
+	case p.refreshChan <- struct{}{}:
+	default:
+		// すでに要求済み
+	}
+}
+
+// pollIfSubscribed 購読者がいる場合のみポーリング
+func (p *SitePoller) pollIfSubscribed(ctx context.Context) {
+	p.mu.RLock()
+	count := len(p.subscribers)
+	p.mu.RUnlock()
+
+	if count == 0 {
+		return
+	}
+
+	if p.mistClient == nil {
+		log.Println("[SitePoller] Mist APIクライアントが無効なためポーリングをスキップします")
+		return
+	}
+
+	snapshot, err := p.poll(ctx)
+	if err != nil {
+		logMistError("[SitePoller]", "ポーリングエラー", err)
+		return
+	}
+
+	p.publish(snapshot)
+}
+
+// poll ゾーン定義・ゾーン統計・BLEアセットをそれぞれ1回ずつ取得してスナップショットを作成
+func (p *SitePoller) poll(ctx context.Context) (*ZoneSnapshot, error) {
+	siteID := p.mistClient.SiteID
+
+	// ゾーン定義（取得失敗時は前回の定義を再利用）
+	zones, err := p.mistClient.GetZones(ctx, siteID)
+	if err != nil {
+		p.mu.RLock()
+		cached := p.zones
+		p.mu.RUnlock()
+		if cached == nil {
+			return nil, err
+		}
+		logMistError("[SitePoller]", "ゾーン定義取得エラー（前回の定義を使用します）", err)
+		zones = cached
+	} else {
+		p.mu.Lock()
+		p.zones = zones
+		p.mu.Unlock()
+	}
+
+	snapshot := &ZoneSnapshot{
+		TakenAt:     time.Now(),
+		Zones:       make(map[string]mistapi.MistZone, len(zones)),
+		ZoneDevices: make(map[string][]string),
+		DeviceZones: make(map[string][]string),
+	}
+	mapIDs := make(map[string]bool)
+	for _, zone := range zones {
+		snapshot.Zones[zone.ID] = zone
+		if zone.MapID != "" {
+			mapIDs[zone.MapID] = true
+		}
+	}
+
+	// ゾーン統計（WiFi/SDKクライアント）
+	zoneStats, err := p.mistClient.GetZoneStats(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+	wifiCount := 0
+	for _, stat := range zoneStats {
+		for _, id := range stat.SDKClients {
+			snapshot.addDevice(stat.ID, id)
+			wifiCount++
+		}
+		for _, id := range stat.Clients {
+			snapshot.addDevice(stat.ID, id)
+			wifiCount++
+		}
+	}
+
+	// マップごとのBLEアセット（1マップの失敗で全体を捨てない）
+	bleCount := 0
+	for mapID := range mapIDs {
+		if p.stream != nil {
+			if err := p.stream.SubscribeMap(mapID); err != nil {
+				log.Printf("[SitePoller] ストリーム購読エラー (Map=%s): %v", mapID, err)
+			}
+		}
+
+		// ストリームで受信できている場合はAPIを呼び出さない
+		if assets, ok := p.streamAssets(mapID); ok {
+			for _, asset := range assets {
+				for _, zone := range zones {
+					if zone.MapID == mapID && zone.Contains(asset.X, asset.Y) {
+						snapshot.addDevice(zone.ID, asset.MAC)
+						bleCount++
+					}
+				}
+			}
+			continue
+		}
+
+		devices, err := p.mistClient.GetBLEDevices(ctx, siteID, mapID)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			logMistError("[SitePoller]", "BLEデバイス取得エラー (Map="+mapID+")", err)
+			continue
+		}
+		for _, device := range devices {
+			for _, zone := range zones {
+				if zone.MapID == mapID && zone.Contains(device.X, device.Y) {
+					snapshot.addDevice(zone.ID, device.Mac)
+					bleCount++
+				}
+			}
+		}
+	}
+
+	log.Printf("[SitePoller] スナップショット作成: ゾーン数=%d, マップ数=%d, 検知数 (WiFi/SDK: %d, BLE: %d)",
+		len(zones), len(mapIDs), wifiCount, bleCount)
+	return snapshot, nil
+}
+
+// publish スナップショットを全購読者に配信（受信が遅れている購読者には最新のみを残す）
+func (p *SitePoller) publish(snapshot *ZoneSnapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = snapshot
+	for _, ch := range p.subscribers {
+		select {
+		case ch <- snapshot:
+		default:
+			// 古いスナップショットを捨てて最新に置き換える
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- snapshot
+		}
+	}
+}
+
+// logMistError Mist APIエラーを種類ごとに分けてログ出力
+func logMistError(prefix, message string, err error) {
+	var rateErr *mistapi.RateLimitError
+	var authErr *mistapi.UnauthorizedError
+	var transientErr *mistapi.TransientError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// 停止によるキャンセルはエラー扱いしない
+	case errors.As(err, &rateErr):
+		log.Printf("%s %s（レート制限中、次回のチェックで再試行します Retry-After=%s）: %v", prefix, message, rateErr.RetryAfter, err)
+	case errors.As(err, &authErr):
+		log.Printf("%s %s（認証エラー、MIST_API_TOKENを確認してください）: %v", prefix, message, err)
+	case errors.As(err, &transientErr):
+		log.Printf("%s %s（一時的なエラー、次回のチェックで再試行します）: %v", prefix, message, err)
+	default:
+		log.Printf("%s %s: %v", prefix, message, err)
+	}
+}
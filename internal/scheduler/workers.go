@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+)
+
+// WorkerConfig バックグラウンド処理の設定
+type WorkerConfig struct {
+	StreamEnabled    bool          // Mist WebSocketストリームで位置情報を受信するか
+	MistSyncInterval time.Duration // Mistのマップ・ゾーン定義の同期間隔
+}
+
+// Workers リーダーのみが実行するスケジューラー群
+//
+// リーダーになるたびにスケジューラーを作り直して起動し、リーダーでなくなったら
+// 監視中の授業も含めてすべて停止する（監視状態は保存済みのため次のリーダーが引き継ぐ）。
+type Workers struct {
+	occurrenceService   *service.LessonOccurrenceService
+	roomService         *service.RoomService
+	deviceService       *service.DeviceService
+	stayService         *service.StayService
+	enrollmentService   *service.EnrollmentService
+	monitorStateService *service.LessonMonitorStateService
+	organizationService *service.OrganizationService
+	mistSyncService     *service.MistSyncService
+	mistClient          *mistapi.Client
+	config              WorkerConfig
+
+	mu              sync.RWMutex
+	lessonScheduler *LessonScheduler // リーダーでない間はnil
+}
+
+// NewWorkers スケジューラー群を作成
+func NewWorkers(
+	occurrenceService *service.LessonOccurrenceService,
+	roomService *service.RoomService,
+	deviceService *service.DeviceService,
+	stayService *service.StayService,
+	enrollmentService *service.EnrollmentService,
+	monitorStateService *service.LessonMonitorStateService,
+	organizationService *service.OrganizationService,
+	mistSyncService *service.MistSyncService,
+	mistClient *mistapi.Client,
+	config WorkerConfig,
+) *Workers {
+	return &Workers{
+		occurrenceService:   occurrenceService,
+		roomService:         roomService,
+		deviceService:       deviceService,
+		stayService:         stayService,
+		enrollmentService:   enrollmentService,
+		monitorStateService: monitorStateService,
+		organizationService: organizationService,
+		mistSyncService:     mistSyncService,
+		mistClient:          mistClient,
+		config:              config,
+	}
+}
+
+// Run ctxがキャンセルされるまでスケジューラー群を実行
+func (w *Workers) Run(ctx context.Context) {
+	// 授業スケジューラー
+	lessonScheduler := NewLessonScheduler(
+		w.occurrenceService,
+		w.roomService,
+		w.deviceService,
+		w.stayService,
+		w.enrollmentService,
+		w.monitorStateService,
+		w.mistClient,
+	)
+	if w.config.StreamEnabled {
+		if err := lessonScheduler.EnableStream(); err != nil {
+			log.Printf("[Workers] Mistストリームを有効化できませんでした（ポーリングのみで監視します）: %v", err)
+		} else {
+			log.Println("[Workers] Mistストリームを有効化しました")
+		}
+	}
+	go lessonScheduler.Start()
+	log.Println("[Workers] 授業スケジューラーを起動しました")
+
+	w.mu.Lock()
+	w.lessonScheduler = lessonScheduler
+	w.mu.Unlock()
+
+	// 日次バッチスケジューラー
+	dailyBatchScheduler := NewDailyBatchScheduler(w.deviceService, w.organizationService)
+	go dailyBatchScheduler.Start()
+	log.Println("[Workers] 日次バッチスケジューラーを起動しました")
+
+	// Mistマップ・ゾーン同期スケジューラー
+	var mistSyncScheduler *MistSyncScheduler
+	if w.mistClient != nil {
+		mistSyncScheduler = NewMistSyncScheduler(w.mistSyncService, w.config.MistSyncInterval)
+		go mistSyncScheduler.Start()
+		log.Println("[Workers] Mist同期スケジューラーを起動しました")
+	}
+
+	<-ctx.Done()
+
+	w.mu.Lock()
+	w.lessonScheduler = nil
+	w.mu.Unlock()
+
+	log.Println("[Workers] 授業スケジューラーを停止しています...")
+	lessonScheduler.Stop()
+
+	log.Println("[Workers] 日次バッチスケジューラーを停止しています...")
+	dailyBatchScheduler.Stop()
+
+	if mistSyncScheduler != nil {
+		log.Println("[Workers] Mist同期スケジューラーを停止しています...")
+		mistSyncScheduler.Stop()
+	}
+}
+
+// HandleZoneEvent Mist webhookのゾーンイベントを授業スケジューラーに渡す
+// リーダーでない場合は破棄するため、呼び出し側で事前にIsLeaderを確認すること
+func (w *Workers) HandleZoneEvent(event mistapi.ZoneEvent) {
+	if s := w.current(); s != nil {
+		s.HandleZoneEvent(event)
+	}
+}
+
+// HandleLocationEvent Mist webhookの位置イベントを授業スケジューラーに渡す
+func (w *Workers) HandleLocationEvent(event mistapi.LocationEvent) {
+	if s := w.current(); s != nil {
+		s.HandleLocationEvent(event)
+	}
+}
+
+// HandleClientJoinEvent Mist webhookの接続イベントを授業スケジューラーに渡す
+func (w *Workers) HandleClientJoinEvent(event mistapi.ClientJoinEvent) {
+	if s := w.current(); s != nil {
+		s.HandleClientJoinEvent(event)
+	}
+}
+
+// IsLeader リーダーとしてスケジューラーを実行中かどうか
+func (w *Workers) IsLeader() bool {
+	return w.current() != nil
+}
+
+// current 実行中の授業スケジューラー
+func (w *Workers) current() *LessonScheduler {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lessonScheduler
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminAccountService 管理APIのアカウントサービス
+type AdminAccountService struct {
+	accountRepo *repository.AdminAccountRepository
+}
+
+// NewAdminAccountService 管理APIのアカウントサービスを作成
+func NewAdminAccountService(accountRepo *repository.AdminAccountRepository) *AdminAccountService {
+	return &AdminAccountService{
+		accountRepo: accountRepo,
+	}
+}
+
+// Create アカウントを作成（パスワードはbcryptでハッシュ化して保存）
+func (s *AdminAccountService) Create(ctx context.Context, mail, password, role string, orgID, userID *string) (*model.AdminAccount, error) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &model.AdminAccount{
+		ID:           uuid.NewString(),
+		Mail:         mail,
+		PasswordHash: string(passwordHash),
+		Role:         role,
+		OrgID:        orgID,
+		UserID:       userID,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.accountRepo.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetByID IDでアカウントを取得
+func (s *AdminAccountService) GetByID(ctx context.Context, id string) (*model.AdminAccount, error) {
+	return s.accountRepo.FindByID(ctx, id)
+}
+
+// GetByMail メールアドレスでアカウントを取得
+func (s *AdminAccountService) GetByMail(ctx context.Context, mail string) (*model.AdminAccount, error) {
+	return s.accountRepo.FindByMail(ctx, mail)
+}
+
+// GetAll アカウント一覧を取得（orgIDは省略可）
+func (s *AdminAccountService) GetAll(ctx context.Context, orgID string) ([]model.AdminAccount, error) {
+	return s.accountRepo.FindAll(ctx, orgID)
+}
+
+// ExistsSuperAdmin スーパー管理者のアカウントがあるか
+func (s *AdminAccountService) ExistsSuperAdmin(ctx context.Context) (bool, error) {
+	return s.accountRepo.ExistsByRole(ctx, model.AdminRoleSuperAdmin)
+}
+
+// VerifyPassword パスワードがアカウントのハッシュと一致するか
+func (s *AdminAccountService) VerifyPassword(account *model.AdminAccount, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)) == nil
+}
+
+// UpdatePassword パスワードを更新
+func (s *AdminAccountService) UpdatePassword(ctx context.Context, account *model.AdminAccount, password string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.accountRepo.UpdatePasswordHash(ctx, account.ID, string(passwordHash)); err != nil {
+		return err
+	}
+	account.PasswordHash = string(passwordHash)
+	return nil
+}
+
+// RecordLogin 最終ログイン日時を記録
+func (s *AdminAccountService) RecordLogin(ctx context.Context, account *model.AdminAccount) error {
+	now := time.Now()
+	if err := s.accountRepo.UpdateLastLoginAt(ctx, account.ID, now); err != nil {
+		return err
+	}
+	account.LastLoginAt = &now
+	return nil
+}
+
+// Delete アカウントを削除
+func (s *AdminAccountService) Delete(ctx context.Context, id string) error {
+	return s.accountRepo.Delete(ctx, id)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// AppSessionService アプリのセッションサービス
+type AppSessionService struct {
+	sessionRepo *repository.AppSessionRepository
+}
+
+// NewAppSessionService アプリのセッションサービスを作成
+func NewAppSessionService(sessionRepo *repository.AppSessionRepository) *AppSessionService {
+	return &AppSessionService{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Create ユーザーのデバイスにセッションを作成
+func (s *AppSessionService) Create(ctx context.Context, userID, deviceID string, ttl time.Duration) (*model.AppSession, error) {
+	now := time.Now()
+	session := &model.AppSession{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		DeviceID:  deviceID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetByID IDでセッションを取得
+func (s *AppSessionService) GetByID(ctx context.Context, id string) (*model.AppSession, error) {
+	return s.sessionRepo.FindByID(ctx, id)
+}
+
+// Revoke セッションを失効させる（すでに失効していた場合はfalse）
+func (s *AppSessionService) Revoke(ctx context.Context, id string) (bool, error) {
+	return s.sessionRepo.Revoke(ctx, id, time.Now())
+}
+
+// RevokeByDevice デバイスのセッションをすべて失効させる
+func (s *AppSessionService) RevokeByDevice(ctx context.Context, deviceID string) error {
+	return s.sessionRepo.RevokeByDeviceID(ctx, deviceID, time.Now())
+}
+
+// RevokeByUser ユーザーのセッションをすべて失効させる（失効させた件数を返す）
+func (s *AppSessionService) RevokeByUser(ctx context.Context, userID string) (int64, error) {
+	return s.sessionRepo.RevokeByUserID(ctx, userID, time.Now())
+}
+
+// DeleteExpired 有効期限切れのセッションを削除
+func (s *AppSessionService) DeleteExpired(ctx context.Context) error {
+	return s.sessionRepo.DeleteExpired(ctx, time.Now())
+}
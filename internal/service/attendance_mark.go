@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// AttendanceMarkService 出席の手動記録サービス
+type AttendanceMarkService struct {
+	markRepo *repository.AttendanceMarkRepository
+}
+
+// NewAttendanceMarkService 出席の手動記録サービスを作成
+func NewAttendanceMarkService(markRepo *repository.AttendanceMarkRepository) *AttendanceMarkService {
+	return &AttendanceMarkService{
+		markRepo: markRepo,
+	}
+}
+
+// Mark 実施回のユーザーの出席を手動で記録（すでに記録がある場合は上書き）
+func (s *AttendanceMarkService) Mark(ctx context.Context, occurrence *model.LessonOccurrence, userID, status, reason, markedBy string) (*model.AttendanceMark, error) {
+	occurrenceID := occurrence.ID
+	mark := &model.AttendanceMark{
+		ID:           uuid.NewString(),
+		OrgID:        occurrence.OrgID,
+		UserID:       userID,
+		LessonID:     occurrence.LessonID,
+		LessonDate:   occurrence.Date,
+		OccurrenceID: &occurrenceID,
+		Status:       status,
+		Reason:       reason,
+		MarkedBy:     markedBy,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.markRepo.Upsert(ctx, mark); err != nil {
+		return nil, err
+	}
+	return mark, nil
+}
+
+// GetByUserLessonDate ユーザーID・授業ID・実施日で手動記録を取得
+func (s *AttendanceMarkService) GetByUserLessonDate(ctx context.Context, userID, lessonID string, lessonDate time.Time) (*model.AttendanceMark, error) {
+	return s.markRepo.FindByUserLessonDate(ctx, userID, lessonID, lessonDate)
+}
+
+// GetByLessonAndDate 授業IDと実施日で手動記録一覧を取得
+func (s *AttendanceMarkService) GetByLessonAndDate(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.AttendanceMark, error) {
+	return s.markRepo.FindByLessonAndDate(ctx, lessonID, lessonDate)
+}
+
+// Delete 手動記録を削除
+func (s *AttendanceMarkService) Delete(ctx context.Context, id string) error {
+	return s.markRepo.Delete(ctx, id)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CalendarService 学期・休日カレンダーサービス
+type CalendarService struct {
+	termRepo        *repository.TermRepository
+	calendarDayRepo *repository.CalendarDayRepository
+}
+
+// NewCalendarService 学期・休日カレンダーサービスを作成
+func NewCalendarService(termRepo *repository.TermRepository, calendarDayRepo *repository.CalendarDayRepository) *CalendarService {
+	return &CalendarService{
+		termRepo:        termRepo,
+		calendarDayRepo: calendarDayRepo,
+	}
+}
+
+// ResolveDay 組織の指定日に授業を行うか、どの曜日の時間割を使うかを判定
+// 休日は授業なし、学期が登録されている組織ではいずれの学期にも含まれない日も授業なしとする
+func (s *CalendarService) ResolveDay(ctx context.Context, orgID string, date time.Time) (*model.SchoolDay, error) {
+	date = model.LessonDateOf(date)
+	day := &model.SchoolDay{
+		Date:      date,
+		Open:      true,
+		DayOfWeek: int(date.Weekday()),
+	}
+
+	calendarDay, err := s.calendarDayRepo.FindByOrgAndDate(ctx, orgID, date)
+	if err != nil && !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+	if calendarDay != nil && calendarDay.Kind == model.CalendarDayHoliday {
+		day.Open = false
+		day.Reason = calendarDay.Name
+		if day.Reason == "" {
+			day.Reason = "休日"
+		}
+		return day, nil
+	}
+
+	term, err := s.termRepo.FindContaining(ctx, orgID, date)
+	if err != nil && !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+	if term == nil {
+		count, err := s.termRepo.CountByOrgID(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			day.Open = false
+			day.Reason = "学期外"
+			return day, nil
+		}
+	}
+	day.Term = term
+
+	if calendarDay != nil && calendarDay.Kind == model.CalendarDaySpecial && calendarDay.DayOfWeek != nil {
+		day.Special = true
+		day.DayOfWeek = *calendarDay.DayOfWeek
+		day.Reason = calendarDay.Name
+	}
+	return day, nil
+}
+
+// CreateTerm 学期を作成
+func (s *CalendarService) CreateTerm(ctx context.Context, orgID, name string, startDate, endDate time.Time) (*model.Term, error) {
+	term := &model.Term{
+		ID:        uuid.NewString(),
+		OrgID:     orgID,
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.termRepo.Create(ctx, term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// GetTermByID IDで学期を取得
+func (s *CalendarService) GetTermByID(ctx context.Context, id string) (*model.Term, error) {
+	return s.termRepo.FindByID(ctx, id)
+}
+
+// GetTermsByOrgID 組織IDで学期一覧を取得
+func (s *CalendarService) GetTermsByOrgID(ctx context.Context, orgID string) ([]model.Term, error) {
+	return s.termRepo.FindByOrgID(ctx, orgID)
+}
+
+// UpdateTerm 学期を更新
+func (s *CalendarService) UpdateTerm(ctx context.Context, term *model.Term, name string, startDate, endDate time.Time) error {
+	term.Name = name
+	term.StartDate = startDate
+	term.EndDate = endDate
+	term.UpdatedAt = time.Now()
+	return s.termRepo.Update(ctx, term)
+}
+
+// DeleteTerm 学期を削除
+func (s *CalendarService) DeleteTerm(ctx context.Context, id string) error {
+	return s.termRepo.Delete(ctx, id)
+}
+
+// CreateDay 休日・特別時間割を作成
+func (s *CalendarService) CreateDay(ctx context.Context, orgID string, date time.Time, kind, name string, dayOfWeek *int) (*model.CalendarDay, error) {
+	day := &model.CalendarDay{
+		ID:        uuid.NewString(),
+		OrgID:     orgID,
+		Date:      date,
+		Kind:      kind,
+		Name:      name,
+		DayOfWeek: dayOfWeek,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.calendarDayRepo.Create(ctx, day); err != nil {
+		return nil, err
+	}
+	return day, nil
+}
+
+// GetDayByID IDで休日・特別時間割を取得
+func (s *CalendarService) GetDayByID(ctx context.Context, id string) (*model.CalendarDay, error) {
+	return s.calendarDayRepo.FindByID(ctx, id)
+}
+
+// GetDayByOrgAndDate 組織IDと日付で休日・特別時間割を取得
+func (s *CalendarService) GetDayByOrgAndDate(ctx context.Context, orgID string, date time.Time) (*model.CalendarDay, error) {
+	return s.calendarDayRepo.FindByOrgAndDate(ctx, orgID, date)
+}
+
+// GetDaysByOrgID 組織IDで休日・特別時間割一覧を取得（from・toがゼロ値の場合は期間を限定しない）
+func (s *CalendarService) GetDaysByOrgID(ctx context.Context, orgID string, from, to time.Time) ([]model.CalendarDay, error) {
+	return s.calendarDayRepo.FindByOrgID(ctx, orgID, from, to)
+}
+
+// UpdateDay 休日・特別時間割を更新
+func (s *CalendarService) UpdateDay(ctx context.Context, day *model.CalendarDay, kind, name string, dayOfWeek *int) error {
+	day.Kind = kind
+	day.Name = name
+	day.DayOfWeek = dayOfWeek
+	day.UpdatedAt = time.Now()
+	return s.calendarDayRepo.Update(ctx, day)
+}
+
+// DeleteDay 休日・特別時間割を削除
+func (s *CalendarService) DeleteDay(ctx context.Context, id string) error {
+	return s.calendarDayRepo.Delete(ctx, id)
+}
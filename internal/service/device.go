@@ -73,8 +73,8 @@ func normalizeMACAddress(mac string) string {
 	return strings.ReplaceAll(mac, "-", ":")
 }
 
-// Create デバイスを作成
-func (d *DeviceService) Create(ctx context.Context, userID, deviceID string) (*model.Device, error) {
+// Create デバイスを作成（アクティベーションの署名を検証する公開鍵を登録する）
+func (d *DeviceService) Create(ctx context.Context, userID, deviceID, publicKey string) (*model.Device, error) {
 	now := time.Now()
 	device := &model.Device{
 		ID:                uuid.NewString(),
@@ -82,6 +82,7 @@ func (d *DeviceService) Create(ctx context.Context, userID, deviceID string) (*m
 		DeviceID:          normalizeMACAddress(deviceID), // MACアドレス形式を統一
 		IsActive:          false,
 		LastAuthenticated: now,
+		PublicKey:         publicKey,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
@@ -107,6 +108,11 @@ func (d *DeviceService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdatePublicKey デバイスの公開鍵を更新（デバイスは非アクティブになる）
+func (d *DeviceService) UpdatePublicKey(ctx context.Context, id, publicKey string) error {
+	return d.deviceRepo.UpdatePublicKey(ctx, id, publicKey)
+}
+
 // Activate デバイスをアクティブにする
 func (d *DeviceService) Activate(ctx context.Context, id string) error {
 	if err := d.deviceRepo.Activate(ctx, id); err != nil {
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// DeviceChallengeService デバイスアクティベーションのチャレンジサービス
+type DeviceChallengeService struct {
+	challengeRepo *repository.DeviceChallengeRepository
+}
+
+// NewDeviceChallengeService デバイスアクティベーションのチャレンジサービスを作成
+func NewDeviceChallengeService(challengeRepo *repository.DeviceChallengeRepository) *DeviceChallengeService {
+	return &DeviceChallengeService{
+		challengeRepo: challengeRepo,
+	}
+}
+
+// Issue デバイスのチャレンジを発行（ランダムな32バイトのNonce）
+func (s *DeviceChallengeService) Issue(ctx context.Context, deviceID string, ttl time.Duration) (*model.DeviceChallenge, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	challenge := &model.DeviceChallenge{
+		ID:        uuid.NewString(),
+		DeviceID:  deviceID,
+		Nonce:     base64.RawURLEncoding.EncodeToString(raw),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.challengeRepo.Create(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// GetByID IDでチャレンジを取得
+func (s *DeviceChallengeService) GetByID(ctx context.Context, id string) (*model.DeviceChallenge, error) {
+	return s.challengeRepo.FindByID(ctx, id)
+}
+
+// Consume チャレンジを使用済みにする（すでに使用済みの場合はfalse）
+func (s *DeviceChallengeService) Consume(ctx context.Context, challenge *model.DeviceChallenge) (bool, error) {
+	return s.challengeRepo.MarkUsed(ctx, challenge.ID, time.Now())
+}
+
+// DeleteExpired 有効期限切れのチャレンジを削除
+func (s *DeviceChallengeService) DeleteExpired(ctx context.Context) error {
+	return s.challengeRepo.DeleteExpired(ctx, time.Now())
+}
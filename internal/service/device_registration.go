@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// registrationCodeDigits 確認コードの桁数
+const registrationCodeDigits = 6
+
+// DeviceRegistrationService 確認コードの入力待ちのデバイス登録サービス
+type DeviceRegistrationService struct {
+	registrationRepo *repository.DeviceRegistrationRepository
+}
+
+// NewDeviceRegistrationService 確認コードの入力待ちのデバイス登録サービスを作成
+func NewDeviceRegistrationService(registrationRepo *repository.DeviceRegistrationRepository) *DeviceRegistrationService {
+	return &DeviceRegistrationService{
+		registrationRepo: registrationRepo,
+	}
+}
+
+// Issue デバイス登録を作成して確認コードを発行（戻り値のコードはこの時だけ取得できる）
+// ユーザーの入力待ちのデバイス登録は、最新の1件のみが有効になるよう削除する
+func (s *DeviceRegistrationService) Issue(ctx context.Context, userID, deviceID, publicKey string, ttl time.Duration) (string, *model.DeviceRegistration, error) {
+	code, err := newRegistrationCode()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.registrationRepo.DeletePendingByUserID(ctx, userID); err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	registration := &model.DeviceRegistration{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		DeviceID:  deviceID,
+		PublicKey: publicKey,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	registration.CodeHash = hashRegistrationCode(registration.ID, code)
+	if err := s.registrationRepo.Create(ctx, registration); err != nil {
+		return "", nil, err
+	}
+	return code, registration, nil
+}
+
+// GetByID IDでデバイス登録を取得
+func (s *DeviceRegistrationService) GetByID(ctx context.Context, id string) (*model.DeviceRegistration, error) {
+	return s.registrationRepo.FindByID(ctx, id)
+}
+
+// IssuedWithin ユーザーに直近interval以内に確認コードを発行したか
+func (s *DeviceRegistrationService) IssuedWithin(ctx context.Context, userID string, interval time.Duration) (bool, error) {
+	latest, err := s.registrationRepo.FindLatestByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return issuedWithin(latest, time.Now(), interval), nil
+}
+
+// RecordAttempt 確認コードの入力回数を1増やす（上限に達している場合はfalse）
+func (s *DeviceRegistrationService) RecordAttempt(ctx context.Context, registration *model.DeviceRegistration, maxAttempts int) (bool, error) {
+	ok, err := s.registrationRepo.IncrementAttempts(ctx, registration.ID, maxAttempts)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		registration.Attempts++
+	}
+	return ok, nil
+}
+
+// CodeMatches 確認コードが一致するか
+func (s *DeviceRegistrationService) CodeMatches(registration *model.DeviceRegistration, code string) bool {
+	expected := hashRegistrationCode(registration.ID, code)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(registration.CodeHash)) == 1
+}
+
+// Consume デバイス登録を使用済みにする（すでに使用済みの場合はfalse）
+func (s *DeviceRegistrationService) Consume(ctx context.Context, registration *model.DeviceRegistration) (bool, error) {
+	return s.registrationRepo.Consume(ctx, registration.ID, time.Now())
+}
+
+// DeleteExpired 有効期限切れのデバイス登録を削除
+func (s *DeviceRegistrationService) DeleteExpired(ctx context.Context) error {
+	return s.registrationRepo.DeleteExpired(ctx, time.Now())
+}
+
+// issuedWithin デバイス登録がnowの直近interval以内に作成されたか
+func issuedWithin(registration *model.DeviceRegistration, now time.Time, interval time.Duration) bool {
+	return now.Sub(registration.CreatedAt) < interval
+}
+
+// newRegistrationCode 確認コード（0埋めした10進数）を生成
+func newRegistrationCode() (string, error) {
+	limit := big.NewInt(1)
+	for range registrationCodeDigits {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", registrationCodeDigits, n), nil
+}
+
+// hashRegistrationCode 確認コードのSHA-256（16進数。デバイス登録のIDと組み合わせる）
+func hashRegistrationCode(registrationID, code string) string {
+	return hashToken(registrationID + ":" + code)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+)
+
+func TestNewRegistrationCode(t *testing.T) {
+	format := regexp.MustCompile(`^[0-9]{6}$`)
+	for range 100 {
+		code, err := newRegistrationCode()
+		if err != nil {
+			t.Fatalf("newRegistrationCode() error = %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("newRegistrationCode() = %q, want 6 digits", code)
+		}
+	}
+}
+
+func TestDeviceRegistrationServiceCodeMatches(t *testing.T) {
+	s := &DeviceRegistrationService{}
+	registration := &model.DeviceRegistration{ID: "registration-1"}
+	registration.CodeHash = hashRegistrationCode(registration.ID, "012345")
+
+	tests := []struct {
+		name         string
+		registration *model.DeviceRegistration
+		code         string
+		want         bool
+	}{
+		{name: "発行したコード", registration: registration, code: "012345", want: true},
+		{name: "異なるコード", registration: registration, code: "012346"},
+		{name: "先頭の0を省略したコード", registration: registration, code: "12345"},
+		{name: "空のコード", registration: registration, code: ""},
+		{
+			name:         "別のデバイス登録のコード",
+			registration: &model.DeviceRegistration{ID: "registration-2", CodeHash: registration.CodeHash},
+			code:         "012345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.CodeMatches(tt.registration, tt.code); got != tt.want {
+				t.Errorf("CodeMatches(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	if registration.CodeHash == "012345" {
+		t.Errorf("CodeHash に確認コードがそのまま保存されている")
+	}
+}
+
+func TestIssuedWithin(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		want      bool
+	}{
+		{name: "発行直後", createdAt: now, want: true},
+		{name: "間隔内", createdAt: now.Add(-59 * time.Second), want: true},
+		{name: "間隔ちょうど", createdAt: now.Add(-time.Minute)},
+		{name: "間隔を過ぎている", createdAt: now.Add(-61 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registration := &model.DeviceRegistration{CreatedAt: tt.createdAt}
+			if got := issuedWithin(registration, now, time.Minute); got != tt.want {
+				t.Errorf("issuedWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// EnrollmentService 履修登録サービス
+// 教科の履修登録とクラスの所属から授業の対象者を判定する
+type EnrollmentService struct {
+	enrollmentRepo  *repository.EnrollmentRepository
+	groupMemberRepo *repository.GroupMemberRepository
+}
+
+// NewEnrollmentService 履修登録サービスを作成
+func NewEnrollmentService(enrollmentRepo *repository.EnrollmentRepository, groupMemberRepo *repository.GroupMemberRepository) *EnrollmentService {
+	return &EnrollmentService{
+		enrollmentRepo:  enrollmentRepo,
+		groupMemberRepo: groupMemberRepo,
+	}
+}
+
+// Create 履修登録を作成
+func (s *EnrollmentService) Create(ctx context.Context, orgID, userID, subjectID string) (*model.Enrollment, error) {
+	enrollment := &model.Enrollment{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		SubjectID: subjectID,
+		OrgID:     orgID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.enrollmentRepo.Create(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// GetByID IDで履修登録を取得
+func (s *EnrollmentService) GetByID(ctx context.Context, id string) (*model.Enrollment, error) {
+	return s.enrollmentRepo.FindByID(ctx, id)
+}
+
+// GetByUserAndSubject ユーザーIDと教科IDで履修登録を取得
+func (s *EnrollmentService) GetByUserAndSubject(ctx context.Context, userID, subjectID string) (*model.Enrollment, error) {
+	return s.enrollmentRepo.FindByUserAndSubject(ctx, userID, subjectID)
+}
+
+// GetByOrgID 組織IDで履修登録一覧を取得（userID・subjectIDは省略可）
+func (s *EnrollmentService) GetByOrgID(ctx context.Context, orgID, userID, subjectID string) ([]model.Enrollment, error) {
+	return s.enrollmentRepo.FindByOrgID(ctx, orgID, userID, subjectID)
+}
+
+// GetRoster 授業（教科・対象のクラス）の対象者を取得
+func (s *EnrollmentService) GetRoster(ctx context.Context, subjectID string, groupID *string) (model.LessonRoster, error) {
+	var roster model.LessonRoster
+
+	userIDs, err := s.enrollmentRepo.FindUserIDsBySubjectID(ctx, subjectID)
+	if err != nil {
+		return roster, err
+	}
+	if len(userIDs) > 0 {
+		roster.Enrolled = toSet(userIDs)
+	}
+
+	if groupID != nil {
+		memberIDs, err := s.groupMemberRepo.FindUserIDsByGroupID(ctx, *groupID)
+		if err != nil {
+			return roster, err
+		}
+		roster.Members = toSet(memberIDs)
+	}
+	return roster, nil
+}
+
+// Delete 履修登録を削除
+func (s *EnrollmentService) Delete(ctx context.Context, id string) error {
+	return s.enrollmentRepo.Delete(ctx, id)
+}
+
+// GetAudience ユーザーが対象者になっている授業の判定を取得
+// 履修登録のない教科・クラスを割り当てていない授業はすべてのユーザーが対象
+func (s *EnrollmentService) GetAudience(ctx context.Context, orgID, userID string) (*model.LessonAudience, error) {
+	restrictedIDs, err := s.enrollmentRepo.FindEnrolledSubjectIDs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	enrolledIDs, err := s.enrollmentRepo.FindSubjectIDsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs, err := s.groupMemberRepo.FindGroupIDsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LessonAudience{
+		RestrictedSubjects: toSet(restrictedIDs),
+		EnrolledSubjects:   toSet(enrolledIDs),
+		Groups:             toSet(groupIDs),
+	}, nil
+}
+
+// toSet IDの一覧を集合に変換
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
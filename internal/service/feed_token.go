@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// FeedTokenService 時間割配信トークンサービス
+type FeedTokenService struct {
+	tokenRepo *repository.FeedTokenRepository
+}
+
+// NewFeedTokenService 時間割配信トークンサービスを作成
+func NewFeedTokenService(tokenRepo *repository.FeedTokenRepository) *FeedTokenService {
+	return &FeedTokenService{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Issue 配信対象のトークンを発行（以前のトークンは失効させる。戻り値のトークンはこの時だけ取得できる）
+func (s *FeedTokenService) Issue(ctx context.Context, orgID, kind, targetID string) (string, *model.FeedToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	if _, err := s.tokenRepo.RevokeByTarget(ctx, kind, targetID, now); err != nil {
+		return "", nil, err
+	}
+
+	feedToken := &model.FeedToken{
+		ID:        uuid.NewString(),
+		OrgID:     orgID,
+		Kind:      kind,
+		TargetID:  targetID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, feedToken); err != nil {
+		return "", nil, err
+	}
+	return token, feedToken, nil
+}
+
+// GetByToken トークンで配信トークンを取得
+func (s *FeedTokenService) GetByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	return s.tokenRepo.FindByHash(ctx, hashToken(token))
+}
+
+// Revoke 配信対象のトークンを失効させる（失効させた件数を返す）
+func (s *FeedTokenService) Revoke(ctx context.Context, kind, targetID string) (int64, error) {
+	return s.tokenRepo.RevokeByTarget(ctx, kind, targetID, time.Now())
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// GroupService クラスサービス
+type GroupService struct {
+	groupRepo       *repository.GroupRepository
+	groupMemberRepo *repository.GroupMemberRepository
+}
+
+// NewGroupService クラスサービスを作成
+func NewGroupService(groupRepo *repository.GroupRepository, groupMemberRepo *repository.GroupMemberRepository) *GroupService {
+	return &GroupService{
+		groupRepo:       groupRepo,
+		groupMemberRepo: groupMemberRepo,
+	}
+}
+
+// Create クラスを作成
+func (s *GroupService) Create(ctx context.Context, orgID, name string, year int, caption string) (*model.Group, error) {
+	group := &model.Group{
+		ID:        uuid.NewString(),
+		OrgID:     orgID,
+		Name:      name,
+		Year:      year,
+		Caption:   caption,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetByID IDでクラスを取得
+func (s *GroupService) GetByID(ctx context.Context, id string) (*model.Group, error) {
+	return s.groupRepo.FindByID(ctx, id)
+}
+
+// GetByOrgNameYear 組織ID・クラス名・年度でクラスを取得
+func (s *GroupService) GetByOrgNameYear(ctx context.Context, orgID, name string, year int) (*model.Group, error) {
+	return s.groupRepo.FindByOrgNameYear(ctx, orgID, name, year)
+}
+
+// GetByOrgID 組織IDでクラス一覧を取得（yearが0の場合は全年度）
+func (s *GroupService) GetByOrgID(ctx context.Context, orgID string, year int) ([]model.Group, error) {
+	return s.groupRepo.FindByOrgID(ctx, orgID, year)
+}
+
+// Update クラスを更新
+func (s *GroupService) Update(ctx context.Context, group *model.Group, name string, year int, caption string) error {
+	group.Name = name
+	group.Year = year
+	group.Caption = caption
+	group.UpdatedAt = time.Now()
+	return s.groupRepo.Update(ctx, group)
+}
+
+// Delete クラスを削除（所属は削除し、授業の割り当ては解除する）
+func (s *GroupService) Delete(ctx context.Context, id string) error {
+	return s.groupRepo.Delete(ctx, id)
+}
+
+// AddMember ユーザーをクラスに所属させる（すでに所属している場合は何もしない）
+func (s *GroupService) AddMember(ctx context.Context, group *model.Group, userID string) error {
+	return s.groupMemberRepo.CreateIfNotExists(ctx, &model.GroupMember{
+		ID:        uuid.NewString(),
+		GroupID:   group.ID,
+		UserID:    userID,
+		OrgID:     group.OrgID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+}
+
+// GetMember クラスの所属を取得
+func (s *GroupService) GetMember(ctx context.Context, groupID, userID string) (*model.GroupMember, error) {
+	return s.groupMemberRepo.FindByGroupAndUser(ctx, groupID, userID)
+}
+
+// GetMembers クラスの所属一覧を取得（ユーザー情報を含む）
+func (s *GroupService) GetMembers(ctx context.Context, groupID string) ([]model.GroupMember, error) {
+	return s.groupMemberRepo.FindByGroupID(ctx, groupID)
+}
+
+// RemoveMember ユーザーをクラスから外す
+func (s *GroupService) RemoveMember(ctx context.Context, groupID, userID string) error {
+	return s.groupMemberRepo.DeleteByGroupAndUser(ctx, groupID, userID)
+}
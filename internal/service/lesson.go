@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
@@ -22,18 +23,20 @@ func NewLessonService(lessonRepo *repository.LessonRepository) *LessonService {
 	}
 }
 
-// Create 授業を作成
-func (s *LessonService) Create(ctx context.Context, subjectID, roomID, orgID string, dayOfWeek int, startTime, endTime time.Time, period int, date *time.Time) (*model.Lesson, error) {
+// Create 授業を作成（groupIDを指定した場合はクラスの授業）
+func (s *LessonService) Create(ctx context.Context, subjectID, roomID, orgID string, dayOfWeek int, startTime, endTime time.Time, period int, date, until *time.Time, groupID *string) (*model.Lesson, error) {
 	lesson := &model.Lesson{
 		ID:        uuid.NewString(),
 		SubjectID: subjectID,
 		RoomID:    roomID,
 		OrgID:     orgID,
+		GroupID:   groupID,
 		DayOfWeek: dayOfWeek,
 		StartTime: startTime,
 		EndTime:   endTime,
 		Period:    period,
 		Date:      date,
+		Until:     until,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -45,6 +48,35 @@ func (s *LessonService) Create(ctx context.Context, subjectID, roomID, orgID str
 	return lesson, nil
 }
 
+// UpsertByICalUID iCalendarの予定から取り込んだ授業を作成または更新し、作成した場合はtrueを返す
+// lesson.ICalUIDは必須。組織・UID・曜日が同じ授業があれば、ID・クラス・時限・作成日時を引き継いで内容を置き換える
+func (s *LessonService) UpsertByICalUID(ctx context.Context, lesson *model.Lesson) (bool, error) {
+	now := time.Now()
+	existing, err := s.lessonRepo.FindByICalUID(ctx, lesson.OrgID, *lesson.ICalUID, lesson.DayOfWeek)
+	if err != nil {
+		if !errors.Is(err, repository.ErrorRecordNotFound) {
+			return false, err
+		}
+		lesson.ID = uuid.NewString()
+		lesson.CreatedAt = now
+		lesson.UpdatedAt = now
+		if err := s.lessonRepo.Create(ctx, lesson); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	lesson.ID = existing.ID
+	lesson.GroupID = existing.GroupID
+	lesson.Period = existing.Period
+	lesson.CreatedAt = existing.CreatedAt
+	lesson.UpdatedAt = now
+	if err := s.lessonRepo.Update(ctx, lesson); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 // GetByID IDで授業を取得
 func (s *LessonService) GetByID(ctx context.Context, id string) (*model.Lesson, error) {
 	return s.lessonRepo.FindByID(ctx, id)
@@ -55,19 +87,14 @@ func (s *LessonService) GetByOrgID(ctx context.Context, orgID string) ([]model.L
 	return s.lessonRepo.FindByOrgID(ctx, orgID)
 }
 
-// GetByDate 特定の日付の授業を取得
-func (s *LessonService) GetByDate(ctx context.Context, orgID string, date time.Time) ([]model.Lesson, error) {
-	return s.lessonRepo.FindByDate(ctx, orgID, date)
+// GetByRoomID 部屋IDで授業一覧を取得
+func (s *LessonService) GetByRoomID(ctx context.Context, roomID string) ([]model.Lesson, error) {
+	return s.lessonRepo.FindByRoomID(ctx, roomID)
 }
 
-// GetByUserAndDate 特定ユーザーの特定日付の授業を取得
-func (s *LessonService) GetByUserAndDate(ctx context.Context, userID string, date time.Time) ([]model.Lesson, error) {
-	return s.lessonRepo.FindByUserAndDate(ctx, userID, date)
-}
-
-// GetMonitoringLessons 監視対象の授業を取得
-func (s *LessonService) GetMonitoringLessons(ctx context.Context, currentTime time.Time) ([]model.Lesson, error) {
-	return s.lessonRepo.FindMonitoringLessons(ctx, currentTime)
+// GetByGroupID クラスIDで授業一覧を取得
+func (s *LessonService) GetByGroupID(ctx context.Context, groupID string) ([]model.Lesson, error) {
+	return s.lessonRepo.FindByGroupID(ctx, groupID)
 }
 
 // Update 授業を更新
@@ -80,8 +107,3 @@ func (s *LessonService) Update(ctx context.Context, lesson *model.Lesson) error
 func (s *LessonService) Delete(ctx context.Context, id string) error {
 	return s.lessonRepo.Delete(ctx, id)
 }
-
-// GetByRoomAndTime 部屋IDと時刻から授業を検索
-func (s *LessonService) GetByRoomAndTime(ctx context.Context, roomID string, currentTime time.Time) (*model.Lesson, error) {
-	return s.lessonRepo.FindByRoomAndTime(ctx, roomID, currentTime)
-}
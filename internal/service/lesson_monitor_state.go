@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// LessonMonitorStateService 授業監視状態サービス
+type LessonMonitorStateService struct {
+	stateRepo *repository.LessonMonitorStateRepository
+}
+
+// NewLessonMonitorStateService 授業監視状態サービスを作成
+func NewLessonMonitorStateService(stateRepo *repository.LessonMonitorStateRepository) *LessonMonitorStateService {
+	return &LessonMonitorStateService{
+		stateRepo: stateRepo,
+	}
+}
+
+// Get 授業IDと日付で監視状態を取得
+func (s *LessonMonitorStateService) Get(ctx context.Context, lessonID string, lessonDate time.Time) (*model.LessonMonitorState, error) {
+	state, err := s.stateRepo.FindByLessonAndDate(ctx, lessonID, lessonDate)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Save 監視状態を保存
+func (s *LessonMonitorStateService) Save(ctx context.Context, state *model.LessonMonitorState) error {
+	now := time.Now()
+	if state.ID == "" {
+		state.ID = uuid.NewString()
+		state.CreatedAt = now
+	}
+	state.UpdatedAt = now
+	return s.stateRepo.Upsert(ctx, state)
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// LessonOccurrenceService 授業実施回サービス
+// 時間割（毎週の授業・日付指定の授業）を組織のカレンダーに従って日付ごとの実施回に展開する
+type LessonOccurrenceService struct {
+	occurrenceRepo    *repository.LessonOccurrenceRepository
+	lessonRepo        *repository.LessonRepository
+	userRepo          *repository.UserRepository
+	organizationRepo  *repository.OrganizationRepository
+	overrideRepo      *repository.LessonOverrideRepository
+	calendarService   *CalendarService
+	enrollmentService *EnrollmentService
+}
+
+// NewLessonOccurrenceService 授業実施回サービスを作成
+func NewLessonOccurrenceService(
+	occurrenceRepo *repository.LessonOccurrenceRepository,
+	lessonRepo *repository.LessonRepository,
+	userRepo *repository.UserRepository,
+	organizationRepo *repository.OrganizationRepository,
+	overrideRepo *repository.LessonOverrideRepository,
+	calendarService *CalendarService,
+	enrollmentService *EnrollmentService,
+) *LessonOccurrenceService {
+	return &LessonOccurrenceService{
+		occurrenceRepo:    occurrenceRepo,
+		lessonRepo:        lessonRepo,
+		userRepo:          userRepo,
+		organizationRepo:  organizationRepo,
+		overrideRepo:      overrideRepo,
+		calendarService:   calendarService,
+		enrollmentService: enrollmentService,
+	}
+}
+
+// EnsureForDate 指定日の実施回を時間割とカレンダーから作成（変更がない実施回は更新しない）
+// orgIDが空の場合は全組織が対象
+func (s *LessonOccurrenceService) EnsureForDate(ctx context.Context, orgID string, date time.Time) error {
+	date = model.LessonDateOf(date)
+
+	if orgID != "" {
+		return s.ensureForOrg(ctx, orgID, date)
+	}
+
+	organizations, err := s.organizationRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, organization := range organizations {
+		if err := s.ensureForOrg(ctx, organization.ID, date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureForOrg 組織の指定日の実施回を作成し、実施しない授業の実施回を休講にする
+// 日付指定の授業は休日・学期外でも実施する（補講など管理者が明示的に登録したもの）
+// 授業変更（休講・教室変更・時間変更）が登録されている場合はその内容を反映する
+func (s *LessonOccurrenceService) ensureForOrg(ctx context.Context, orgID string, date time.Time) error {
+	day, err := s.calendarService.ResolveDay(ctx, orgID, date)
+	if err != nil {
+		return err
+	}
+
+	lessons, err := s.lessonRepo.FindDatedOn(ctx, orgID, date)
+	if err != nil {
+		return err
+	}
+	if day.Open {
+		weekly, err := s.lessonRepo.FindWeeklyOn(ctx, orgID, date, day.DayOfWeek)
+		if err != nil {
+			return err
+		}
+		lessons = append(lessons, weekly...)
+	}
+
+	overrides, err := s.overrideRepo.FindByOrgAndDate(ctx, orgID, date)
+	if err != nil {
+		return err
+	}
+	overrideByLesson := make(map[string]model.LessonOverride, len(overrides))
+	for _, override := range overrides {
+		overrideByLesson[override.LessonID] = override
+	}
+
+	existing, err := s.occurrenceRepo.FindAllByOrgAndDate(ctx, orgID, date)
+	if err != nil {
+		return err
+	}
+	existingByLesson := make(map[string]model.LessonOccurrence, len(existing))
+	for _, occurrence := range existing {
+		existingByLesson[occurrence.LessonID] = occurrence
+	}
+
+	now := time.Now()
+	scheduled := make(map[string]bool, len(lessons))
+	for _, lesson := range lessons {
+		scheduled[lesson.ID] = true
+
+		startAt, endAt := lesson.TimesOn(date)
+		occurrence := model.LessonOccurrence{
+			ID:        uuid.NewString(),
+			LessonID:  lesson.ID,
+			OrgID:     lesson.OrgID,
+			SubjectID: lesson.SubjectID,
+			RoomID:    lesson.RoomID,
+			GroupID:   lesson.GroupID,
+			Date:      date,
+			StartAt:   startAt,
+			EndAt:     endAt,
+			Period:    lesson.Period,
+			Status:    model.OccurrenceScheduled,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if override, ok := overrideByLesson[lesson.ID]; ok {
+			override.Apply(&occurrence)
+		}
+
+		if current, ok := existingByLesson[lesson.ID]; ok && sameSchedule(current, occurrence) {
+			continue
+		}
+		if err := s.occurrenceRepo.Upsert(ctx, &occurrence); err != nil {
+			return err
+		}
+	}
+
+	// 実施しなくなった実施回を休講にする（滞在ログとの紐付けを残すため削除はしない）
+	reason := day.Reason
+	if day.Open {
+		reason = "時間割の変更"
+	}
+	for _, occurrence := range existing {
+		if scheduled[occurrence.LessonID] || occurrence.IsCancelled() {
+			continue
+		}
+		if err := s.occurrenceRepo.Cancel(ctx, occurrence.ID, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSchoolDayByUser ユーザーの組織の指定日の授業実施状況を取得
+func (s *LessonOccurrenceService) GetSchoolDayByUser(ctx context.Context, userID string, date time.Time) (*model.SchoolDay, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.calendarService.ResolveDay(ctx, user.OrgID, date)
+}
+
+// GetSchoolDay 組織の指定日の授業実施状況を取得
+func (s *LessonOccurrenceService) GetSchoolDay(ctx context.Context, orgID string, date time.Time) (*model.SchoolDay, error) {
+	return s.calendarService.ResolveDay(ctx, orgID, date)
+}
+
+// GetByID IDで実施回を取得
+func (s *LessonOccurrenceService) GetByID(ctx context.Context, id string) (*model.LessonOccurrence, error) {
+	return s.occurrenceRepo.FindByID(ctx, id)
+}
+
+// GetByDate 組織の指定日の実施回一覧を取得
+func (s *LessonOccurrenceService) GetByDate(ctx context.Context, orgID string, date time.Time) ([]model.LessonOccurrence, error) {
+	if err := s.EnsureForDate(ctx, orgID, date); err != nil {
+		return nil, err
+	}
+	return s.occurrenceRepo.FindByDate(ctx, orgID, model.LessonDateOf(date))
+}
+
+// GetTimetableByUser 特定ユーザーの特定日付の時間割を取得
+// 実施予定の実施回に加えて、授業変更で休講になった実施回も含める（履修していない教科・所属していないクラスの授業は除く）
+func (s *LessonOccurrenceService) GetTimetableByUser(ctx context.Context, userID string, date time.Time) ([]model.LessonOccurrence, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.EnsureForDate(ctx, user.OrgID, date); err != nil {
+		return nil, err
+	}
+	occurrences, err := s.occurrenceRepo.FindTimetable(ctx, user.OrgID, model.LessonDateOf(date))
+	if err != nil {
+		return nil, err
+	}
+	return s.FilterForUser(ctx, user, occurrences)
+}
+
+// GetTimetableByGroup クラスの指定日の時間割（クラスに割り当てた授業の実施回）を取得
+// 実施予定の実施回に加えて、授業変更で休講になった実施回も含める
+func (s *LessonOccurrenceService) GetTimetableByGroup(ctx context.Context, group *model.Group, date time.Time) ([]model.LessonOccurrence, error) {
+	if err := s.EnsureForDate(ctx, group.OrgID, date); err != nil {
+		return nil, err
+	}
+	occurrences, err := s.occurrenceRepo.FindTimetable(ctx, group.OrgID, model.LessonDateOf(date))
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]model.LessonOccurrence, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if occurrence.GroupID != nil && *occurrence.GroupID == group.ID {
+			filtered = append(filtered, occurrence)
+		}
+	}
+	return filtered, nil
+}
+
+// GetByUserAndDate 特定ユーザーの特定日付の実施回一覧を取得（履修していない教科・所属していないクラスの授業は除く）
+func (s *LessonOccurrenceService) GetByUserAndDate(ctx context.Context, userID string, date time.Time) ([]model.LessonOccurrence, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := s.GetByDate(ctx, user.OrgID, date)
+	if err != nil {
+		return nil, err
+	}
+	return s.FilterForUser(ctx, user, occurrences)
+}
+
+// FilterForUser ユーザーが対象者になっている（履修している教科・所属するクラスの）実施回のみを返す
+func (s *LessonOccurrenceService) FilterForUser(ctx context.Context, user *model.User, occurrences []model.LessonOccurrence) ([]model.LessonOccurrence, error) {
+	audience, err := s.enrollmentService.GetAudience(ctx, user.OrgID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]model.LessonOccurrence, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if audience.Includes(occurrence.SubjectID, occurrence.GroupID) {
+			filtered = append(filtered, occurrence)
+		}
+	}
+	return filtered, nil
+}
+
+// GetMonitoring 監視対象の実施回を取得（開始5分前〜終了10分後）
+func (s *LessonOccurrenceService) GetMonitoring(ctx context.Context, currentTime time.Time) ([]model.LessonOccurrence, error) {
+	if err := s.EnsureForDate(ctx, "", currentTime); err != nil {
+		return nil, err
+	}
+	from, to := model.MonitoringRange(currentTime)
+	return s.occurrenceRepo.FindOverlapping(ctx, from, to)
+}
+
+// GetByRoomAndTime 部屋IDと時刻から実施中の実施回を取得
+func (s *LessonOccurrenceService) GetByRoomAndTime(ctx context.Context, roomID string, currentTime time.Time) (*model.LessonOccurrence, error) {
+	if err := s.EnsureForDate(ctx, "", currentTime); err != nil {
+		return nil, err
+	}
+	return s.occurrenceRepo.FindByRoomAndTime(ctx, roomID, currentTime)
+}
+
+// sameSchedule 実施回の日時・部屋などが一致するか
+func sameSchedule(a, b model.LessonOccurrence) bool {
+	return a.OrgID == b.OrgID &&
+		a.SubjectID == b.SubjectID &&
+		a.RoomID == b.RoomID &&
+		sameGroup(a.GroupID, b.GroupID) &&
+		a.StartAt.Equal(b.StartAt) &&
+		a.EndAt.Equal(b.EndAt) &&
+		a.Period == b.Period &&
+		a.Status == b.Status &&
+		a.Reason == b.Reason &&
+		a.Overridden == b.Overridden
+}
+
+// sameGroup 対象のクラスが一致するか
+func sameGroup(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
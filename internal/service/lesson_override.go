@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// LessonOverrideService 授業変更サービス
+type LessonOverrideService struct {
+	overrideRepo *repository.LessonOverrideRepository
+}
+
+// NewLessonOverrideService 授業変更サービスを作成
+func NewLessonOverrideService(overrideRepo *repository.LessonOverrideRepository) *LessonOverrideService {
+	return &LessonOverrideService{
+		overrideRepo: overrideRepo,
+	}
+}
+
+// Create 授業変更を作成
+func (s *LessonOverrideService) Create(ctx context.Context, override *model.LessonOverride) error {
+	override.ID = uuid.NewString()
+	override.CreatedAt = time.Now()
+	override.UpdatedAt = time.Now()
+	return s.overrideRepo.Create(ctx, override)
+}
+
+// Upsert 授業変更を作成（同じ授業・日付の授業変更があれば内容を置き換える）
+func (s *LessonOverrideService) Upsert(ctx context.Context, override *model.LessonOverride) error {
+	existing, err := s.overrideRepo.FindByLessonAndDate(ctx, override.LessonID, override.Date)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return s.Create(ctx, override)
+		}
+		return err
+	}
+
+	override.ID = existing.ID
+	override.CreatedAt = existing.CreatedAt
+	return s.Update(ctx, override)
+}
+
+// GetByID IDで授業変更を取得
+func (s *LessonOverrideService) GetByID(ctx context.Context, id string) (*model.LessonOverride, error) {
+	return s.overrideRepo.FindByID(ctx, id)
+}
+
+// GetByLessonAndDate 授業IDと日付で授業変更を取得
+func (s *LessonOverrideService) GetByLessonAndDate(ctx context.Context, lessonID string, date time.Time) (*model.LessonOverride, error) {
+	return s.overrideRepo.FindByLessonAndDate(ctx, lessonID, date)
+}
+
+// GetByOrgID 組織IDで授業変更一覧を取得（from・toがゼロ値の場合は期間を限定しない）
+func (s *LessonOverrideService) GetByOrgID(ctx context.Context, orgID string, from, to time.Time) ([]model.LessonOverride, error) {
+	return s.overrideRepo.FindByOrgID(ctx, orgID, from, to)
+}
+
+// Update 授業変更を更新
+func (s *LessonOverrideService) Update(ctx context.Context, override *model.LessonOverride) error {
+	override.UpdatedAt = time.Now()
+	return s.overrideRepo.Update(ctx, override)
+}
+
+// Delete 授業変更を削除
+func (s *LessonOverrideService) Delete(ctx context.Context, id string) error {
+	return s.overrideRepo.Delete(ctx, id)
+}
@@ -1,30 +1,53 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
 )
 
 // MapService マップサービス
 type MapService struct {
 	mistClient *mistapi.Client
+	mapRepo    *repository.MapRepository
 }
 
 // NewMapService マップサービスを作成
-func NewMapService(mistClient *mistapi.Client) *MapService {
+func NewMapService(mistClient *mistapi.Client, mapRepo *repository.MapRepository) *MapService {
 	return &MapService{
 		mistClient: mistClient,
+		mapRepo:    mapRepo,
 	}
 }
 
+// GetStored 同期済みの有効なマップ一覧を取得
+func (s *MapService) GetStored(ctx context.Context) ([]model.Map, error) {
+	maps, err := s.mapRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return maps, nil
+}
+
+// GetStoredByMistMapID 同期済みのマップをMistマップIDで取得
+func (s *MapService) GetStoredByMistMapID(ctx context.Context, mistMapID string) (*model.Map, error) {
+	m, err := s.mapRepo.FindByMistMapID(ctx, mistMapID)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // GetAll 全マップを取得
-func (s *MapService) GetAll() ([]mistapi.MistMap, error) {
+func (s *MapService) GetAll(ctx context.Context) ([]mistapi.MistMap, error) {
 	if s.mistClient == nil {
 		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
 	}
 
-	mistMaps, err := s.mistClient.GetMaps(s.mistClient.SiteID)
+	mistMaps, err := s.mistClient.GetMaps(ctx, s.mistClient.SiteID)
 	if err != nil {
 		return nil, fmt.Errorf("マップ取得エラー: %w", err)
 	}
@@ -33,12 +56,12 @@ func (s *MapService) GetAll() ([]mistapi.MistMap, error) {
 }
 
 // GetImage マップ画像を取得
-func (s *MapService) GetImage(mapID string) ([]byte, error) {
+func (s *MapService) GetImage(ctx context.Context, mapID string) ([]byte, error) {
 	if s.mistClient == nil {
 		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
 	}
 
-	imageData, err := s.mistClient.GetMapImage(s.mistClient.SiteID, mapID)
+	imageData, err := s.mistClient.GetMapImage(ctx, s.mistClient.SiteID, mapID)
 	if err != nil {
 		return nil, fmt.Errorf("マップ画像取得エラー: %w", err)
 	}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
+
+	"github.com/google/uuid"
+)
+
+// MistSyncService Mistのマップ・ゾーン定義をDBに同期するサービス
+type MistSyncService struct {
+	mistClient *mistapi.Client
+	mapRepo    *repository.MapRepository
+	zoneRepo   *repository.ZoneRepository
+}
+
+// NewMistSyncService Mist同期サービスを作成
+func NewMistSyncService(mistClient *mistapi.Client, mapRepo *repository.MapRepository, zoneRepo *repository.ZoneRepository) *MistSyncService {
+	return &MistSyncService{
+		mistClient: mistClient,
+		mapRepo:    mapRepo,
+		zoneRepo:   zoneRepo,
+	}
+}
+
+// MistSyncResult 同期結果
+type MistSyncResult struct {
+	Maps             int       `json:"maps"`
+	Zones            int       `json:"zones"`
+	SkippedZones     int       `json:"skipped_zones"`
+	DeactivatedMaps  int64     `json:"deactivated_maps"`
+	DeactivatedZones int64     `json:"deactivated_zones"`
+	SyncedAt         time.Time `json:"synced_at"`
+}
+
+// Sync Mistからマップ・ゾーンを取得してDBに反映し、Mistから消えたものを無効化
+// 取得に失敗した場合は何も無効化しない
+func (s *MistSyncService) Sync(ctx context.Context) (*MistSyncResult, error) {
+	if s.mistClient == nil {
+		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
+	}
+
+	siteID := s.mistClient.SiteID
+	mistMaps, err := s.mistClient.GetMaps(ctx, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("マップ取得エラー: %w", err)
+	}
+	mistZones, err := s.mistClient.GetZones(ctx, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("mist APIゾーン取得エラー: %w", err)
+	}
+
+	now := time.Now()
+	result := &MistSyncResult{SyncedAt: now}
+
+	// マップ（MistマップID → DBのマップID）
+	mapIDs := make(map[string]string, len(mistMaps))
+	mistMapIDs := make([]string, 0, len(mistMaps))
+	for _, mm := range mistMaps {
+		m := &model.Map{
+			ID:           uuid.NewString(),
+			MistMapID:    mm.ID,
+			Name:         mm.Name,
+			Width:        mm.Width,
+			Height:       mm.Height,
+			WidthM:       mm.WidthM,
+			HeightM:      mm.HeightM,
+			PPM:          mm.PPM,
+			URL:          mm.URL,
+			ThumbnailURL: mm.ThumbnailURL,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			IsActive:     true,
+		}
+		if err := s.mapRepo.Upsert(ctx, m); err != nil {
+			return nil, fmt.Errorf("マップ保存エラー (Map=%s): %w", mm.ID, err)
+		}
+		mapIDs[mm.ID] = m.ID
+		mistMapIDs = append(mistMapIDs, mm.ID)
+		result.Maps++
+	}
+
+	// ゾーン
+	mistZoneIDs := make([]string, 0, len(mistZones))
+	for _, mz := range mistZones {
+		mapID, ok := mapIDs[mz.MapID]
+		if !ok {
+			log.Printf("[MistSyncService] マップが見つからないためゾーンをスキップします: Zone=%s, Map=%s", mz.ID, mz.MapID)
+			result.SkippedZones++
+			continue
+		}
+
+		vertices := make([]model.ZoneVertex, 0, len(mz.Vertices))
+		for _, v := range mz.Vertices {
+			vertices = append(vertices, model.ZoneVertex{X: v.X, Y: v.Y})
+		}
+
+		zone := &model.Zone{
+			ID:         uuid.NewString(),
+			MistZoneID: mz.ID,
+			Name:       mz.Name,
+			MapID:      mapID,
+			MistMapID:  mz.MapID,
+			Vertices:   vertices,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			IsActive:   true,
+		}
+		if err := s.zoneRepo.Upsert(ctx, zone); err != nil {
+			return nil, fmt.Errorf("ゾーン保存エラー (Zone=%s): %w", mz.ID, err)
+		}
+		mistZoneIDs = append(mistZoneIDs, mz.ID)
+		result.Zones++
+	}
+
+	// Mistから消えたものを無効化
+	result.DeactivatedZones, err = s.zoneRepo.DeactivateMissing(ctx, mistZoneIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ゾーン無効化エラー: %w", err)
+	}
+	result.DeactivatedMaps, err = s.mapRepo.DeactivateMissing(ctx, mistMapIDs)
+	if err != nil {
+		return nil, fmt.Errorf("マップ無効化エラー: %w", err)
+	}
+
+	return result, nil
+}
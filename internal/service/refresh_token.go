@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenService リフレッシュトークンサービス
+type RefreshTokenService struct {
+	tokenRepo *repository.RefreshTokenRepository
+}
+
+// NewRefreshTokenService リフレッシュトークンサービスを作成
+func NewRefreshTokenService(tokenRepo *repository.RefreshTokenRepository) *RefreshTokenService {
+	return &RefreshTokenService{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Issue アカウントのリフレッシュトークンを発行（戻り値のトークンはこの時だけ取得できる）
+func (s *RefreshTokenService) Issue(ctx context.Context, accountID string, ttl time.Duration) (string, *model.RefreshToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	refreshToken := &model.RefreshToken{
+		ID:        uuid.NewString(),
+		AccountID: accountID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, refreshToken); err != nil {
+		return "", nil, err
+	}
+	return token, refreshToken, nil
+}
+
+// GetByToken トークンでリフレッシュトークンを取得
+func (s *RefreshTokenService) GetByToken(ctx context.Context, token string) (*model.RefreshToken, error) {
+	return s.tokenRepo.FindByHash(ctx, hashToken(token))
+}
+
+// Revoke リフレッシュトークンを失効させる（すでに失効していた場合はfalse）
+func (s *RefreshTokenService) Revoke(ctx context.Context, refreshToken *model.RefreshToken) (bool, error) {
+	return s.tokenRepo.Revoke(ctx, refreshToken.ID, time.Now())
+}
+
+// RevokeAll アカウントのリフレッシュトークンをすべて失効させる
+func (s *RefreshTokenService) RevokeAll(ctx context.Context, accountID string) error {
+	return s.tokenRepo.RevokeByAccountID(ctx, accountID, time.Now())
+}
+
+// DeleteExpired 有効期限切れのリフレッシュトークンを削除
+func (s *RefreshTokenService) DeleteExpired(ctx context.Context) error {
+	return s.tokenRepo.DeleteExpired(ctx, time.Now())
+}
+
+// hashToken トークンのSHA-256（16進数）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
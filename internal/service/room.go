@@ -23,7 +23,7 @@ func NewRoomService(roomRepo *repository.RoomRepository) *RoomService {
 }
 
 // Create 部屋を作成
-func (r *RoomService) Create(ctx context.Context, orgID, orgRoomID, name, caption, mistZoneID string) (*model.Room, error) {
+func (r *RoomService) Create(ctx context.Context, orgID, orgRoomID, name, caption, mistZoneID, mapID string) (*model.Room, error) {
 	room := &model.Room{
 		ID:         uuid.NewString(),
 		OrgID:      orgID,
@@ -31,6 +31,7 @@ func (r *RoomService) Create(ctx context.Context, orgID, orgRoomID, name, captio
 		Name:       name,
 		Caption:    caption,
 		MistZoneID: mistZoneID,
+		MapID:      mapID,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
@@ -79,10 +80,11 @@ func (r *RoomService) GetByOrgRoomID(ctx context.Context, orgID, orgRoomID strin
 }
 
 // Update 部屋を更新
-func (r *RoomService) Update(ctx context.Context, room *model.Room, name, caption, mistZoneID string) error {
+func (r *RoomService) Update(ctx context.Context, room *model.Room, name, caption, mistZoneID, mapID string) error {
 	room.Name = name
 	room.Caption = caption
 	room.MistZoneID = mistZoneID
+	room.MapID = mapID
 	room.UpdatedAt = time.Now()
 
 	if err := r.roomRepo.Update(ctx, room); err != nil {
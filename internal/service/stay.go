@@ -100,6 +100,15 @@ func (s *StayService) GetByLessonID(ctx context.Context, lessonID string) ([]mod
 	return stays, nil
 }
 
+// GetByLessonAndDate LessonIDと授業実施日で滞在一覧を取得
+func (s *StayService) GetByLessonAndDate(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.Stay, error) {
+	stays, err := s.stayRepo.FindByLessonAndDate(ctx, lessonID, lessonDate)
+	if err != nil {
+		return nil, err
+	}
+	return stays, nil
+}
+
 // GetActiveByUserAndLesson ユーザーIDとLessonIDでアクティブな滞在を取得
 func (s *StayService) GetActiveByUserAndLesson(ctx context.Context, userID string, lessonID string) (*model.Stay, error) {
 	stay, err := s.stayRepo.FindActiveByUserAndLesson(ctx, userID, lessonID)
@@ -109,9 +118,32 @@ func (s *StayService) GetActiveByUserAndLesson(ctx context.Context, userID strin
 	return stay, nil
 }
 
+// GetActiveAutoWithLesson 授業に紐づく自動記録のアクティブな滞在一覧を取得
+func (s *StayService) GetActiveAutoWithLesson(ctx context.Context) ([]model.Stay, error) {
+	stays, err := s.stayRepo.FindActiveAutoWithLesson(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stays, nil
+}
+
 // CreateWithLesson 滞在を作成（授業付き）
-func (s *StayService) CreateWithLesson(ctx context.Context, stay *model.Stay) error {
-	return s.stayRepo.Create(ctx, stay)
+// 同じユーザー・授業・実施日の滞在が既にある場合は作成せず、stayを既存の滞在で置き換えてfalseを返す
+func (s *StayService) CreateWithLesson(ctx context.Context, stay *model.Stay) (bool, error) {
+	if stay.LessonID != nil && stay.LessonDate == nil {
+		lessonDate := model.LessonDateOf(stay.CreatedAt)
+		stay.LessonDate = &lessonDate
+	}
+	return s.stayRepo.CreateOrGetByLessonDate(ctx, stay)
+}
+
+// GetByUserLessonDate ユーザーID・LessonID・授業実施日で滞在を取得
+func (s *StayService) GetByUserLessonDate(ctx context.Context, userID, lessonID string, lessonDate time.Time) (*model.Stay, error) {
+	stay, err := s.stayRepo.FindByUserLessonDate(ctx, userID, lessonID, lessonDate)
+	if err != nil {
+		return nil, err
+	}
+	return stay, nil
 }
 
 // Update 滞在を更新
@@ -125,6 +157,32 @@ func (s *StayService) Update(ctx context.Context, stay *model.Stay, subjectID, d
 	return nil
 }
 
+// RecordAttendance 滞在時間と出席判定結果を記録
+func (s *StayService) RecordAttendance(ctx context.Context, stay *model.Stay, dwellMinutes int, attended *bool) error {
+	if err := s.stayRepo.UpdateAttendance(ctx, stay.ID, dwellMinutes, attended); err != nil {
+		return err
+	}
+	stay.DwellMinutes = dwellMinutes
+	stay.Attended = attended
+	return nil
+}
+
+// CloseStay 指定した時刻で滞在を終了する（最終検知時刻での自動退出など）
+func (s *StayService) CloseStay(ctx context.Context, id int, leavedAt time.Time) error {
+	if err := s.stayRepo.CloseStay(ctx, id, leavedAt); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReopenStay 終了した滞在を再開する
+func (s *StayService) ReopenStay(ctx context.Context, id int) error {
+	if err := s.stayRepo.ReopenStay(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
 // EndStay 滞在を終了する
 func (s *StayService) EndStay(ctx context.Context, id int) error {
 	if err := s.stayRepo.EndStay(ctx, id); err != nil {
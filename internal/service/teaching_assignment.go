@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TeachingAssignmentService 教員の担当サービス
+type TeachingAssignmentService struct {
+	assignmentRepo *repository.TeachingAssignmentRepository
+}
+
+// NewTeachingAssignmentService 教員の担当サービスを作成
+func NewTeachingAssignmentService(assignmentRepo *repository.TeachingAssignmentRepository) *TeachingAssignmentService {
+	return &TeachingAssignmentService{
+		assignmentRepo: assignmentRepo,
+	}
+}
+
+// Create 教員の担当を作成（lessonIDがnilの場合は教科全体の担当）
+func (s *TeachingAssignmentService) Create(ctx context.Context, orgID, userID, subjectID string, lessonID *string) (*model.TeachingAssignment, error) {
+	assignment := &model.TeachingAssignment{
+		ID:        uuid.NewString(),
+		OrgID:     orgID,
+		UserID:    userID,
+		SubjectID: subjectID,
+		LessonID:  lessonID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.assignmentRepo.Create(ctx, assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// GetByID IDで教員の担当を取得
+func (s *TeachingAssignmentService) GetByID(ctx context.Context, id string) (*model.TeachingAssignment, error) {
+	return s.assignmentRepo.FindByID(ctx, id)
+}
+
+// GetByTarget 教員・教科・授業が一致する担当を取得
+func (s *TeachingAssignmentService) GetByTarget(ctx context.Context, userID, subjectID string, lessonID *string) (*model.TeachingAssignment, error) {
+	return s.assignmentRepo.FindByTarget(ctx, userID, subjectID, lessonID)
+}
+
+// GetByOrgID 組織IDで教員の担当一覧を取得（userIDは省略可）
+func (s *TeachingAssignmentService) GetByOrgID(ctx context.Context, orgID, userID string) ([]model.TeachingAssignment, error) {
+	return s.assignmentRepo.FindByOrgID(ctx, orgID, userID)
+}
+
+// GetByUserID 教員の担当一覧を取得
+func (s *TeachingAssignmentService) GetByUserID(ctx context.Context, userID string) ([]model.TeachingAssignment, error) {
+	return s.assignmentRepo.FindByUserID(ctx, userID)
+}
+
+// Delete 教員の担当を削除
+func (s *TeachingAssignmentService) Delete(ctx context.Context, id string) error {
+	return s.assignmentRepo.Delete(ctx, id)
+}
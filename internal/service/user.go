@@ -24,11 +24,12 @@ func NewUserService(userRepo *repository.UserRepository) *UserService {
 }
 
 // Create ユーザーを作成
-func (u *UserService) Create(ctx context.Context, orgID, mail string) (*model.User, error) {
+func (u *UserService) Create(ctx context.Context, orgID, mail, role string) (*model.User, error) {
 	user := &model.User{
 		ID:        uuid.NewString(),
 		OrgID:     orgID,
 		Mail:      mail,
+		Role:      role,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -114,6 +115,17 @@ func (u *UserService) Update(ctx context.Context, user *model.User, mail string)
 	return nil
 }
 
+// UpdateRole ユーザーの役割を更新
+func (u *UserService) UpdateRole(ctx context.Context, user *model.User, role string) error {
+	user.Role = role
+	user.UpdatedAt = time.Now()
+
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return nil
+}
+
 // Delete ユーザーを削除
 func (u *UserService) Delete(ctx context.Context, id string) error {
 	if err := u.userRepo.SoftDelete(ctx, id); err != nil {
@@ -1,31 +1,55 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/pkg/mistapi"
 )
 
 // ZoneService ゾーンサービス
 type ZoneService struct {
 	mistClient *mistapi.Client
+	zoneRepo   *repository.ZoneRepository
 }
 
 // NewZoneService ゾーンサービスを作成
-func NewZoneService(mistClient *mistapi.Client) *ZoneService {
+func NewZoneService(mistClient *mistapi.Client, zoneRepo *repository.ZoneRepository) *ZoneService {
 	return &ZoneService{
 		mistClient: mistClient,
+		zoneRepo:   zoneRepo,
 	}
 }
 
+// GetStored 同期済みの有効なゾーン一覧を取得（MistマップIDを指定した場合はそのマップのみ）
+func (s *ZoneService) GetStored(ctx context.Context, mistMapID string) ([]model.Zone, error) {
+	if mistMapID != "" {
+		return s.zoneRepo.FindActiveByMistMapID(ctx, mistMapID)
+	}
+	return s.zoneRepo.FindActive(ctx)
+}
+
+// GetStoredByMistZoneID 同期済みのゾーンをMistゾーンIDで取得
+func (s *ZoneService) GetStoredByMistZoneID(ctx context.Context, mistZoneID string) (*model.Zone, error) {
+	zone, err := s.zoneRepo.FindByMistZoneID(ctx, mistZoneID)
+	if err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
 // GetAll 全ゾーンを取得
-func (s *ZoneService) GetAll() ([]mistapi.MistZone, error) {
+func (s *ZoneService) GetAll(ctx context.Context) ([]mistapi.MistZone, error) {
 	if s.mistClient == nil {
 		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
 	}
 
 	// Mist APIからゾーン一覧を取得
-	mistZones, err := s.mistClient.GetZones(s.mistClient.SiteID)
+	mistZones, err := s.mistClient.GetZones(ctx, s.mistClient.SiteID)
 	if err != nil {
 		return nil, fmt.Errorf("mist APIゾーン取得エラー: %w", err)
 	}
@@ -34,32 +58,43 @@ func (s *ZoneService) GetAll() ([]mistapi.MistZone, error) {
 }
 
 // GetClientsByZoneID ゾーンIDでクライアント一覧を取得
-func (s *ZoneService) GetClientsByZoneID(zoneID string) (*ZoneClientsResponse, error) {
+func (s *ZoneService) GetClientsByZoneID(ctx context.Context, zoneID string) (*ZoneClientsResponse, error) {
 	if s.mistClient == nil {
 		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
 	}
 
-	sdkClientIDs, clientIDs, err := s.mistClient.GetZoneClients(s.mistClient.SiteID, zoneID)
+	sdkClientIDs, clientIDs, err := s.mistClient.GetZoneClients(ctx, s.mistClient.SiteID, zoneID)
 	if err != nil {
 		return nil, fmt.Errorf("mist APIゾーン取得エラー: %w", err)
 	}
 
-	var sdkClients []interface{}
+	var sdkClients []*mistapi.MistSDKClient
 	if len(sdkClientIDs) > 0 {
 		for _, id := range sdkClientIDs {
-			sc, err := s.mistClient.GetSDKClient(s.mistClient.SiteID, id)
+			sc, err := s.mistClient.GetSDKClient(ctx, s.mistClient.SiteID, id)
 			if err != nil {
+				// 取得までの間にクライアントが離脱した場合はスキップ
+				var notFoundErr *mistapi.NotFoundError
+				if errors.As(err, &notFoundErr) {
+					log.Printf("[ZoneService] SDKクライアントが見つかりません: %s", id)
+					continue
+				}
 				return nil, err
 			}
 			sdkClients = append(sdkClients, sc)
 		}
 	}
 
-	var wirelessClients []interface{}
+	var wirelessClients []*mistapi.MistWirelessClient
 	if len(clientIDs) > 0 {
 		for _, id := range clientIDs {
-			wc, err := s.mistClient.GetWirelessClient(s.mistClient.SiteID, id)
+			wc, err := s.mistClient.GetWirelessClient(ctx, s.mistClient.SiteID, id)
 			if err != nil {
+				var notFoundErr *mistapi.NotFoundError
+				if errors.As(err, &notFoundErr) {
+					log.Printf("[ZoneService] WiFiクライアントが見つかりません: %s", id)
+					continue
+				}
 				return nil, err
 			}
 			wirelessClients = append(wirelessClients, wc)
@@ -74,6 +109,92 @@ func (s *ZoneService) GetClientsByZoneID(zoneID string) (*ZoneClientsResponse, e
 
 // ZoneClientsResponse ゾーンのクライアントレスポンス
 type ZoneClientsResponse struct {
-	SDKClients []interface{} `json:"sdkclients"`
-	Clients    []interface{} `json:"clients"`
+	SDKClients []*mistapi.MistSDKClient      `json:"sdkclients"`
+	Clients    []*mistapi.MistWirelessClient `json:"clients"`
+}
+
+// ZoneOccupancy マップ上のゾーンごとの在室状況
+type ZoneOccupancy struct {
+	Counts    map[string]int     // MistゾーンID → 検知デバイス数
+	Positions []mistapi.Vertices // 検知デバイスの位置（SDKクライアント・BLEアセット）
+}
+
+// GetOccupancy マップ上の同期済みゾーンごとの在室数を取得
+// withPositionsの場合はSDKクライアントの位置も個別に取得する（クライアント数だけAPIを呼び出す）
+func (s *ZoneService) GetOccupancy(ctx context.Context, mistMapID string, withPositions bool) (*ZoneOccupancy, error) {
+	if s.mistClient == nil {
+		return nil, fmt.Errorf("mist APIクライアントが初期化されていません")
+	}
+
+	zones, err := s.zoneRepo.FindActiveByMistMapID(ctx, mistMapID)
+	if err != nil {
+		return nil, err
+	}
+	onMap := make(map[string]mistapi.MistZone, len(zones))
+	for _, zone := range zones {
+		onMap[zone.MistZoneID] = toMistZone(zone)
+	}
+
+	occupancy := &ZoneOccupancy{Counts: make(map[string]int, len(zones))}
+
+	// WiFi/SDKクライアント
+	stats, err := s.mistClient.GetZoneStats(ctx, s.mistClient.SiteID)
+	if err != nil {
+		return nil, fmt.Errorf("mist APIゾーン統計取得エラー: %w", err)
+	}
+	for _, stat := range stats {
+		if _, ok := onMap[stat.ID]; !ok {
+			continue
+		}
+		occupancy.Counts[stat.ID] += len(stat.SDKClients) + len(stat.Clients)
+
+		if !withPositions {
+			continue
+		}
+		for _, id := range stat.SDKClients {
+			sc, err := s.mistClient.GetSDKClient(ctx, s.mistClient.SiteID, id)
+			if err != nil {
+				var notFoundErr *mistapi.NotFoundError
+				if errors.As(err, &notFoundErr) {
+					continue
+				}
+				return nil, err
+			}
+			occupancy.Positions = append(occupancy.Positions, mistapi.Vertices{X: sc.X, Y: sc.Y})
+		}
+	}
+
+	// BLEアセット
+	devices, err := s.mistClient.GetBLEDevices(ctx, s.mistClient.SiteID, mistMapID)
+	if err != nil {
+		return nil, fmt.Errorf("mist API BLEデバイス取得エラー: %w", err)
+	}
+	for _, device := range devices {
+		inZone := false
+		for id, zone := range onMap {
+			if zone.Contains(device.X, device.Y) {
+				occupancy.Counts[id]++
+				inZone = true
+			}
+		}
+		if withPositions && inZone {
+			occupancy.Positions = append(occupancy.Positions, mistapi.Vertices{X: device.X, Y: device.Y})
+		}
+	}
+
+	return occupancy, nil
+}
+
+// toMistZone 同期済みのゾーンをMist APIのゾーン定義に変換
+func toMistZone(zone model.Zone) mistapi.MistZone {
+	vertices := make([]mistapi.Vertices, 0, len(zone.Vertices))
+	for _, v := range zone.Vertices {
+		vertices = append(vertices, mistapi.Vertices{X: v.X, Y: v.Y})
+	}
+	return mistapi.MistZone{
+		ID:       zone.MistZoneID,
+		Name:     zone.Name,
+		MapID:    zone.MistMapID,
+		Vertices: vertices,
+	}
 }
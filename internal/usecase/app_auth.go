@@ -3,30 +3,95 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/devicekey"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/jwt"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/mailer"
 )
 
 var (
 	ErrorAlreadyRegistered    = errors.New("すでに登録済みです")
 	ErrorUserNotFound         = errors.New("ユーザーが見つかりません")
 	ErrorOrganizationNotFound = errors.New("組織が見つかりません")
+	ErrorDeviceMismatch       = errors.New("このデバイスに発行されたトークンではありません")
+	ErrorInvalidPublicKey     = errors.New("公開鍵が不正です（ECDSA P-256またはEd25519の公開鍵をbase64で指定してください）")
+	ErrorPublicKeyMissing     = errors.New("デバイスの公開鍵が登録されていません。デバイスを登録し直してください")
+	ErrorInvalidChallenge     = errors.New("チャレンジが無効か有効期限切れです")
+	ErrorInvalidSignature     = errors.New("署名を検証できませんでした")
+	ErrorDeviceInUse          = errors.New("このデバイスは別のユーザーに登録されています。管理者に登録の解除を依頼してください")
+	ErrorCodeRecentlySent     = errors.New("確認コードを送信したばかりです。しばらくしてから再度お試しください")
+	ErrorInvalidRegistration  = errors.New("登録手続きが無効か有効期限切れです。最初からやり直してください")
+	ErrorInvalidCode          = errors.New("確認コードが一致しません")
+	ErrorTooManyAttempts      = errors.New("確認コードの入力回数の上限に達しました。最初からやり直してください")
+	ErrorDeviceInactive       = errors.New("デバイスがアクティベーションされていません。生体認証でアクティベーションしてください")
 )
 
+// challengeTTL アクティベーションのチャレンジの有効期間
+const challengeTTL = 2 * time.Minute
+
+// デバイス登録の確認コード
+const (
+	registrationCodeTTL        = 10 * time.Minute // 有効期間
+	registrationMaxAttempts    = 5                // 入力回数の上限
+	registrationResendInterval = time.Minute      // 再送信できるまでの間隔
+)
+
+// appTokenIssuer アプリのセッショントークンの発行者（管理APIのアクセストークンと区別する）
+const appTokenIssuer = "ed-mist-backend/app"
+
+// AppSessionConfig アプリのセッションの設定
+type AppSessionConfig struct {
+	Secret []byte        // セッショントークンの署名鍵
+	TTL    time.Duration // セッションの有効期間
+}
+
+// AppIdentity 認証済みのアプリのユーザー
+type AppIdentity struct {
+	SessionID    string
+	UserID       string
+	OrgID        string
+	DeviceID     string // devicesのID
+	DeviceActive bool   // デバイスが本日アクティベーション済みか
+}
+
 // AppAuthUsecase アプリ認証ユースケース
 type AppAuthUsecase struct {
 	userService         *service.UserService
 	deviceService       *service.DeviceService
 	organizationService *service.OrganizationService
+	sessionService      *service.AppSessionService
+	challengeService    *service.DeviceChallengeService
+	registrationService *service.DeviceRegistrationService
+	mailSender          mailer.Sender
+	config              AppSessionConfig
 }
 
 // NewAppAuthUsecase アプリ認証ユースケースを作成
-func NewAppAuthUsecase(userService *service.UserService, deviceService *service.DeviceService, organizationService *service.OrganizationService) *AppAuthUsecase {
+func NewAppAuthUsecase(
+	userService *service.UserService,
+	deviceService *service.DeviceService,
+	organizationService *service.OrganizationService,
+	sessionService *service.AppSessionService,
+	challengeService *service.DeviceChallengeService,
+	registrationService *service.DeviceRegistrationService,
+	mailSender mailer.Sender,
+	config AppSessionConfig,
+) *AppAuthUsecase {
 	return &AppAuthUsecase{
 		userService:         userService,
 		deviceService:       deviceService,
 		organizationService: organizationService,
+		sessionService:      sessionService,
+		challengeService:    challengeService,
+		registrationService: registrationService,
+		mailSender:          mailSender,
+		config:              config,
 	}
 }
 
@@ -35,12 +100,51 @@ type DeviceRegisterRequest struct {
 	OrgID    *string `json:"org_id"` // オプショナル：複数組織がある場合に指定
 	Mail     string  `json:"mail" validate:"required,email"`
 	DeviceID string  `json:"device_id" validate:"required"`
+
+	// デバイスで生成した鍵ペアの公開鍵（SubjectPublicKeyInfoのDERをbase64にしたもの。ECDSA P-256またはEd25519）
+	// アクティベーションではチャレンジのnonceをこの鍵の秘密鍵で署名する
+	PublicKey string `json:"public_key" validate:"required"`
+}
+
+// DeviceRegisterPendingResponse 確認コードを送信したデバイス登録のレスポンス
+type DeviceRegisterPendingResponse struct {
+	RegistrationID string    `json:"registration_id"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Message        string    `json:"message"`
+}
+
+// DeviceRegisterVerifyRequest デバイス登録の確認コード検証リクエスト
+type DeviceRegisterVerifyRequest struct {
+	RegistrationID string `json:"registration_id" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// DeviceChallengeResponse アクティベーションのチャレンジ
+// nonceの文字列（UTF-8）をデバイスの秘密鍵で署名し、有効期限内にDeviceActivateRequestで送る
+type DeviceChallengeResponse struct {
+	ChallengeID string    `json:"challenge_id"`
+	Nonce       string    `json:"nonce"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// DeviceActivateRequest デバイスアクティベーションリクエスト
+type DeviceActivateRequest struct {
+	ChallengeID string `json:"challenge_id" validate:"required"`
+	Signature   string `json:"signature" validate:"required"` // base64（ECDSAはSHA-256に対するASN.1 DERの署名）
 }
 
 // DeviceRegisterResponse デバイス登録レスポンス
 type DeviceRegisterResponse struct {
-	User   *model.User   `json:"user,omitempty"`
-	Device *model.Device `json:"device,omitempty"`
+	User    *model.User      `json:"user,omitempty"`
+	Device  *model.Device    `json:"device,omitempty"`
+	Session *AppSessionToken `json:"session,omitempty"`
+}
+
+// AppSessionToken アプリのセッショントークン（Authorizationヘッダーに指定し、X-Device-IDヘッダーで登録したデバイスIDを送る）
+type AppSessionToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // OrganizationChoice 組織選択情報
@@ -57,8 +161,14 @@ type MultipleOrganizationsResponse struct {
 	RequiresOrgID bool                 `json:"requires_org_id"`
 }
 
-// DeviceRegister デバイス登録（管理画面で作成済みのユーザーが前提）
+// DeviceRegister デバイス登録の開始（管理画面で作成済みのユーザーが前提）
+// ユーザーのメールアドレスに確認コードを送信し、VerifyDeviceRegisterで検証してからデバイスを作成する
 func (u *AppAuthUsecase) DeviceRegister(ctx context.Context, req *DeviceRegisterRequest) (interface{}, error) {
+	// 公開鍵の形式を確認
+	if _, err := devicekey.ParsePublicKey(req.PublicKey); err != nil {
+		return nil, ErrorInvalidPublicKey
+	}
+
 	// メールアドレスで全ユーザーを取得
 	users, err := u.userService.GetAllByMail(ctx, req.Mail)
 	if err != nil {
@@ -74,34 +184,16 @@ func (u *AppAuthUsecase) DeviceRegister(ctx context.Context, req *DeviceRegister
 	if req.OrgID != nil && *req.OrgID != "" {
 		for _, user := range users {
 			if user.OrgID == *req.OrgID {
-				// デバイス登録処理
-				device, err := u.registerDevice(ctx, user.ID, req.DeviceID)
-				if err != nil {
-					return nil, err
-				}
-
-				return &DeviceRegisterResponse{
-					User:   &user,
-					Device: device,
-				}, nil
+				return u.startRegistration(ctx, &user, req)
 			}
 		}
 		// 指定された組織IDのユーザーが見つからない
 		return nil, ErrorUserNotFound
 	}
 
-	// 1つだけ見つかった場合は自動的にデバイス登録
+	// 1つだけ見つかった場合は自動的にデバイス登録を開始
 	if len(users) == 1 {
-		user := users[0]
-		device, err := u.registerDevice(ctx, user.ID, req.DeviceID)
-		if err != nil {
-			return nil, err
-		}
-
-		return &DeviceRegisterResponse{
-			User:   &user,
-			Device: device,
-		}, nil
+		return u.startRegistration(ctx, &users[0], req)
 	}
 
 	// 複数見つかった場合は組織選択を促す
@@ -121,8 +213,152 @@ func (u *AppAuthUsecase) DeviceRegister(ctx context.Context, req *DeviceRegister
 	}, nil
 }
 
-// registerDevice デバイス登録
-func (u *AppAuthUsecase) registerDevice(ctx context.Context, userID, deviceID string) (*model.Device, error) {
+// startRegistration 確認コードを発行してユーザーのメールアドレスに送信
+func (u *AppAuthUsecase) startRegistration(ctx context.Context, user *model.User, req *DeviceRegisterRequest) (*DeviceRegisterPendingResponse, error) {
+	// 登録できないデバイスにはコードを送らない
+	if existing, err := u.deviceService.GetByDeviceID(ctx, req.DeviceID); err == nil {
+		if existing.UserID != user.ID {
+			return nil, ErrorDeviceInUse
+		}
+		if existing.IsActive {
+			return nil, ErrorAlreadyRegistered
+		}
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	// 確認コードの連続送信を防ぐ
+	recentlySent, err := u.registrationService.IssuedWithin(ctx, user.ID, registrationResendInterval)
+	if err != nil {
+		return nil, err
+	}
+	if recentlySent {
+		return nil, ErrorCodeRecentlySent
+	}
+
+	// 有効期限切れのデバイス登録を掃除する
+	if err := u.registrationService.DeleteExpired(ctx); err != nil {
+		log.Printf("[AppAuthUsecase] 期限切れデバイス登録の削除エラー: %v", err)
+	}
+
+	code, registration, err := u.registrationService.Issue(ctx, user.ID, req.DeviceID, req.PublicKey, registrationCodeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	message := mailer.Message{
+		To:      user.Mail,
+		Subject: "【ED Mist】デバイス登録の確認コード",
+		Body: fmt.Sprintf("デバイス登録の確認コードは %s です。\n\n%d分以内にアプリで入力してください。\nこのメールに心当たりがない場合は、このメールを破棄してください。\n",
+			code, int(registrationCodeTTL.Minutes())),
+	}
+	if err := u.mailSender.Send(ctx, message); err != nil {
+		return nil, err
+	}
+	log.Printf("[AppAuthUsecase] デバイス登録の確認コードを送信しました: User=%s, Registration=%s", user.ID, registration.ID)
+
+	return &DeviceRegisterPendingResponse{
+		RegistrationID: registration.ID,
+		ExpiresAt:      registration.ExpiresAt,
+		Message:        "登録されているメールアドレスに確認コードを送信しました",
+	}, nil
+}
+
+// VerifyDeviceRegister 確認コードを検証してデバイスを登録し、セッショントークンを発行
+func (u *AppAuthUsecase) VerifyDeviceRegister(ctx context.Context, req *DeviceRegisterVerifyRequest) (*DeviceRegisterResponse, error) {
+	registration, err := u.registrationService.GetByID(ctx, req.RegistrationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidRegistration
+		}
+		return nil, err
+	}
+	if err := checkRegistration(registration, time.Now()); err != nil {
+		return nil, err
+	}
+
+	recorded, err := u.registrationService.RecordAttempt(ctx, registration, registrationMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRegistrationCode(registration, recorded, u.registrationService.CodeMatches(registration, req.Code)); err != nil {
+		log.Printf("[AppAuthUsecase] 確認コードの検証に失敗しました: Registration=%s, 入力回数=%d, Error=%v", registration.ID, registration.Attempts, err)
+		return nil, err
+	}
+
+	consumed, err := u.registrationService.Consume(ctx, registration)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		// 同じ確認コードが同時に使用された
+		return nil, ErrorInvalidRegistration
+	}
+
+	user, err := u.userService.GetByID(ctx, registration.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidRegistration
+		}
+		return nil, err
+	}
+	return u.registerAndIssue(ctx, user, registration.DeviceID, registration.PublicKey)
+}
+
+// checkRegistration 確認コードを入力できるデバイス登録か（使用済み・期限切れのデバイス登録は使えない）
+func checkRegistration(registration *model.DeviceRegistration, now time.Time) error {
+	if !registration.IsPending(now) {
+		return ErrorInvalidRegistration
+	}
+	return nil
+}
+
+// checkRegistrationCode 入力回数を記録した後に確認コードを判定
+// recordedは入力回数を記録できたか（上限に達していた場合はfalse）。上限回数目の入力で一致しなければ以降は受け付けない
+func checkRegistrationCode(registration *model.DeviceRegistration, recorded, matches bool) error {
+	if !recorded {
+		return ErrorTooManyAttempts
+	}
+	if !matches {
+		if registration.Attempts >= registrationMaxAttempts {
+			return ErrorTooManyAttempts
+		}
+		return ErrorInvalidCode
+	}
+	return nil
+}
+
+// registerAndIssue デバイスを登録してセッショントークンを発行
+func (u *AppAuthUsecase) registerAndIssue(ctx context.Context, user *model.User, deviceID, publicKey string) (*DeviceRegisterResponse, error) {
+	device, err := u.registerDevice(ctx, user.ID, deviceID, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 登録し直した場合は以前のデバイス・鍵で発行したセッションをすべて失効させる
+	if _, err := u.sessionService.RevokeByUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	// 有効期限切れのセッションを掃除する
+	if err := u.sessionService.DeleteExpired(ctx); err != nil {
+		log.Printf("[AppAuthUsecase] 期限切れセッションの削除エラー: %v", err)
+	}
+
+	session, err := u.issueSession(ctx, user, device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceRegisterResponse{
+		User:    user,
+		Device:  device,
+		Session: session,
+	}, nil
+}
+
+// registerDevice デバイス登録（登録したデバイスはチャレンジの署名を検証するまで非アクティブ）
+func (u *AppAuthUsecase) registerDevice(ctx context.Context, userID, deviceID, publicKey string) (*model.Device, error) {
 	// ユーザーの既存デバイスを確認
 	devices, err := u.deviceService.GetByUserID(ctx, userID)
 	if err != nil {
@@ -135,20 +371,17 @@ func (u *AppAuthUsecase) registerDevice(ctx context.Context, userID, deviceID st
 			if device.IsActive {
 				return nil, ErrorAlreadyRegistered
 			}
-			// 非アクティブなデバイスを再アクティブ化
-			if err := u.deviceService.Activate(ctx, device.ID); err != nil {
+			// 非アクティブなデバイスは公開鍵を登録し直す
+			if err := u.deviceService.UpdatePublicKey(ctx, device.ID, publicKey); err != nil {
 				return nil, err
 			}
-			return &device, nil
+			return u.deviceService.GetByID(ctx, device.ID)
 		}
 	}
 
-	// 他のユーザーが同じデバイスIDを使用している場合は非アクティブ化
-	existingDevice, err := u.deviceService.GetByDeviceID(ctx, deviceID)
-	if err == nil && existingDevice != nil {
-		if err := u.deviceService.Deactivate(ctx, existingDevice.ID); err != nil {
-			return nil, err
-		}
+	// 他のユーザーが同じデバイスIDを使用している場合は登録できない（管理者が登録を解除する）
+	if existingDevice, err := u.deviceService.GetByDeviceID(ctx, deviceID); err == nil && existingDevice.UserID != userID {
+		return nil, ErrorDeviceInUse
 	}
 
 	// ユーザーの既存アクティブデバイスを非アクティブ化
@@ -159,16 +392,270 @@ func (u *AppAuthUsecase) registerDevice(ctx context.Context, userID, deviceID st
 	}
 
 	// 新しいデバイスを作成
-	device, err := u.deviceService.Create(ctx, userID, deviceID)
+	return u.deviceService.Create(ctx, userID, deviceID, publicKey)
+}
+
+// IssueChallenge 認証済みのデバイスにアクティベーションのチャレンジを発行
+func (u *AppAuthUsecase) IssueChallenge(ctx context.Context, identity *AppIdentity) (*DeviceChallengeResponse, error) {
+	device, err := u.deviceService.GetByID(ctx, identity.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.PublicKey == "" {
+		return nil, ErrorPublicKeyMissing
+	}
+
+	// 有効期限切れのチャレンジを掃除する
+	if err := u.challengeService.DeleteExpired(ctx); err != nil {
+		log.Printf("[AppAuthUsecase] 期限切れチャレンジの削除エラー: %v", err)
+	}
+
+	challenge, err := u.challengeService.Issue(ctx, device.ID, challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceChallengeResponse{
+		ChallengeID: challenge.ID,
+		Nonce:       challenge.Nonce,
+		ExpiresAt:   challenge.ExpiresAt,
+	}, nil
+}
+
+// ActivateDevice チャレンジの署名を検証してデバイスをアクティベーション
+// チャレンジは検証の成否に関わらず使用済みになる（署名の総当たりを防ぐ）
+func (u *AppAuthUsecase) ActivateDevice(ctx context.Context, identity *AppIdentity, req *DeviceActivateRequest) (*model.Device, error) {
+	challenge, err := u.challengeService.GetByID(ctx, req.ChallengeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidChallenge
+		}
+		return nil, err
+	}
+	if err := checkChallenge(challenge, identity.DeviceID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	consumed, err := u.challengeService.Consume(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		// 同じチャレンジが同時に使用された
+		return nil, ErrorInvalidChallenge
+	}
+
+	device, err := u.deviceService.GetByID(ctx, identity.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.PublicKey == "" {
+		return nil, ErrorPublicKeyMissing
+	}
+	if err := devicekey.Verify(device.PublicKey, []byte(challenge.Nonce), req.Signature); err != nil {
+		log.Printf("[AppAuthUsecase] 署名の検証に失敗しました: Device=%s, Error=%v", device.ID, err)
+		return nil, ErrorInvalidSignature
+	}
+
+	return u.deviceService.ActivateWithAuthentication(ctx, device.DeviceID)
+}
+
+// checkChallenge チャレンジがデバイスで使用できるか（使用済み・期限切れ・他のデバイスのチャレンジは使えない）
+func checkChallenge(challenge *model.DeviceChallenge, deviceID string, now time.Time) error {
+	if challenge.DeviceID != deviceID || !challenge.IsUsable(now) {
+		return ErrorInvalidChallenge
+	}
+	return nil
+}
+
+// Authenticate セッショントークンとデバイスIDを検証して認証済みのユーザーを取得
+func (u *AppAuthUsecase) Authenticate(ctx context.Context, token, deviceID string) (*AppIdentity, error) {
+	session, claims, err := u.parseSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, ErrorInvalidToken
+	}
+	device, err := u.verifyDevice(ctx, session, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppIdentity{
+		SessionID:    session.ID,
+		UserID:       session.UserID,
+		OrgID:        claims.OrgID,
+		DeviceID:     session.DeviceID,
+		DeviceActive: device.IsActive,
+	}, nil
+}
+
+// RotateSession セッショントークンを更新（使用したトークンは失効する）
+// 失効済みのトークンが使われた場合は漏洩とみなし、デバイスのセッションをすべて失効させる
+func (u *AppAuthUsecase) RotateSession(ctx context.Context, token, deviceID string) (*AppSessionToken, error) {
+	session, _, err := u.parseSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.RevokedAt != nil {
+		log.Printf("[AppAuthUsecase] 失効済みのセッショントークンが使用されました: User=%s, Device=%s", session.UserID, session.DeviceID)
+		if err := u.sessionService.RevokeByDevice(ctx, session.DeviceID); err != nil {
+			return nil, err
+		}
+		return nil, ErrorInvalidToken
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, ErrorInvalidToken
+	}
+	if _, err := u.verifyDevice(ctx, session, deviceID); err != nil {
+		return nil, err
+	}
+
+	revoked, err := u.sessionService.Revoke(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// 同じトークンで同時に更新された
+		return nil, ErrorInvalidToken
+	}
+
+	user, err := u.userService.GetByID(ctx, session.UserID)
 	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidToken
+		}
+		return nil, err
+	}
+	return u.issueSession(ctx, user, session.DeviceID)
+}
+
+// Logout 認証済みのセッションを失効させる
+func (u *AppAuthUsecase) Logout(ctx context.Context, identity *AppIdentity) error {
+	_, err := u.sessionService.Revoke(ctx, identity.SessionID)
+	return err
+}
+
+// RevokeUserSessions ユーザーのセッションをすべて失効させる（管理向け。失効させた件数を返す）
+func (u *AppAuthUsecase) RevokeUserSessions(ctx context.Context, orgID, userID string) (int64, error) {
+	// 組織の存在確認
+	if _, err := u.organizationService.GetByID(ctx, orgID); err != nil {
+		return 0, err
+	}
+
+	// ユーザーの存在確認
+	user, err := u.userService.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// ユーザーが指定された組織に属しているかチェック
+	if user.OrgID != orgID {
+		return 0, errors.New("指定されたユーザーは組織に属していません")
+	}
+
+	return u.sessionService.RevokeByUser(ctx, userID)
+}
+
+// RemoveUserDevices ユーザーのデバイスの登録をすべて解除（管理向け。セッションも失効する。解除した件数を返す）
+func (u *AppAuthUsecase) RemoveUserDevices(ctx context.Context, orgID, userID string) (int, error) {
+	// 組織の存在確認
+	if _, err := u.organizationService.GetByID(ctx, orgID); err != nil {
+		return 0, err
+	}
+
+	// ユーザーの存在確認
+	user, err := u.userService.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// ユーザーが指定された組織に属しているかチェック
+	if user.OrgID != orgID {
+		return 0, errors.New("指定されたユーザーは組織に属していません")
+	}
+
+	devices, err := u.deviceService.GetByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := u.sessionService.RevokeByUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	for _, device := range devices {
+		if err := u.deviceService.Delete(ctx, device.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(devices), nil
+}
+
+// parseSession セッショントークンの署名を検証してセッションを取得（失効・期限切れの確認は呼び出し側で行う）
+func (u *AppAuthUsecase) parseSession(ctx context.Context, token string) (*model.AppSession, *jwt.Claims, error) {
+	claims, err := jwt.Parse(token, u.config.Secret, time.Now())
+	if err != nil {
+		return nil, nil, ErrorInvalidToken
+	}
+	if claims.Issuer != appTokenIssuer || claims.ID == "" {
+		return nil, nil, ErrorInvalidToken
+	}
+
+	session, err := u.sessionService.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, nil, ErrorInvalidToken
+		}
+		return nil, nil, err
+	}
+	if session.UserID != claims.Subject {
+		return nil, nil, ErrorInvalidToken
+	}
+	return session, claims, nil
+}
+
+// verifyDevice リクエストのデバイスがセッションを発行したデバイスか
+func (u *AppAuthUsecase) verifyDevice(ctx context.Context, session *model.AppSession, deviceID string) (*model.Device, error) {
+	if deviceID == "" {
+		return nil, ErrorDeviceMismatch
+	}
+	device, err := u.deviceService.GetByDeviceID(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorDeviceMismatch
+		}
 		return nil, err
 	}
+	if device.ID != session.DeviceID || device.UserID != session.UserID {
+		return nil, ErrorDeviceMismatch
+	}
+	return device, nil
+}
 
-	// 新しいデバイスをアクティブ化
-	if err := u.deviceService.Activate(ctx, device.ID); err != nil {
+// issueSession ユーザーのデバイスにセッションを作成してトークンを発行
+func (u *AppAuthUsecase) issueSession(ctx context.Context, user *model.User, deviceID string) (*AppSessionToken, error) {
+	session, err := u.sessionService.Create(ctx, user.ID, deviceID, u.config.TTL)
+	if err != nil {
 		return nil, err
 	}
 
-	// アクティブ化されたデバイスを再取得
-	return u.deviceService.GetByID(ctx, device.ID)
+	claims := jwt.Claims{
+		Subject:   user.ID,
+		Issuer:    appTokenIssuer,
+		IssuedAt:  session.CreatedAt.Unix(),
+		ExpiresAt: session.ExpiresAt.Unix(),
+		ID:        session.ID,
+		OrgID:     user.OrgID,
+		UserID:    user.ID,
+	}
+	token, err := jwt.Sign(claims, u.config.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppSessionToken{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: session.ExpiresAt,
+	}, nil
 }
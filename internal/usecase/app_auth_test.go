@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/devicekey"
+)
+
+func TestCheckRegistration(t *testing.T) {
+	now := time.Now()
+	consumedAt := now.Add(-time.Second)
+
+	tests := []struct {
+		name         string
+		registration *model.DeviceRegistration
+		want         error
+	}{
+		{
+			name:         "入力待ち",
+			registration: &model.DeviceRegistration{ExpiresAt: now.Add(registrationCodeTTL)},
+		},
+		{
+			name:         "有効期限切れのコード",
+			registration: &model.DeviceRegistration{ExpiresAt: now.Add(-time.Second)},
+			want:         ErrorInvalidRegistration,
+		},
+		{
+			name:         "有効期限ちょうど",
+			registration: &model.DeviceRegistration{ExpiresAt: now},
+			want:         ErrorInvalidRegistration,
+		},
+		{
+			name:         "使用済みのコードの再利用",
+			registration: &model.DeviceRegistration{ExpiresAt: now.Add(registrationCodeTTL), ConsumedAt: &consumedAt},
+			want:         ErrorInvalidRegistration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRegistration(tt.registration, now); !errors.Is(err, tt.want) {
+				t.Errorf("checkRegistration() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRegistrationCodeAttemptLimit(t *testing.T) {
+	registration := &model.DeviceRegistration{ID: "registration-1"}
+
+	// 誤ったコードを続けて入力し、上限に達した後は正しいコードも受け付けないことを確認
+	for attempt := 1; attempt <= registrationMaxAttempts+1; attempt++ {
+		// DeviceRegistrationRepository.IncrementAttemptsと同じく上限未満の場合のみ記録される
+		recorded := registration.Attempts < registrationMaxAttempts
+		if recorded {
+			registration.Attempts++
+		}
+		matches := attempt > registrationMaxAttempts
+
+		want := ErrorInvalidCode
+		if attempt >= registrationMaxAttempts {
+			want = ErrorTooManyAttempts
+		}
+		if err := checkRegistrationCode(registration, recorded, matches); !errors.Is(err, want) {
+			t.Errorf("%d回目の checkRegistrationCode() error = %v, want %v", attempt, err, want)
+		}
+	}
+	if registration.Attempts != registrationMaxAttempts {
+		t.Errorf("Attempts = %d, want %d", registration.Attempts, registrationMaxAttempts)
+	}
+
+	// 上限回数目でも一致すれば受け付ける
+	last := &model.DeviceRegistration{ID: "registration-2", Attempts: registrationMaxAttempts}
+	if err := checkRegistrationCode(last, true, true); err != nil {
+		t.Errorf("checkRegistrationCode() error = %v, want nil", err)
+	}
+}
+
+func TestCheckChallengeRejectsReplay(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	publicKey := base64.StdEncoding.EncodeToString(der)
+
+	now := time.Now()
+	usedAt := now.Add(-time.Second)
+	newChallenge := func(modify func(*model.DeviceChallenge)) *model.DeviceChallenge {
+		challenge := &model.DeviceChallenge{
+			ID:        "challenge-1",
+			DeviceID:  "device-1",
+			Nonce:     "nonce-0123456789",
+			ExpiresAt: now.Add(challengeTTL),
+			CreatedAt: now,
+		}
+		if modify != nil {
+			modify(challenge)
+		}
+		return challenge
+	}
+
+	tests := []struct {
+		name      string
+		challenge *model.DeviceChallenge
+		now       time.Time
+		want      error
+	}{
+		{
+			name:      "未使用で有効期限内",
+			challenge: newChallenge(nil),
+			now:       now,
+		},
+		{
+			name:      "使用済みのチャレンジの再利用",
+			challenge: newChallenge(func(c *model.DeviceChallenge) { c.UsedAt = &usedAt }),
+			now:       now,
+			want:      ErrorInvalidChallenge,
+		},
+		{
+			name:      "有効期限切れ",
+			challenge: newChallenge(nil),
+			now:       now.Add(challengeTTL),
+			want:      ErrorInvalidChallenge,
+		},
+		{
+			name:      "他のデバイスのチャレンジ",
+			challenge: newChallenge(func(c *model.DeviceChallenge) { c.DeviceID = "device-2" }),
+			now:       now,
+			want:      ErrorInvalidChallenge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 署名は正しくても、使用できないチャレンジでは署名の検証まで進まない
+			signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(tt.challenge.Nonce)))
+			if err := devicekey.Verify(publicKey, []byte(tt.challenge.Nonce), signature); err != nil {
+				t.Fatalf("devicekey.Verify() error = %v", err)
+			}
+			if err := checkChallenge(tt.challenge, "device-1", tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("checkChallenge() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -12,18 +12,23 @@ import (
 type AttendanceStatus string
 
 const (
-	AttendanceOnTime   AttendanceStatus = "on_time"   // 定刻
-	AttendanceLate     AttendanceStatus = "late"      // 遅刻
-	AttendanceVeryLate AttendanceStatus = "very_late" // 大幅遅刻
-	AttendanceAbsent   AttendanceStatus = "absent"    // 欠席
-	AttendanceUnknown  AttendanceStatus = "unknown"   // 不明
+	AttendanceOnTime     AttendanceStatus = "on_time"     // 定刻
+	AttendanceLate       AttendanceStatus = "late"        // 遅刻
+	AttendanceVeryLate   AttendanceStatus = "very_late"   // 大幅遅刻
+	AttendanceEarlyLeave AttendanceStatus = "early_leave" // 早退
+	AttendanceAbsent     AttendanceStatus = "absent"      // 欠席
+	AttendanceExcused    AttendanceStatus = "excused"     // 公欠（教員が記録した届出のある欠席）
+	AttendanceUnknown    AttendanceStatus = "unknown"     // 不明
 )
 
 // AttendanceConfig 出席管理設定
 type AttendanceConfig struct {
-	LateThresholdMinutes int  `json:"late_threshold_minutes"` // 遅刻許容時間（分）デフォルト: 10
-	EarlyEntryMinutes    int  `json:"early_entry_minutes"`    // 授業前何分から入室可能か デフォルト: 10
-	AutoCheckoutEnabled  bool `json:"auto_checkout_enabled"`  // 自動退出を有効にするか デフォルト: true
+	LateThresholdMinutes int     `json:"late_threshold_minutes"` // 遅刻許容時間（分）デフォルト: 10
+	EarlyEntryMinutes    int     `json:"early_entry_minutes"`    // 授業前何分から入室可能か デフォルト: 10
+	AutoCheckoutEnabled  bool    `json:"auto_checkout_enabled"`  // 自動退出を有効にするか デフォルト: true
+	MinPresenceRatio     float64 `json:"min_presence_ratio"`     // 出席とみなす授業時間中の最低在室割合（0〜1）デフォルト: 0.5
+	AbsenceGraceMinutes  int     `json:"absence_grace_minutes"`  // 検知されなくなってから退室とみなすまでの時間（分）デフォルト: 5
+	EarlyLeaveMinutes    int     `json:"early_leave_minutes"`    // 授業終了の何分以上前の退室を早退とするか デフォルト: 10
 }
 
 // DefaultAttendanceConfig デフォルトの出席管理設定
@@ -32,6 +37,9 @@ func DefaultAttendanceConfig() AttendanceConfig {
 		LateThresholdMinutes: 10,
 		EarlyEntryMinutes:    10,
 		AutoCheckoutEnabled:  true,
+		MinPresenceRatio:     0.5,
+		AbsenceGraceMinutes:  5,
+		EarlyLeaveMinutes:    10,
 	}
 }
 
@@ -45,12 +53,17 @@ type StayWithAttendance struct {
 
 // AttendanceRecord 出席記録（API レスポンス用）
 type AttendanceRecord struct {
-	Lesson           *model.Lesson    `json:"lesson"`
+	Lesson           *model.Lesson    `json:"lesson"` // 開始・終了時刻は実施日の時刻
+	OccurrenceID     string           `json:"occurrence_id"`
+	Date             time.Time        `json:"date"`
 	AttendanceStatus AttendanceStatus `json:"attendance_status"`
 	LateMinutes      int              `json:"late_minutes"`
 	OnTime           bool             `json:"on_time"`
 	EntryTime        *time.Time       `json:"entry_time,omitempty"`
 	ExitTime         *time.Time       `json:"exit_time,omitempty"`
+	DwellMinutes     int              `json:"dwell_minutes"`
+	Marked           bool             `json:"marked"`           // 教員が手動で記録した出席か
+	Reason           string           `json:"reason,omitempty"` // 手動記録の理由
 }
 
 // AttendanceSummary 出席サマリー
@@ -58,43 +71,82 @@ type AttendanceSummary struct {
 	TotalLessons   int     `json:"total_lessons"`
 	OnTime         int     `json:"on_time"`
 	Late           int     `json:"late"`
+	EarlyLeave     int     `json:"early_leave"`
 	Absent         int     `json:"absent"`
+	Excused        int     `json:"excused"` // 公欠（出席率の計算から除く）
 	AttendanceRate float64 `json:"attendance_rate"`
+	Closed         bool    `json:"closed"`                  // 休日・学期外で授業がない日か
+	ClosedReason   string  `json:"closed_reason,omitempty"` // 授業がない理由
 }
 
 // AttendanceUsecase 出席判定ユースケース
 type AttendanceUsecase struct {
-	lessonService *service.LessonService
-	stayService   *service.StayService
-	userService   *service.UserService
+	occurrenceService *service.LessonOccurrenceService
+	stayService       *service.StayService
+	userService       *service.UserService
+	markService       *service.AttendanceMarkService
 }
 
 // NewAttendanceUsecase 出席判定ユースケースを作成
 func NewAttendanceUsecase(
-	lessonService *service.LessonService,
+	occurrenceService *service.LessonOccurrenceService,
 	stayService *service.StayService,
 	userService *service.UserService,
+	markService *service.AttendanceMarkService,
 ) *AttendanceUsecase {
 	return &AttendanceUsecase{
-		lessonService: lessonService,
-		stayService:   stayService,
-		userService:   userService,
+		occurrenceService: occurrenceService,
+		stayService:       stayService,
+		userService:       userService,
+		markService:       markService,
 	}
 }
 
-// CalculateAttendanceStatus 出席ステータスを計算
-func CalculateAttendanceStatus(stay model.Stay, lesson *model.Lesson, config AttendanceConfig) AttendanceStatus {
+// lessonWindow 滞在が対象とする授業実施回の開始・終了時刻
+// 実施回が紐付いている場合はその時刻、なければ授業の時刻を滞在の実施日に展開して使用する
+func lessonWindow(stay model.Stay, lesson *model.Lesson) (time.Time, time.Time, bool) {
+	if stay.Occurrence != nil {
+		return stay.Occurrence.StartAt, stay.Occurrence.EndAt, true
+	}
+
 	// Lessonがstayに紐付いている場合はそれを使用、なければ引数のlessonを使用
 	targetLesson := stay.Lesson
 	if targetLesson == nil {
 		targetLesson = lesson
 	}
-
 	if targetLesson == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	date := model.LessonDateOf(stay.CreatedAt)
+	if stay.LessonDate != nil {
+		// date型は0時（UTC）で読み込まれるため、暦日のみを使用する
+		y, m, d := stay.LessonDate.Date()
+		date = time.Date(y, m, d, 0, 0, 0, 0, stay.CreatedAt.Location())
+	}
+	startTime, endTime := targetLesson.TimesOn(date)
+	return startTime, endTime, true
+}
+
+// CalculateAttendanceStatus 出席ステータスを計算
+func CalculateAttendanceStatus(stay model.Stay, lesson *model.Lesson, config AttendanceConfig) AttendanceStatus {
+	startTime, endTime, ok := lessonWindow(stay, lesson)
+	if !ok {
 		return AttendanceUnknown // Lessonがない
 	}
 
-	diff := stay.CreatedAt.Sub(targetLesson.StartTime)
+	// 滞在時間が不足している場合は入室していても欠席
+	if stay.Attended != nil && !*stay.Attended {
+		return AttendanceAbsent
+	}
+
+	// 授業終了より一定時間以上前に退室した場合は早退
+	if stay.LeavedAt != nil &&
+		stay.LeavedAt.Before(endTime.Add(-time.Duration(config.EarlyLeaveMinutes)*time.Minute)) {
+		return AttendanceEarlyLeave
+	}
+
+	diff := stay.CreatedAt.Sub(startTime)
 
 	if diff <= 0 {
 		return AttendanceOnTime // 定刻または早め
@@ -111,17 +163,12 @@ func CalculateAttendanceStatus(stay model.Stay, lesson *model.Lesson, config Att
 
 // CalculateLateMinutes 遅刻時間を計算
 func CalculateLateMinutes(stay model.Stay, lesson *model.Lesson) int {
-	// Lessonがstayに紐付いている場合はそれを使用、なければ引数のlessonを使用
-	targetLesson := stay.Lesson
-	if targetLesson == nil {
-		targetLesson = lesson
-	}
-
-	if targetLesson == nil {
+	startTime, _, ok := lessonWindow(stay, lesson)
+	if !ok {
 		return 0
 	}
 
-	diff := stay.CreatedAt.Sub(targetLesson.StartTime)
+	diff := stay.CreatedAt.Sub(startTime)
 	if diff <= 0 {
 		return 0
 	}
@@ -138,7 +185,7 @@ func EnrichStayWithAttendance(stay model.Stay, lesson *model.Lesson, config Atte
 		Stay:             stay,
 		AttendanceStatus: status,
 		LateMinutes:      lateMinutes,
-		OnTime:           lateMinutes == 0,
+		OnTime:           lateMinutes == 0 && status != AttendanceAbsent,
 	}
 }
 
@@ -150,24 +197,139 @@ func (u *AttendanceUsecase) GetTodayAttendance(ctx context.Context, userID strin
 		return nil, nil, err
 	}
 
-	// 今日の授業一覧を取得
-	lessons, err := u.lessonService.GetByUserAndDate(ctx, userID, date)
+	// カレンダー上の授業実施状況を取得
+	schoolDay, err := u.occurrenceService.GetSchoolDayByUser(ctx, userID, date)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 今日の授業（実施回）一覧を取得（休講の実施回は含まれないため欠席にならない）
+	occurrences, err := u.occurrenceService.GetByUserAndDate(ctx, userID, date)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	return u.buildAttendance(ctx, user, schoolDay, occurrences, u.stayService.GetByLessonAndDate, u.markService.GetByLessonAndDate)
+}
+
+// MemberAttendance ユーザーごとの出席状況
+type MemberAttendance struct {
+	User    model.User         `json:"user"`
+	Records []AttendanceRecord `json:"records"`
+	Summary *AttendanceSummary `json:"summary"`
+}
+
+// GetAttendanceByUsers 組織の複数ユーザーの指定日の出席状況を取得
+// 実施回と授業ごとの滞在ログ・手動記録は1回だけ取得し、ユーザーごとに対象の授業を絞り込んで判定する
+func (u *AttendanceUsecase) GetAttendanceByUsers(ctx context.Context, orgID string, users []model.User, date time.Time) ([]MemberAttendance, error) {
+	schoolDay, err := u.occurrenceService.GetSchoolDay(ctx, orgID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := u.occurrenceService.GetByDate(ctx, orgID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授業ごとの滞在ログ（ユーザー間で共有）
+	staysByLesson := make(map[string][]model.Stay)
+	staysFor := func(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.Stay, error) {
+		if stays, ok := staysByLesson[lessonID]; ok {
+			return stays, nil
+		}
+		stays, err := u.stayService.GetByLessonAndDate(ctx, lessonID, lessonDate)
+		if err != nil {
+			return nil, err
+		}
+		staysByLesson[lessonID] = stays
+		return stays, nil
+	}
+
+	// 授業ごとの手動記録（ユーザー間で共有）
+	marksByLesson := make(map[string][]model.AttendanceMark)
+	marksFor := func(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.AttendanceMark, error) {
+		if marks, ok := marksByLesson[lessonID]; ok {
+			return marks, nil
+		}
+		marks, err := u.markService.GetByLessonAndDate(ctx, lessonID, lessonDate)
+		if err != nil {
+			return nil, err
+		}
+		marksByLesson[lessonID] = marks
+		return marks, nil
+	}
+
+	attendances := make([]MemberAttendance, 0, len(users))
+	for i := range users {
+		user := &users[i]
+		userOccurrences, err := u.occurrenceService.FilterForUser(ctx, user, occurrences)
+		if err != nil {
+			return nil, err
+		}
+		records, summary, err := u.buildAttendance(ctx, user, schoolDay, userOccurrences, staysFor, marksFor)
+		if err != nil {
+			return nil, err
+		}
+		attendances = append(attendances, MemberAttendance{User: *user, Records: records, Summary: summary})
+	}
+	return attendances, nil
+}
+
+// buildAttendance 実施回ごとの滞在ログからユーザーの出席記録とサマリーを作成
+// 教員による手動記録がある実施回は滞在ログより手動記録を優先する
+func (u *AttendanceUsecase) buildAttendance(
+	ctx context.Context,
+	user *model.User,
+	schoolDay *model.SchoolDay,
+	occurrences []model.LessonOccurrence,
+	staysFor func(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.Stay, error),
+	marksFor func(ctx context.Context, lessonID string, lessonDate time.Time) ([]model.AttendanceMark, error),
+) ([]AttendanceRecord, *AttendanceSummary, error) {
 	// デフォルト設定を使用（将来的には組織の設定を取得）
 	config := DefaultAttendanceConfig()
 
 	records := []AttendanceRecord{}
 	summary := &AttendanceSummary{
-		TotalLessons: len(lessons),
+		TotalLessons: len(occurrences),
+		Closed:       !schoolDay.Open,
+	}
+	if !schoolDay.Open {
+		summary.ClosedReason = schoolDay.Reason
 	}
 
-	for _, lesson := range lessons {
-		// この授業の滞在ログを検索
-		// LessonIDで検索 + 手動入室も含める（同じ時間帯・同じ部屋）
-		stays, err := u.stayService.GetByLessonID(ctx, lesson.ID)
+	for _, occurrence := range occurrences {
+		lesson := occurrence.LessonOnDate()
+
+		// 教員による手動記録を検索
+		marks, err := marksFor(ctx, lesson.ID, occurrence.Date)
+		if err != nil {
+			return nil, nil, err
+		}
+		var userMark *model.AttendanceMark
+		for i := range marks {
+			if marks[i].UserID == user.ID {
+				userMark = &marks[i]
+				break
+			}
+		}
+		if userMark != nil && userMark.Status == model.AttendanceMarkExcused {
+			// 公欠
+			records = append(records, AttendanceRecord{
+				Lesson:           &lesson,
+				OccurrenceID:     occurrence.ID,
+				Date:             occurrence.Date,
+				AttendanceStatus: AttendanceExcused,
+				Marked:           true,
+				Reason:           userMark.Reason,
+			})
+			summary.Excused++
+			continue
+		}
+
+		// この実施回の滞在ログを検索
+		// LessonIDと実施日で検索 + 手動入室も含める（同じ時間帯・同じ部屋）
+		stays, err := staysFor(ctx, lesson.ID, occurrence.Date)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -198,10 +360,24 @@ func (u *AttendanceUsecase) GetTodayAttendance(ctx context.Context, userID strin
 			}
 		}
 
-		if userStay == nil {
+		if userStay == nil && userMark != nil {
+			// 教員が出席扱いにした（検知されなかった場合など）
+			records = append(records, AttendanceRecord{
+				Lesson:           &lesson,
+				OccurrenceID:     occurrence.ID,
+				Date:             occurrence.Date,
+				AttendanceStatus: AttendanceOnTime,
+				OnTime:           true,
+				Marked:           true,
+				Reason:           userMark.Reason,
+			})
+			summary.OnTime++
+		} else if userStay == nil {
 			// 欠席
 			records = append(records, AttendanceRecord{
 				Lesson:           &lesson,
+				OccurrenceID:     occurrence.ID,
+				Date:             occurrence.Date,
 				AttendanceStatus: AttendanceAbsent,
 				LateMinutes:      0,
 				OnTime:           false,
@@ -214,6 +390,18 @@ func (u *AttendanceUsecase) GetTodayAttendance(ctx context.Context, userID strin
 			status := CalculateAttendanceStatus(*userStay, &lesson, config)
 			lateMinutes := CalculateLateMinutes(*userStay, &lesson)
 
+			// 教員が出席扱いにした場合は滞在時間不足による欠席を出席に改める
+			reason := ""
+			if userMark != nil {
+				reason = userMark.Reason
+				if status == AttendanceAbsent {
+					status = AttendanceOnTime
+					if lateMinutes > 0 {
+						status = AttendanceLate
+					}
+				}
+			}
+
 			var exitTime *time.Time
 			if userStay.LeavedAt != nil {
 				exitTime = userStay.LeavedAt
@@ -221,11 +409,16 @@ func (u *AttendanceUsecase) GetTodayAttendance(ctx context.Context, userID strin
 
 			records = append(records, AttendanceRecord{
 				Lesson:           &lesson,
+				OccurrenceID:     occurrence.ID,
+				Date:             occurrence.Date,
 				AttendanceStatus: status,
 				LateMinutes:      lateMinutes,
-				OnTime:           lateMinutes == 0,
+				OnTime:           lateMinutes == 0 && status != AttendanceAbsent,
 				EntryTime:        &userStay.CreatedAt,
 				ExitTime:         exitTime,
+				DwellMinutes:     userStay.DwellMinutes,
+				Marked:           userMark != nil,
+				Reason:           reason,
 			})
 
 			switch status {
@@ -233,14 +426,18 @@ func (u *AttendanceUsecase) GetTodayAttendance(ctx context.Context, userID strin
 				summary.OnTime++
 			case AttendanceLate, AttendanceVeryLate:
 				summary.Late++
+			case AttendanceEarlyLeave:
+				summary.EarlyLeave++
+			case AttendanceAbsent:
+				summary.Absent++
 			}
 		}
 	}
 
-	// 出席率を計算
-	if summary.TotalLessons > 0 {
-		attendedLessons := summary.OnTime + summary.Late
-		summary.AttendanceRate = float64(attendedLessons) / float64(summary.TotalLessons) * 100
+	// 出席率を計算（公欠の授業は除く）
+	if countedLessons := summary.TotalLessons - summary.Excused; countedLessons > 0 {
+		attendedLessons := summary.OnTime + summary.Late + summary.EarlyLeave
+		summary.AttendanceRate = float64(attendedLessons) / float64(countedLessons) * 100
 	} else {
 		summary.AttendanceRate = 0
 	}
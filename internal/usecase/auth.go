@@ -0,0 +1,402 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/mail"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorInvalidCredentials = errors.New("メールアドレスまたはパスワードが正しくありません")
+	ErrorInvalidToken       = errors.New("トークンが無効です")
+	ErrorForbidden          = errors.New("この操作を行う権限がありません")
+	ErrorAccountExists      = errors.New("このメールアドレスのアカウントは既に登録されています")
+	ErrorInvalidAdminRole   = errors.New("roleが不正です（super_admin, org_admin, teacher, auditorのいずれか）")
+	ErrorInvalidAccountMail = errors.New("mailの形式が不正です")
+	ErrorWeakPassword       = errors.New("パスワードは8文字以上にしてください")
+	ErrorAccountOrgRequired = errors.New("super_admin以外のアカウントにはorg_idが必要です")
+	ErrorTeacherUserMissing = errors.New("teacherのアカウントにはuser_id（教員のユーザー）が必要です")
+	ErrorDeleteOwnAccount   = errors.New("自分のアカウントは削除できません")
+)
+
+// minPasswordLength パスワードの最小文字数
+const minPasswordLength = 8
+
+// tokenIssuer アクセストークンの発行者
+const tokenIssuer = "ed-mist-backend"
+
+// AuthConfig 認証の設定
+type AuthConfig struct {
+	Secret          []byte        // アクセストークンの署名鍵
+	AccessTokenTTL  time.Duration // アクセストークンの有効期間
+	RefreshTokenTTL time.Duration // リフレッシュトークンの有効期間
+}
+
+// Principal 認証済みのアカウント（アクセストークンのクレーム）
+type Principal struct {
+	AccountID string `json:"account_id"`
+	Role      string `json:"role"`
+	OrgID     string `json:"org_id,omitempty"`  // スーパー管理者は空
+	UserID    string `json:"user_id,omitempty"` // 教員アカウントに対応するユーザー
+}
+
+// IsSuperAdmin スーパー管理者か
+func (p *Principal) IsSuperAdmin() bool {
+	return p.Role == model.AdminRoleSuperAdmin
+}
+
+// CanWrite 参照以外の操作ができるか（スーパー管理者・組織管理者）
+func (p *Principal) CanWrite() bool {
+	return p.Role == model.AdminRoleSuperAdmin || p.Role == model.AdminRoleOrgAdmin
+}
+
+// CanAccessOrg 組織のリソースにアクセスできるか
+func (p *Principal) CanAccessOrg(orgID string) bool {
+	return p.IsSuperAdmin() || (p.OrgID != "" && p.OrgID == orgID)
+}
+
+// HasRole いずれかの役割を持つか
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenPair ログイン・トークン更新のレスポンス
+type TokenPair struct {
+	AccessToken  string              `json:"access_token"`
+	RefreshToken string              `json:"refresh_token"`
+	TokenType    string              `json:"token_type"`
+	ExpiresIn    int                 `json:"expires_in"` // アクセストークンの有効期間（秒）
+	Account      *model.AdminAccount `json:"account"`
+}
+
+// LoginRequest ログインリクエスト
+type LoginRequest struct {
+	Mail     string `json:"mail" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// RefreshRequest トークン更新・ログアウトリクエスト
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// CreateAccountRequest アカウント作成リクエスト
+type CreateAccountRequest struct {
+	Mail     string `json:"mail" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Role     string `json:"role" validate:"required"` // super_admin, org_admin, teacher, auditor
+	OrgID    string `json:"org_id"`                   // super_admin以外は必須
+	UserID   string `json:"user_id"`                  // teacherは必須（教員のユーザー）
+}
+
+// ChangePasswordRequest パスワード変更リクエスト
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// AuthUsecase 管理APIの認証ユースケース
+type AuthUsecase struct {
+	accountService      *service.AdminAccountService
+	refreshTokenService *service.RefreshTokenService
+	userService         *service.UserService
+	organizationService *service.OrganizationService
+	config              AuthConfig
+}
+
+// NewAuthUsecase 管理APIの認証ユースケースを作成
+func NewAuthUsecase(
+	accountService *service.AdminAccountService,
+	refreshTokenService *service.RefreshTokenService,
+	userService *service.UserService,
+	organizationService *service.OrganizationService,
+	config AuthConfig,
+) *AuthUsecase {
+	return &AuthUsecase{
+		accountService:      accountService,
+		refreshTokenService: refreshTokenService,
+		userService:         userService,
+		organizationService: organizationService,
+		config:              config,
+	}
+}
+
+// BootstrapSuperAdmin スーパー管理者がいない場合に初期アカウントを作成
+func (u *AuthUsecase) BootstrapSuperAdmin(ctx context.Context, adminMail, password string) error {
+	exists, err := u.accountService.ExistsSuperAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if len(password) < minPasswordLength {
+		return ErrorWeakPassword
+	}
+
+	account, err := u.accountService.Create(ctx, adminMail, password, model.AdminRoleSuperAdmin, nil, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("[AuthUsecase] 初期スーパー管理者を作成しました: %s", account.Mail)
+	return nil
+}
+
+// Login メールアドレスとパスワードでログインしてトークンを発行
+func (u *AuthUsecase) Login(ctx context.Context, req *LoginRequest) (*TokenPair, error) {
+	account, err := u.accountService.GetByMail(ctx, req.Mail)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidCredentials
+		}
+		return nil, err
+	}
+	if !u.accountService.VerifyPassword(account, req.Password) {
+		return nil, ErrorInvalidCredentials
+	}
+
+	if err := u.accountService.RecordLogin(ctx, account); err != nil {
+		return nil, err
+	}
+
+	// 有効期限切れのリフレッシュトークンを掃除する
+	if err := u.refreshTokenService.DeleteExpired(ctx); err != nil {
+		log.Printf("[AuthUsecase] 期限切れリフレッシュトークンの削除エラー: %v", err)
+	}
+
+	return u.issueTokens(ctx, account)
+}
+
+// Refresh リフレッシュトークンを使用して新しいトークンを発行（使用したリフレッシュトークンは失効する）
+// 失効済みのリフレッシュトークンが使われた場合は漏洩とみなし、アカウントのリフレッシュトークンをすべて失効させる
+func (u *AuthUsecase) Refresh(ctx context.Context, req *RefreshRequest) (*TokenPair, error) {
+	refreshToken, err := u.refreshTokenService.GetByToken(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidToken
+		}
+		return nil, err
+	}
+
+	if refreshToken.RevokedAt != nil {
+		log.Printf("[AuthUsecase] 失効済みのリフレッシュトークンが使用されました: Account=%s", refreshToken.AccountID)
+		if err := u.refreshTokenService.RevokeAll(ctx, refreshToken.AccountID); err != nil {
+			return nil, err
+		}
+		return nil, ErrorInvalidToken
+	}
+	if !refreshToken.IsActive(time.Now()) {
+		return nil, ErrorInvalidToken
+	}
+
+	revoked, err := u.refreshTokenService.Revoke(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// 同じトークンが同時に使用された
+		return nil, ErrorInvalidToken
+	}
+
+	account, err := u.accountService.GetByID(ctx, refreshToken.AccountID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, ErrorInvalidToken
+		}
+		return nil, err
+	}
+	return u.issueTokens(ctx, account)
+}
+
+// Logout リフレッシュトークンを失効させる（アクセストークンは有効期限まで有効）
+func (u *AuthUsecase) Logout(ctx context.Context, req *RefreshRequest) error {
+	refreshToken, err := u.refreshTokenService.GetByToken(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	_, err = u.refreshTokenService.Revoke(ctx, refreshToken)
+	return err
+}
+
+// Authenticate アクセストークンを検証して認証済みのアカウントを取得
+func (u *AuthUsecase) Authenticate(accessToken string) (*Principal, error) {
+	claims, err := jwt.Parse(accessToken, u.config.Secret, time.Now())
+	if err != nil {
+		return nil, ErrorInvalidToken
+	}
+	if claims.Issuer != tokenIssuer || claims.Subject == "" || !model.IsValidAdminRole(claims.Role) {
+		return nil, ErrorInvalidToken
+	}
+	return &Principal{
+		AccountID: claims.Subject,
+		Role:      claims.Role,
+		OrgID:     claims.OrgID,
+		UserID:    claims.UserID,
+	}, nil
+}
+
+// GetAccount 認証済みのアカウントを取得
+func (u *AuthUsecase) GetAccount(ctx context.Context, principal *Principal) (*model.AdminAccount, error) {
+	return u.accountService.GetByID(ctx, principal.AccountID)
+}
+
+// ChangePassword 自分のパスワードを変更（発行済みのリフレッシュトークンはすべて失効する）
+func (u *AuthUsecase) ChangePassword(ctx context.Context, principal *Principal, req *ChangePasswordRequest) error {
+	account, err := u.accountService.GetByID(ctx, principal.AccountID)
+	if err != nil {
+		return err
+	}
+	if !u.accountService.VerifyPassword(account, req.CurrentPassword) {
+		return ErrorInvalidCredentials
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		return ErrorWeakPassword
+	}
+
+	if err := u.accountService.UpdatePassword(ctx, account, req.NewPassword); err != nil {
+		return err
+	}
+	return u.refreshTokenService.RevokeAll(ctx, account.ID)
+}
+
+// CreateAccount アカウントを作成
+// スーパー管理者のアカウントはスーパー管理者のみ、それ以外は組織管理者が自組織の分のみ作成できる
+func (u *AuthUsecase) CreateAccount(ctx context.Context, principal *Principal, req *CreateAccountRequest) (*model.AdminAccount, error) {
+	if !model.IsValidAdminRole(req.Role) {
+		return nil, ErrorInvalidAdminRole
+	}
+	if addr, err := mail.ParseAddress(req.Mail); err != nil || addr.Address != req.Mail {
+		return nil, ErrorInvalidAccountMail
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrorWeakPassword
+	}
+
+	var orgID, userID *string
+	if req.Role == model.AdminRoleSuperAdmin {
+		if !principal.IsSuperAdmin() {
+			return nil, ErrorForbidden
+		}
+	} else {
+		if req.OrgID == "" {
+			return nil, ErrorAccountOrgRequired
+		}
+		if !principal.CanAccessOrg(req.OrgID) {
+			return nil, ErrorForbidden
+		}
+		// 組織の存在確認
+		if _, err := u.organizationService.GetByID(ctx, req.OrgID); err != nil {
+			return nil, err
+		}
+		orgID = &req.OrgID
+	}
+
+	// 教員アカウントは教員のユーザーに対応付ける
+	if req.Role == model.AdminRoleTeacher {
+		if req.UserID == "" {
+			return nil, ErrorTeacherUserMissing
+		}
+		user, err := u.userService.GetByID(ctx, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user.OrgID != req.OrgID {
+			return nil, errors.New("指定されたユーザーは組織に属していません")
+		}
+		if !user.IsTeacher() {
+			return nil, ErrorNotTeacher
+		}
+		userID = &user.ID
+	}
+
+	// 重複確認
+	if _, err := u.accountService.GetByMail(ctx, req.Mail); err == nil {
+		return nil, ErrorAccountExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	return u.accountService.Create(ctx, req.Mail, req.Password, req.Role, orgID, userID)
+}
+
+// GetAccounts アカウント一覧を取得（スーパー管理者以外は自組織のアカウントのみ）
+func (u *AuthUsecase) GetAccounts(ctx context.Context, principal *Principal, orgID string) ([]model.AdminAccount, error) {
+	if !principal.IsSuperAdmin() {
+		orgID = principal.OrgID
+	}
+	return u.accountService.GetAll(ctx, orgID)
+}
+
+// DeleteAccount アカウントを削除
+func (u *AuthUsecase) DeleteAccount(ctx context.Context, principal *Principal, accountID string) error {
+	if accountID == principal.AccountID {
+		return ErrorDeleteOwnAccount
+	}
+
+	account, err := u.accountService.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.OrgID == nil {
+		if !principal.IsSuperAdmin() {
+			return ErrorForbidden
+		}
+	} else if !principal.CanAccessOrg(*account.OrgID) {
+		return ErrorForbidden
+	}
+
+	return u.accountService.Delete(ctx, account.ID)
+}
+
+// issueTokens アクセストークンとリフレッシュトークンを発行
+func (u *AuthUsecase) issueTokens(ctx context.Context, account *model.AdminAccount) (*TokenPair, error) {
+	now := time.Now()
+	claims := jwt.Claims{
+		Subject:   account.ID,
+		Issuer:    tokenIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(u.config.AccessTokenTTL).Unix(),
+		ID:        uuid.NewString(),
+		Role:      account.Role,
+	}
+	if account.OrgID != nil {
+		claims.OrgID = *account.OrgID
+	}
+	if account.UserID != nil {
+		claims.UserID = *account.UserID
+	}
+	accessToken, err := jwt.Sign(claims, u.config.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, _, err := u.refreshTokenService.Issue(ctx, account.ID, u.config.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(u.config.AccessTokenTTL.Seconds()),
+		Account:      account,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/jwt"
+)
+
+var testAuthSecret = []byte("test-secret-0123456789abcdef")
+
+// signTestToken テスト用にクレームを署名したトークンを作成
+func signTestToken(t *testing.T, claims jwt.Claims, secret []byte) string {
+	t.Helper()
+	token, err := jwt.Sign(claims, secret)
+	if err != nil {
+		t.Fatalf("jwt.Sign() error = %v", err)
+	}
+	return token
+}
+
+func TestAuthUsecaseAuthenticate(t *testing.T) {
+	u := NewAuthUsecase(nil, nil, nil, nil, AuthConfig{
+		Secret:         testAuthSecret,
+		AccessTokenTTL: 15 * time.Minute,
+	})
+
+	now := time.Now()
+	adminClaims := func(modify func(*jwt.Claims)) jwt.Claims {
+		claims := jwt.Claims{
+			Subject:   "account-1",
+			Issuer:    tokenIssuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(15 * time.Minute).Unix(),
+			ID:        "jti-1",
+			Role:      model.AdminRoleOrgAdmin,
+			OrgID:     "org-1",
+		}
+		if modify != nil {
+			modify(&claims)
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *Principal
+		wantErr error
+	}{
+		{
+			name:  "有効なアクセストークン",
+			token: signTestToken(t, adminClaims(nil), testAuthSecret),
+			want:  &Principal{AccountID: "account-1", Role: model.AdminRoleOrgAdmin, OrgID: "org-1"},
+		},
+		{
+			name: "教員のアクセストークン",
+			token: signTestToken(t, adminClaims(func(c *jwt.Claims) {
+				c.Role = model.AdminRoleTeacher
+				c.UserID = "user-1"
+			}), testAuthSecret),
+			want: &Principal{AccountID: "account-1", Role: model.AdminRoleTeacher, OrgID: "org-1", UserID: "user-1"},
+		},
+		{
+			name: "アプリのセッショントークンは管理APIで使えない",
+			token: signTestToken(t, jwt.Claims{
+				Subject:   "user-1",
+				Issuer:    appTokenIssuer,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(time.Hour).Unix(),
+				ID:        "session-1",
+				OrgID:     "org-1",
+			}, testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name: "アプリのトークンに役割を付けても使えない",
+			token: signTestToken(t, adminClaims(func(c *jwt.Claims) {
+				c.Issuer = appTokenIssuer
+				c.Role = model.AdminRoleSuperAdmin
+			}), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "発行者なし",
+			token:   signTestToken(t, adminClaims(func(c *jwt.Claims) { c.Issuer = "" }), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "別の発行者",
+			token:   signTestToken(t, adminClaims(func(c *jwt.Claims) { c.Issuer = "other-service" }), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "subjectなし",
+			token:   signTestToken(t, adminClaims(func(c *jwt.Claims) { c.Subject = "" }), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "不正な役割",
+			token:   signTestToken(t, adminClaims(func(c *jwt.Claims) { c.Role = "root" }), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "役割なし",
+			token:   signTestToken(t, adminClaims(func(c *jwt.Claims) { c.Role = "" }), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "異なる署名鍵",
+			token:   signTestToken(t, adminClaims(nil), []byte("other-secret")),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name: "有効期限切れ",
+			token: signTestToken(t, adminClaims(func(c *jwt.Claims) {
+				c.ExpiresAt = now.Add(-time.Second).Unix()
+			}), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name: "まだ有効でない",
+			token: signTestToken(t, adminClaims(func(c *jwt.Claims) {
+				c.NotBefore = now.Add(time.Minute).Unix()
+			}), testAuthSecret),
+			wantErr: ErrorInvalidToken,
+		},
+		{
+			name:    "トークンの形式が不正",
+			token:   "not-a-token",
+			wantErr: ErrorInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := u.Authenticate(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("Authenticate() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalCanAccessOrg(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		orgID     string
+		want      bool
+	}{
+		{
+			name:      "スーパー管理者は全組織",
+			principal: Principal{Role: model.AdminRoleSuperAdmin},
+			orgID:     "org-1",
+			want:      true,
+		},
+		{
+			name:      "組織管理者は自組織",
+			principal: Principal{Role: model.AdminRoleOrgAdmin, OrgID: "org-1"},
+			orgID:     "org-1",
+			want:      true,
+		},
+		{
+			name:      "組織管理者は他組織にアクセスできない",
+			principal: Principal{Role: model.AdminRoleOrgAdmin, OrgID: "org-1"},
+			orgID:     "org-2",
+		},
+		{
+			name:      "教員は自組織",
+			principal: Principal{Role: model.AdminRoleTeacher, OrgID: "org-1"},
+			orgID:     "org-1",
+			want:      true,
+		},
+		{
+			name:      "監査担当は他組織にアクセスできない",
+			principal: Principal{Role: model.AdminRoleAuditor, OrgID: "org-1"},
+			orgID:     "org-2",
+		},
+		{
+			name:      "組織のないアカウントは空の組織IDにアクセスできない",
+			principal: Principal{Role: model.AdminRoleOrgAdmin},
+			orgID:     "",
+		},
+		{
+			name:      "組織管理者は空の組織IDにアクセスできない",
+			principal: Principal{Role: model.AdminRoleOrgAdmin, OrgID: "org-1"},
+			orgID:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.CanAccessOrg(tt.orgID); got != tt.want {
+				t.Errorf("CanAccessOrg(%q) = %v, want %v", tt.orgID, got, tt.want)
+			}
+		})
+	}
+}
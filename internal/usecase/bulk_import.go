@@ -0,0 +1,820 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/xlsx"
+)
+
+var (
+	ErrorInvalidImportKind   = errors.New("取り込み対象が不正です（users, rooms, subjects, lessons, enrollments, group_membersのいずれか）")
+	ErrorInvalidImportFormat = errors.New("ファイル形式が不正です（.csvまたは.xlsx）")
+	ErrorInvalidImportFile   = errors.New("ファイルを読み込めませんでした")
+	ErrorTooManyImportRows   = errors.New("取り込める行数の上限を超えています")
+	ErrorInvalidImportDay    = errors.New("day_of_weekの形式が不正です（0=日〜6=土、または曜日名）")
+)
+
+// errImportRollback ドライラン・エラー行がある場合にトランザクションを取り消す
+var errImportRollback = errors.New("取り込みを取り消しました")
+
+// maxImportRows 1回に取り込める最大行数
+const maxImportRows = 5000
+
+// 取り込み対象
+const (
+	ImportKindUsers        = "users"
+	ImportKindRooms        = "rooms"
+	ImportKindSubjects     = "subjects"
+	ImportKindLessons      = "lessons"
+	ImportKindEnrollments  = "enrollments"
+	ImportKindGroupMembers = "group_members"
+)
+
+// 行の取り込み結果
+const (
+	ImportRowOK    = "ok"
+	ImportRowError = "error"
+)
+
+// importColumns 取り込み対象ごとの必須列（いずれかの列名があればよい）
+var importColumns = map[string][][]string{
+	ImportKindUsers:        {{"mail", "user_mail"}},
+	ImportKindRooms:        {{"org_room_id"}, {"name", "room_name"}},
+	ImportKindSubjects:     {{"name"}},
+	ImportKindLessons:      {{"subject", "subject_id"}, {"org_room_id"}, {"start_time"}, {"end_time"}},
+	ImportKindEnrollments:  {{"mail", "user_mail", "user_id"}, {"subject", "subject_id"}},
+	ImportKindGroupMembers: {{"mail", "user_mail", "user_id"}, {"group", "group_id"}},
+}
+
+// BulkImportRequest 一括取り込みリクエスト
+type BulkImportRequest struct {
+	OrgID    string
+	Kind     string // users, rooms, subjects, lessons, enrollments, group_members
+	FileName string // 拡張子（.csv, .xlsx）でファイル形式を判定する
+	DryRun   bool   // trueの場合は検証のみで登録しない
+}
+
+// BulkImportRow 行ごとの取り込み結果
+type BulkImportRow struct {
+	Row    int    `json:"row"` // ファイル上の行番号（見出し行が1）
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"` // 登録したレコードのID（登録した場合のみ）
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkImportReport 一括取り込みの結果
+// 1行でもエラーがあれば、エラーのない行も含めてすべて登録しない
+type BulkImportReport struct {
+	Kind      string          `json:"kind"`
+	DryRun    bool            `json:"dry_run"`
+	Applied   bool            `json:"applied"` // 登録したかどうか
+	Total     int             `json:"total"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Rows      []BulkImportRow `json:"rows"`
+}
+
+// BulkImportUsecase CSV・XLSXからの一括取り込みユースケース
+type BulkImportUsecase struct {
+	transactor          *repository.Transactor
+	userUsecase         *UserUsecase
+	roomUsecase         *RoomUsecase
+	enrollmentUsecase   *EnrollmentUsecase
+	userService         *service.UserService
+	roomService         *service.RoomService
+	subjectService      *service.SubjectService
+	lessonService       *service.LessonService
+	groupService        *service.GroupService
+	organizationService *service.OrganizationService
+}
+
+// NewBulkImportUsecase CSV・XLSXからの一括取り込みユースケースを作成
+func NewBulkImportUsecase(
+	transactor *repository.Transactor,
+	userUsecase *UserUsecase,
+	roomUsecase *RoomUsecase,
+	enrollmentUsecase *EnrollmentUsecase,
+	userService *service.UserService,
+	roomService *service.RoomService,
+	subjectService *service.SubjectService,
+	lessonService *service.LessonService,
+	groupService *service.GroupService,
+	organizationService *service.OrganizationService,
+) *BulkImportUsecase {
+	return &BulkImportUsecase{
+		transactor:          transactor,
+		userUsecase:         userUsecase,
+		roomUsecase:         roomUsecase,
+		enrollmentUsecase:   enrollmentUsecase,
+		userService:         userService,
+		roomService:         roomService,
+		subjectService:      subjectService,
+		lessonService:       lessonService,
+		groupService:        groupService,
+		organizationService: organizationService,
+	}
+}
+
+// importRecord 取り込む1行
+type importRecord struct {
+	row    int
+	header map[string]int
+	cells  []string
+}
+
+// get 列の値（複数の列名を指定した場合は最初に存在する列）
+func (r importRecord) get(names ...string) string {
+	for _, name := range names {
+		if i, ok := r.header[name]; ok {
+			if i < len(r.cells) {
+				return strings.TrimSpace(r.cells[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// importRowFunc 1行を検証して登録し、登録したレコードのIDを返す
+type importRowFunc func(ctx context.Context, rec importRecord) (string, error)
+
+// Import CSV・XLSXの各行からユーザー・部屋・教科・授業・履修登録・クラスの所属を一括登録
+//
+// すべての行を1つのトランザクションで登録し、行ごとにセーブポイントを作成してエラーを記録する。
+// ドライランの場合、または1行でもエラーがある場合は最後にロールバックする（登録時の制約違反もドライランで検出できる）
+func (u *BulkImportUsecase) Import(ctx context.Context, req *BulkImportRequest, r io.ReaderAt, size int64) (*BulkImportReport, error) {
+	required, ok := importColumns[req.Kind]
+	if !ok {
+		return nil, ErrorInvalidImportKind
+	}
+
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := readImportFile(req.FileName, r, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > maxImportRows {
+		return nil, fmt.Errorf("%w（最大%d行）", ErrorTooManyImportRows, maxImportRows)
+	}
+	if len(records) > 0 {
+		for _, names := range required {
+			if !hasColumn(records[0].header, names) {
+				return nil, fmt.Errorf("%w: %s列がありません", ErrorInvalidImportFile, names[0])
+			}
+		}
+	}
+
+	report := &BulkImportReport{
+		Kind:   req.Kind,
+		DryRun: req.DryRun,
+		Total:  len(records),
+		Rows:   make([]BulkImportRow, 0, len(records)),
+	}
+
+	err = u.transactor.Run(ctx, func(ctx context.Context) error {
+		importRow, err := u.rowFunc(ctx, req.Kind, req.OrgID)
+		if err != nil {
+			return err
+		}
+
+		for _, rec := range records {
+			var id string
+			err := u.transactor.Savepoint(ctx, fmt.Sprintf("import_row_%d", rec.row), func(ctx context.Context) error {
+				var err error
+				id, err = importRow(ctx, rec)
+				return err
+			})
+
+			result := BulkImportRow{Row: rec.row, Status: ImportRowOK, ID: id}
+			if err != nil {
+				result = BulkImportRow{Row: rec.row, Status: ImportRowError, Error: err.Error()}
+				report.Failed++
+			} else {
+				report.Succeeded++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+
+		if req.DryRun || report.Failed > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+
+	report.Applied = err == nil
+	if !report.Applied {
+		// 登録していないためIDは返さない
+		for i := range report.Rows {
+			report.Rows[i].ID = ""
+		}
+	}
+	return report, nil
+}
+
+// rowFunc 取り込み対象ごとの1行の登録処理（重複チェック用の既存データを読み込む）
+func (u *BulkImportUsecase) rowFunc(ctx context.Context, kind, orgID string) (importRowFunc, error) {
+	switch kind {
+	case ImportKindUsers:
+		return u.userRowFunc(orgID), nil
+	case ImportKindRooms:
+		return u.roomRowFunc(ctx, orgID)
+	case ImportKindSubjects:
+		return u.subjectRowFunc(ctx, orgID)
+	case ImportKindLessons:
+		return u.lessonRowFunc(ctx, orgID)
+	case ImportKindEnrollments:
+		return u.enrollmentRowFunc(ctx, orgID)
+	case ImportKindGroupMembers:
+		return u.groupMemberRowFunc(ctx, orgID)
+	}
+	return nil, ErrorInvalidImportKind
+}
+
+// userRowFunc ユーザーの登録（列: mail, role）
+func (u *BulkImportUsecase) userRowFunc(orgID string) importRowFunc {
+	seen := make(map[string]int) // メールアドレス → 行番号
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		userMail := rec.get("mail", "user_mail")
+		if userMail == "" {
+			return "", errors.New("mailは必須です")
+		}
+		if addr, err := mail.ParseAddress(userMail); err != nil || addr.Address != userMail {
+			return "", errors.New("mailの形式が不正です")
+		}
+
+		key := strings.ToLower(userMail)
+		if row, ok := seen[key]; ok {
+			return "", fmt.Errorf("mailが%d行目と重複しています", row)
+		}
+		seen[key] = rec.row
+
+		if _, err := u.userService.GetByMail(ctx, userMail); err == nil {
+			return "", errors.New("このメールアドレスのユーザーは既に登録されています")
+		} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+			return "", err
+		}
+
+		role := strings.ToLower(rec.get("role"))
+		if role != "" && !model.IsValidUserRole(role) {
+			return "", ErrorInvalidUserRole
+		}
+
+		user, err := u.userUsecase.CreateUser(ctx, &CreateUserRequest{OrgID: orgID, UserMail: userMail, Role: role})
+		if err != nil {
+			return "", err
+		}
+		return user.ID, nil
+	}
+}
+
+// roomRowFunc 部屋の登録（列: org_room_id, name, caption, mist_zone_id）
+func (u *BulkImportUsecase) roomRowFunc(ctx context.Context, orgID string) (importRowFunc, error) {
+	rooms, err := u.roomService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		existing[room.OrgRoomID] = true
+	}
+	seen := make(map[string]int) // 組織部屋ID → 行番号
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		request := CreateRoomRequest{
+			OrgID:      orgID,
+			OrgRoomID:  rec.get("org_room_id"),
+			RoomName:   rec.get("name", "room_name"),
+			Caption:    rec.get("caption"),
+			MistZoneID: rec.get("mist_zone_id"),
+		}
+		if request.OrgRoomID == "" || request.RoomName == "" {
+			return "", errors.New("org_room_id, nameは必須です")
+		}
+		if row, ok := seen[request.OrgRoomID]; ok {
+			return "", fmt.Errorf("org_room_idが%d行目と重複しています", row)
+		}
+		seen[request.OrgRoomID] = rec.row
+		if existing[request.OrgRoomID] {
+			return "", errors.New("このorg_room_idの部屋は既に登録されています")
+		}
+
+		room, err := u.roomUsecase.CreateRoom(ctx, &request)
+		if err != nil {
+			return "", err
+		}
+		return room.ID, nil
+	}, nil
+}
+
+// subjectRowFunc 教科の登録（列: name, year）
+func (u *BulkImportUsecase) subjectRowFunc(ctx context.Context, orgID string) (importRowFunc, error) {
+	subjects, err := u.subjectService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		existing[subjectKey(subject.Name, subject.Year)] = true
+	}
+	seen := make(map[string]int) // 教科名/年度 → 行番号
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		name := rec.get("name")
+		if name == "" {
+			return "", errors.New("nameは必須です")
+		}
+		year := time.Now().Year()
+		if value := rec.get("year"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return "", errors.New("yearの形式が不正です")
+			}
+			year = parsed
+		}
+
+		key := subjectKey(name, year)
+		if row, ok := seen[key]; ok {
+			return "", fmt.Errorf("name, yearが%d行目と重複しています", row)
+		}
+		seen[key] = rec.row
+		if existing[key] {
+			return "", errors.New("この年度の同名の教科は既に登録されています")
+		}
+
+		subject, err := u.subjectService.Create(ctx, name, year, orgID)
+		if err != nil {
+			return "", err
+		}
+		return subject.ID, nil
+	}, nil
+}
+
+// lessonRowFunc 授業の登録
+// 列: subject（教科名）またはsubject_id, year（教科名で指定する場合の年度）, org_room_id,
+// day_of_week, start_time, end_time, period, date（日付指定の授業）, until（毎週の授業の最終日）,
+// group（対象のクラス名）またはgroup_id, group_year（クラス名で指定する場合の年度）
+func (u *BulkImportUsecase) lessonRowFunc(ctx context.Context, orgID string) (importRowFunc, error) {
+	subjects, err := u.subjectService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := u.groupService.GetByOrgID(ctx, orgID, 0)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := u.roomService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	roomsByOrgRoomID := make(map[string]*model.Room, len(rooms))
+	for i := range rooms {
+		roomsByOrgRoomID[rooms[i].OrgRoomID] = &rooms[i]
+	}
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		subject, err := findImportSubject(subjects, rec)
+		if err != nil {
+			return "", err
+		}
+
+		orgRoomID := rec.get("org_room_id")
+		if orgRoomID == "" {
+			return "", errors.New("org_room_idは必須です")
+		}
+		room, ok := roomsByOrgRoomID[orgRoomID]
+		if !ok {
+			return "", fmt.Errorf("org_room_id %s の部屋が見つかりません", orgRoomID)
+		}
+
+		startClock, err := parseImportClock(rec.get("start_time"))
+		if err != nil {
+			return "", fmt.Errorf("start_time: %w", err)
+		}
+		endClock, err := parseImportClock(rec.get("end_time"))
+		if err != nil {
+			return "", fmt.Errorf("end_time: %w", err)
+		}
+		if endClock <= startClock {
+			return "", errors.New("end_timeはstart_timeより後の時刻を指定してください")
+		}
+
+		period := 0
+		if value := rec.get("period"); value != "" {
+			period, err = strconv.Atoi(value)
+			if err != nil || period < 0 {
+				return "", errors.New("periodの形式が不正です")
+			}
+		}
+
+		var datePtr, untilPtr *time.Time
+		if value := rec.get("date"); value != "" {
+			date, err := parseImportDate(value)
+			if err != nil {
+				return "", fmt.Errorf("date: %w", err)
+			}
+			datePtr = &date
+		}
+		if value := rec.get("until"); value != "" {
+			if datePtr != nil {
+				return "", errors.New("untilは毎週の授業（dateなし）にのみ指定できます")
+			}
+			until, err := parseImportDate(value)
+			if err != nil {
+				return "", fmt.Errorf("until: %w", err)
+			}
+			untilPtr = &until
+		}
+
+		dayOfWeek := -1
+		if value := rec.get("day_of_week"); value != "" {
+			dayOfWeek, err = parseImportWeekday(value)
+			if err != nil {
+				return "", err
+			}
+		}
+		switch {
+		case datePtr != nil && dayOfWeek < 0:
+			dayOfWeek = int(datePtr.Weekday())
+		case datePtr != nil && dayOfWeek != int(datePtr.Weekday()):
+			return "", errors.New("day_of_weekがdateの曜日と一致しません")
+		case dayOfWeek < 0:
+			return "", errors.New("day_of_weekまたはdateは必須です")
+		}
+
+		var groupID *string
+		if rec.get("group", "group_id") != "" {
+			group, err := findImportGroup(groups, rec, "group_year")
+			if err != nil {
+				return "", err
+			}
+			groupID = &group.ID
+		}
+
+		// 授業の時刻は入力された時刻をUTCの壁時計として保存する（CreateLessonと同じ）
+		baseDate := time.Now()
+		if datePtr != nil {
+			baseDate = *datePtr
+		}
+		day := time.Date(baseDate.Year(), baseDate.Month(), baseDate.Day(), 0, 0, 0, 0, time.UTC)
+
+		lesson, err := u.lessonService.Create(
+			ctx,
+			subject.ID,
+			room.ID,
+			orgID,
+			dayOfWeek,
+			day.Add(startClock),
+			day.Add(endClock),
+			period,
+			datePtr,
+			untilPtr,
+			groupID,
+		)
+		if err != nil {
+			return "", err
+		}
+		return lesson.ID, nil
+	}, nil
+}
+
+// enrollmentRowFunc 履修登録（列: mailまたはuser_id, subject（教科名）またはsubject_id, year（教科名で指定する場合の年度））
+func (u *BulkImportUsecase) enrollmentRowFunc(ctx context.Context, orgID string) (importRowFunc, error) {
+	subjects, err := u.subjectService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.userService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	userIDsByMail := make(map[string]string, len(users))
+	for _, user := range users {
+		userIDsByMail[strings.ToLower(user.Mail)] = user.ID
+	}
+	seen := make(map[string]int) // ユーザーID/教科ID → 行番号
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		userID := rec.get("user_id")
+		if userID == "" {
+			userMail := rec.get("mail", "user_mail")
+			if userMail == "" {
+				return "", errors.New("mailまたはuser_idは必須です")
+			}
+			var ok bool
+			if userID, ok = userIDsByMail[strings.ToLower(userMail)]; !ok {
+				return "", fmt.Errorf("mail %s のユーザーが見つかりません", userMail)
+			}
+		}
+
+		subject, err := findImportSubject(subjects, rec)
+		if err != nil {
+			return "", err
+		}
+
+		key := userID + "/" + subject.ID
+		if row, ok := seen[key]; ok {
+			return "", fmt.Errorf("%d行目と同じ履修登録です", row)
+		}
+		seen[key] = rec.row
+
+		enrollment, err := u.enrollmentUsecase.CreateEnrollment(ctx, &CreateEnrollmentRequest{
+			OrgID:     orgID,
+			UserID:    userID,
+			SubjectID: subject.ID,
+		})
+		if err != nil {
+			return "", err
+		}
+		return enrollment.ID, nil
+	}, nil
+}
+
+// groupMemberRowFunc クラスの所属（列: mailまたはuser_id, group（クラス名）またはgroup_id, year（クラス名で指定する場合の年度））
+func (u *BulkImportUsecase) groupMemberRowFunc(ctx context.Context, orgID string) (importRowFunc, error) {
+	groups, err := u.groupService.GetByOrgID(ctx, orgID, 0)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.userService.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	userIDsByMail := make(map[string]string, len(users))
+	orgUserIDs := make(map[string]bool, len(users))
+	for _, user := range users {
+		userIDsByMail[strings.ToLower(user.Mail)] = user.ID
+		orgUserIDs[user.ID] = true
+	}
+	seen := make(map[string]int) // クラスID/ユーザーID → 行番号
+
+	return func(ctx context.Context, rec importRecord) (string, error) {
+		userID := rec.get("user_id")
+		if userID == "" {
+			userMail := rec.get("mail", "user_mail")
+			if userMail == "" {
+				return "", errors.New("mailまたはuser_idは必須です")
+			}
+			var ok bool
+			if userID, ok = userIDsByMail[strings.ToLower(userMail)]; !ok {
+				return "", fmt.Errorf("mail %s のユーザーが見つかりません", userMail)
+			}
+		} else if !orgUserIDs[userID] {
+			return "", fmt.Errorf("user_id %s のユーザーが見つかりません", userID)
+		}
+
+		group, err := findImportGroup(groups, rec, "year")
+		if err != nil {
+			return "", err
+		}
+
+		key := group.ID + "/" + userID
+		if row, ok := seen[key]; ok {
+			return "", fmt.Errorf("%d行目と同じクラスの所属です", row)
+		}
+		seen[key] = rec.row
+
+		if _, err := u.groupService.GetMember(ctx, group.ID, userID); err == nil {
+			return "", errors.New("このユーザーは既にこのクラスに所属しています")
+		} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+			return "", err
+		}
+		if err := u.groupService.AddMember(ctx, group, userID); err != nil {
+			return "", err
+		}
+		member, err := u.groupService.GetMember(ctx, group.ID, userID)
+		if err != nil {
+			return "", err
+		}
+		return member.ID, nil
+	}, nil
+}
+
+// findImportGroup 行のクラス（group_id、またはクラス名と年度で検索。年度の指定がなければ最新の年度）
+func findImportGroup(groups []model.Group, rec importRecord, yearColumn string) (*model.Group, error) {
+	if groupID := rec.get("group_id"); groupID != "" {
+		for i := range groups {
+			if groups[i].ID == groupID {
+				return &groups[i], nil
+			}
+		}
+		return nil, fmt.Errorf("group_id %s のクラスが見つかりません", groupID)
+	}
+
+	name := rec.get("group")
+	if name == "" {
+		return nil, errors.New("groupまたはgroup_idは必須です")
+	}
+	year := 0
+	if value := rec.get(yearColumn); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("%sの形式が不正です", yearColumn)
+		}
+		year = parsed
+	}
+
+	var found *model.Group
+	for i := range groups {
+		group := &groups[i]
+		if group.Name != name || (year != 0 && group.Year != year) {
+			continue
+		}
+		if found == nil || group.Year > found.Year {
+			found = group
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("クラス %s が見つかりません", name)
+	}
+	return found, nil
+}
+
+// findImportSubject 授業の行の教科（subject_id、または教科名と年度で検索。年度の指定がなければ最新の年度）
+func findImportSubject(subjects []model.Subject, rec importRecord) (*model.Subject, error) {
+	if subjectID := rec.get("subject_id"); subjectID != "" {
+		for i := range subjects {
+			if subjects[i].ID == subjectID {
+				return &subjects[i], nil
+			}
+		}
+		return nil, fmt.Errorf("subject_id %s の教科が見つかりません", subjectID)
+	}
+
+	name := rec.get("subject")
+	if name == "" {
+		return nil, errors.New("subjectまたはsubject_idは必須です")
+	}
+	year := 0
+	if value := rec.get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, errors.New("yearの形式が不正です")
+		}
+		year = parsed
+	}
+
+	var found *model.Subject
+	for i := range subjects {
+		subject := &subjects[i]
+		if subject.Name != name || (year != 0 && subject.Year != year) {
+			continue
+		}
+		if found == nil || subject.Year > found.Year {
+			found = subject
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("教科 %s が見つかりません", name)
+	}
+	return found, nil
+}
+
+// subjectKey 教科の重複チェックのキー
+func subjectKey(name string, year int) string {
+	return fmt.Sprintf("%s/%d", name, year)
+}
+
+// importWeekdays 曜日の表記 → 曜日（0=日曜日）
+var importWeekdays = map[string]int{
+	"日": 0, "月": 1, "火": 2, "水": 3, "木": 4, "金": 5, "土": 6,
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseImportWeekday 曜日（0〜6、日〜土、月曜日、Mon、Mondayなど）
+func parseImportWeekday(value string) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 || n > 6 {
+			return 0, ErrorInvalidImportDay
+		}
+		return n, nil
+	}
+
+	key := strings.ToLower(value)
+	if r, _ := utf8.DecodeRuneInString(key); r >= utf8.RuneSelf {
+		key = string(r) // 「月曜日」「月曜」は先頭の1文字で判定
+	} else if len(key) > 3 {
+		key = key[:3]
+	}
+	if n, ok := importWeekdays[key]; ok {
+		return n, nil
+	}
+	return 0, ErrorInvalidImportDay
+}
+
+// parseImportClock 時刻（HH:MM、HH:MM:SS、またはExcelの時刻のシリアル値）を0時からの経過時間に変換
+func parseImportClock(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, errors.New("必須です")
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+		}
+	}
+	if t, ok := xlsx.SerialTime(value); ok && t.Before(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+	}
+	return 0, errors.New("時刻の形式が不正です（HH:MM）")
+}
+
+// parseImportDate 日付（YYYY-MM-DD、YYYY/MM/DD、またはExcelの日付のシリアル値）
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006/1/2"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if t, ok := xlsx.SerialTime(value); ok && t.Year() >= 1900 {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, ErrorInvalidDate
+}
+
+// readImportFile CSV・XLSXを読み込んで見出し行以降の空でない行を返す
+func readImportFile(fileName string, r io.ReaderAt, size int64) ([]importRecord, error) {
+	var table [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrorInvalidImportFile, err)
+		}
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+		reader.FieldsPerRecord = -1
+		table, err = reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrorInvalidImportFile, err)
+		}
+	case ".xlsx":
+		var err error
+		table, err = xlsx.ReadFirstSheet(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrorInvalidImportFile, err)
+		}
+	default:
+		return nil, ErrorInvalidImportFormat
+	}
+
+	if len(table) == 0 {
+		return nil, fmt.Errorf("%w: 見出し行がありません", ErrorInvalidImportFile)
+	}
+	header := make(map[string]int, len(table[0]))
+	for i, name := range table[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := header[name]; name != "" && !ok {
+			header[name] = i
+		}
+	}
+
+	var records []importRecord
+	for i, cells := range table[1:] {
+		if isBlankRow(cells) {
+			continue
+		}
+		records = append(records, importRecord{row: i + 2, header: header, cells: cells})
+	}
+	return records, nil
+}
+
+// hasColumn 列名のいずれかが見出しにあるか
+func hasColumn(header map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := header[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlankRow すべてのセルが空の行か
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+var (
+	ErrorInvalidDate            = errors.New("日付の形式が不正です（YYYY-MM-DD）")
+	ErrorInvalidDateRange       = errors.New("end_dateはstart_date以降の日付を指定してください")
+	ErrorInvalidCalendarDayKind = errors.New("kindはholidayまたはspecialを指定してください")
+	ErrorInvalidDayOfWeek       = errors.New("特別時間割にはday_of_week（0=日〜6=土）を指定してください")
+	ErrorCalendarDayExists      = errors.New("指定された日付は既に登録されています")
+)
+
+// CalendarUsecase 学期・休日カレンダーユースケース
+type CalendarUsecase struct {
+	calendarService     *service.CalendarService
+	organizationService *service.OrganizationService
+}
+
+// NewCalendarUsecase 学期・休日カレンダーユースケースを作成
+func NewCalendarUsecase(calendarService *service.CalendarService, organizationService *service.OrganizationService) *CalendarUsecase {
+	return &CalendarUsecase{
+		calendarService:     calendarService,
+		organizationService: organizationService,
+	}
+}
+
+// parseCalendarDate YYYY-MM-DD形式の日付を解析
+func parseCalendarDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, ErrorInvalidDate
+	}
+	return date, nil
+}
+
+// parseDateRange 開始日・終了日を解析して順序を確認
+func parseDateRange(start, end string) (time.Time, time.Time, error) {
+	startDate, err := parseCalendarDate(start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endDate, err := parseCalendarDate(end)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, ErrorInvalidDateRange
+	}
+	return startDate, endDate, nil
+}
+
+// validateCalendarDay 種類と特別時間割の曜日を確認（休日の場合は曜日を使用しない）
+func validateCalendarDay(kind string, dayOfWeek *int) (*int, error) {
+	switch kind {
+	case model.CalendarDayHoliday:
+		return nil, nil
+	case model.CalendarDaySpecial:
+		if dayOfWeek == nil || *dayOfWeek < 0 || *dayOfWeek > 6 {
+			return nil, ErrorInvalidDayOfWeek
+		}
+		return dayOfWeek, nil
+	default:
+		return nil, ErrorInvalidCalendarDayKind
+	}
+}
+
+// CreateTermRequest 学期作成リクエスト
+type CreateTermRequest struct {
+	OrgID     string `json:"org_id" validate:"required"`
+	Name      string `json:"name" validate:"required"`
+	StartDate string `json:"start_date" validate:"required"` // "2025-04-01"
+	EndDate   string `json:"end_date" validate:"required"`   // "2025-09-30"（この日を含む）
+}
+
+// CreateTerm 学期を作成
+func (u *CalendarUsecase) CreateTerm(ctx context.Context, req *CreateTermRequest) (*model.Term, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.calendarService.CreateTerm(ctx, req.OrgID, req.Name, startDate, endDate)
+}
+
+// GetTermsByOrgID 組織IDで学期一覧を取得
+func (u *CalendarUsecase) GetTermsByOrgID(ctx context.Context, orgID string) ([]model.Term, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.calendarService.GetTermsByOrgID(ctx, orgID)
+}
+
+// UpdateTermRequest 学期更新リクエスト
+type UpdateTermRequest struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// UpdateTerm 学期を更新
+func (u *CalendarUsecase) UpdateTerm(ctx context.Context, orgID, termID string, req *UpdateTermRequest) (*model.Term, error) {
+	term, err := u.getTermInOrg(ctx, orgID, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定されていない項目は既存の値を維持
+	name := term.Name
+	if req.Name != "" {
+		name = req.Name
+	}
+	start := term.StartDate.Format("2006-01-02")
+	if req.StartDate != "" {
+		start = req.StartDate
+	}
+	end := term.EndDate.Format("2006-01-02")
+	if req.EndDate != "" {
+		end = req.EndDate
+	}
+	startDate, endDate, err := parseDateRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.calendarService.UpdateTerm(ctx, term, name, startDate, endDate); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// DeleteTerm 学期を削除
+func (u *CalendarUsecase) DeleteTerm(ctx context.Context, orgID, termID string) error {
+	if _, err := u.getTermInOrg(ctx, orgID, termID); err != nil {
+		return err
+	}
+	return u.calendarService.DeleteTerm(ctx, termID)
+}
+
+// getTermInOrg 組織に属する学期を取得
+func (u *CalendarUsecase) getTermInOrg(ctx context.Context, orgID, termID string) (*model.Term, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 学期の存在確認
+	term, err := u.calendarService.GetTermByID(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 学期が指定された組織に属しているかチェック
+	if term.OrgID != orgID {
+		return nil, errors.New("指定された学期は組織に属していません")
+	}
+	return term, nil
+}
+
+// CreateCalendarDayRequest 休日・特別時間割作成リクエスト
+type CreateCalendarDayRequest struct {
+	OrgID     string `json:"org_id" validate:"required"`
+	Date      string `json:"date" validate:"required"` // "2025-11-03"
+	Kind      string `json:"kind" validate:"required"` // "holiday" or "special"
+	Name      string `json:"name"`
+	DayOfWeek *int   `json:"day_of_week"` // 特別時間割で使用する曜日
+}
+
+// CreateCalendarDay 休日・特別時間割を作成
+func (u *CalendarUsecase) CreateCalendarDay(ctx context.Context, req *CreateCalendarDayRequest) (*model.CalendarDay, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := parseCalendarDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+	dayOfWeek, err := validateCalendarDay(req.Kind, req.DayOfWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同じ日付の重複確認
+	if _, err := u.calendarService.GetDayByOrgAndDate(ctx, req.OrgID, date); err == nil {
+		return nil, ErrorCalendarDayExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	return u.calendarService.CreateDay(ctx, req.OrgID, date, req.Kind, req.Name, dayOfWeek)
+}
+
+// GetCalendarDaysByOrgID 組織IDで休日・特別時間割一覧を取得（from・toは省略可）
+func (u *CalendarUsecase) GetCalendarDaysByOrgID(ctx context.Context, orgID, from, to string) ([]model.CalendarDay, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromDate, toDate time.Time
+	if from != "" {
+		if fromDate, err = parseCalendarDate(from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if toDate, err = parseCalendarDate(to); err != nil {
+			return nil, err
+		}
+	}
+	if !fromDate.IsZero() && !toDate.IsZero() && toDate.Before(fromDate) {
+		return nil, ErrorInvalidDateRange
+	}
+
+	return u.calendarService.GetDaysByOrgID(ctx, orgID, fromDate, toDate)
+}
+
+// UpdateCalendarDayRequest 休日・特別時間割更新リクエスト
+type UpdateCalendarDayRequest struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	DayOfWeek *int   `json:"day_of_week"`
+}
+
+// UpdateCalendarDay 休日・特別時間割を更新
+func (u *CalendarUsecase) UpdateCalendarDay(ctx context.Context, orgID, dayID string, req *UpdateCalendarDayRequest) (*model.CalendarDay, error) {
+	day, err := u.getCalendarDayInOrg(ctx, orgID, dayID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 種類が指定されていない場合は既存の値を維持
+	kind := day.Kind
+	if req.Kind != "" {
+		kind = req.Kind
+	}
+	requestedDayOfWeek := req.DayOfWeek
+	if requestedDayOfWeek == nil {
+		requestedDayOfWeek = day.DayOfWeek
+	}
+	dayOfWeek, err := validateCalendarDay(kind, requestedDayOfWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.calendarService.UpdateDay(ctx, day, kind, req.Name, dayOfWeek); err != nil {
+		return nil, err
+	}
+	return day, nil
+}
+
+// DeleteCalendarDay 休日・特別時間割を削除
+func (u *CalendarUsecase) DeleteCalendarDay(ctx context.Context, orgID, dayID string) error {
+	if _, err := u.getCalendarDayInOrg(ctx, orgID, dayID); err != nil {
+		return err
+	}
+	return u.calendarService.DeleteDay(ctx, dayID)
+}
+
+// getCalendarDayInOrg 組織に属する休日・特別時間割を取得
+func (u *CalendarUsecase) getCalendarDayInOrg(ctx context.Context, orgID, dayID string) (*model.CalendarDay, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 休日・特別時間割の存在確認
+	day, err := u.calendarService.GetDayByID(ctx, dayID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定された組織に属しているかチェック
+	if day.OrgID != orgID {
+		return nil, errors.New("指定された日付は組織に属していません")
+	}
+	return day, nil
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+var (
+	ErrorEnrollmentExists = errors.New("このユーザーは既にこの教科を履修登録しています")
+)
+
+// EnrollmentUsecase 履修登録ユースケース
+type EnrollmentUsecase struct {
+	enrollmentService   *service.EnrollmentService
+	userService         *service.UserService
+	subjectService      *service.SubjectService
+	organizationService *service.OrganizationService
+}
+
+// NewEnrollmentUsecase 履修登録ユースケースを作成
+func NewEnrollmentUsecase(
+	enrollmentService *service.EnrollmentService,
+	userService *service.UserService,
+	subjectService *service.SubjectService,
+	organizationService *service.OrganizationService,
+) *EnrollmentUsecase {
+	return &EnrollmentUsecase{
+		enrollmentService:   enrollmentService,
+		userService:         userService,
+		subjectService:      subjectService,
+		organizationService: organizationService,
+	}
+}
+
+// CreateEnrollmentRequest 履修登録作成リクエスト
+type CreateEnrollmentRequest struct {
+	OrgID     string `json:"org_id" validate:"required"`
+	UserID    string `json:"user_id" validate:"required"`
+	SubjectID string `json:"subject_id" validate:"required"`
+}
+
+// CreateEnrollment 履修登録を作成
+func (u *EnrollmentUsecase) CreateEnrollment(ctx context.Context, req *CreateEnrollmentRequest) (*model.Enrollment, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ユーザーが指定された組織に属しているかチェック
+	user, err := u.userService.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrgID != req.OrgID {
+		return nil, errors.New("指定されたユーザーは組織に属していません")
+	}
+
+	// 教科が指定された組織に属しているかチェック
+	subject, err := u.subjectService.GetByID(ctx, req.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	if subject.OrgID != req.OrgID {
+		return nil, errors.New("指定された教科は組織に属していません")
+	}
+
+	// 同じユーザー・教科の重複確認
+	if _, err := u.enrollmentService.GetByUserAndSubject(ctx, user.ID, subject.ID); err == nil {
+		return nil, ErrorEnrollmentExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	return u.enrollmentService.Create(ctx, req.OrgID, user.ID, subject.ID)
+}
+
+// GetEnrollmentsByOrgID 組織IDで履修登録一覧を取得（userID・subjectIDで絞り込み可）
+func (u *EnrollmentUsecase) GetEnrollmentsByOrgID(ctx context.Context, orgID, userID, subjectID string) ([]model.Enrollment, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.enrollmentService.GetByOrgID(ctx, orgID, userID, subjectID)
+}
+
+// DeleteEnrollment 履修登録を削除
+func (u *EnrollmentUsecase) DeleteEnrollment(ctx context.Context, orgID, enrollmentID string) error {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	// 履修登録の存在確認
+	enrollment, err := u.enrollmentService.GetByID(ctx, enrollmentID)
+	if err != nil {
+		return err
+	}
+
+	// 履修登録が指定された組織に属しているかチェック
+	if enrollment.OrgID != orgID {
+		return errors.New("指定された履修登録は組織に属していません")
+	}
+
+	return u.enrollmentService.Delete(ctx, enrollmentID)
+}
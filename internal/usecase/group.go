@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+var (
+	ErrorGroupExists    = errors.New("同じ年度の同名のクラスは既に登録されています")
+	ErrorGroupNoMembers = errors.New("user_idsを1件以上指定してください")
+	ErrorNotGroupMember = errors.New("指定されたユーザーはクラスに所属していません")
+)
+
+// GroupUsecase クラス（ホームルーム）ユースケース
+type GroupUsecase struct {
+	groupService        *service.GroupService
+	userService         *service.UserService
+	lessonService       *service.LessonService
+	occurrenceService   *service.LessonOccurrenceService
+	attendanceUsecase   *AttendanceUsecase
+	organizationService *service.OrganizationService
+}
+
+// NewGroupUsecase クラスユースケースを作成
+func NewGroupUsecase(
+	groupService *service.GroupService,
+	userService *service.UserService,
+	lessonService *service.LessonService,
+	occurrenceService *service.LessonOccurrenceService,
+	attendanceUsecase *AttendanceUsecase,
+	organizationService *service.OrganizationService,
+) *GroupUsecase {
+	return &GroupUsecase{
+		groupService:        groupService,
+		userService:         userService,
+		lessonService:       lessonService,
+		occurrenceService:   occurrenceService,
+		attendanceUsecase:   attendanceUsecase,
+		organizationService: organizationService,
+	}
+}
+
+// CreateGroupRequest クラス作成リクエスト
+type CreateGroupRequest struct {
+	OrgID   string `json:"org_id" validate:"required"`
+	Name    string `json:"name" validate:"required"` // "2-A"
+	Year    int    `json:"year"`                     // 年度（省略時は今年）
+	Caption string `json:"caption"`
+}
+
+// UpdateGroupRequest クラス更新リクエスト
+type UpdateGroupRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Year    int    `json:"year"` // 年度（省略時は変更しない）
+	Caption string `json:"caption"`
+}
+
+// AddGroupMembersRequest クラスへの所属追加リクエスト
+type AddGroupMembersRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required"`
+}
+
+// GroupAttendance クラスの指定日の出席状況
+type GroupAttendance struct {
+	Group   *model.Group       `json:"group"`
+	Date    string             `json:"date"`
+	Summary *AttendanceSummary `json:"summary"` // 所属ユーザー全体の集計
+	Members []MemberAttendance `json:"members"`
+}
+
+// CreateGroup クラスを作成
+func (u *GroupUsecase) CreateGroup(ctx context.Context, req *CreateGroupRequest) (*model.Group, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	year := req.Year
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	// 同じ年度の同名クラスの重複確認
+	if _, err := u.groupService.GetByOrgNameYear(ctx, req.OrgID, req.Name, year); err == nil {
+		return nil, ErrorGroupExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	return u.groupService.Create(ctx, req.OrgID, req.Name, year, req.Caption)
+}
+
+// GetGroupsByOrgID 組織IDでクラス一覧を取得（yearが0の場合は全年度）
+func (u *GroupUsecase) GetGroupsByOrgID(ctx context.Context, orgID string, year int) ([]model.Group, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.groupService.GetByOrgID(ctx, orgID, year)
+}
+
+// GetGroup 組織に属するクラスを取得
+func (u *GroupUsecase) GetGroup(ctx context.Context, orgID, groupID string) (*model.Group, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// クラスの存在確認
+	group, err := u.groupService.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// クラスが指定された組織に属しているかチェック
+	if group.OrgID != orgID {
+		return nil, errors.New("指定されたクラスは組織に属していません")
+	}
+	return group, nil
+}
+
+// UpdateGroup クラスを更新
+func (u *GroupUsecase) UpdateGroup(ctx context.Context, orgID, groupID string, req *UpdateGroupRequest) (*model.Group, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	year := req.Year
+	if year == 0 {
+		year = group.Year
+	}
+
+	// 変更後のクラス名・年度の重複確認
+	if existing, err := u.groupService.GetByOrgNameYear(ctx, orgID, req.Name, year); err == nil && existing.ID != group.ID {
+		return nil, ErrorGroupExists
+	} else if err != nil && !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	if err := u.groupService.Update(ctx, group, req.Name, year, req.Caption); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteGroup クラスを削除（クラスに割り当てた授業は組織の全ユーザーが対象に戻る）
+func (u *GroupUsecase) DeleteGroup(ctx context.Context, orgID, groupID string) error {
+	if _, err := u.GetGroup(ctx, orgID, groupID); err != nil {
+		return err
+	}
+	return u.groupService.Delete(ctx, groupID)
+}
+
+// AddGroupMembers ユーザーをクラスに所属させる（すでに所属しているユーザーはそのまま）
+func (u *GroupUsecase) AddGroupMembers(ctx context.Context, orgID, groupID string, req *AddGroupMembersRequest) ([]model.GroupMember, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.UserIDs) == 0 {
+		return nil, ErrorGroupNoMembers
+	}
+
+	// すべてのユーザーが組織に属しているか確認してから追加する
+	for _, userID := range req.UserIDs {
+		user, err := u.userService.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.OrgID != orgID {
+			return nil, errors.New("指定されたユーザーは組織に属していません")
+		}
+	}
+	for _, userID := range req.UserIDs {
+		if err := u.groupService.AddMember(ctx, group, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.groupService.GetMembers(ctx, group.ID)
+}
+
+// GetGroupMembers クラスの所属ユーザー一覧を取得
+func (u *GroupUsecase) GetGroupMembers(ctx context.Context, orgID, groupID string) ([]model.GroupMember, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return u.groupService.GetMembers(ctx, group.ID)
+}
+
+// RemoveGroupMember ユーザーをクラスから外す
+func (u *GroupUsecase) RemoveGroupMember(ctx context.Context, orgID, groupID, userID string) error {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.groupService.GetMember(ctx, group.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return ErrorNotGroupMember
+		}
+		return err
+	}
+	return u.groupService.RemoveMember(ctx, group.ID, userID)
+}
+
+// GetGroupLessons クラスに割り当てた授業の一覧を取得
+func (u *GroupUsecase) GetGroupLessons(ctx context.Context, orgID, groupID string) ([]model.Lesson, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return u.lessonService.GetByGroupID(ctx, group.ID)
+}
+
+// AssignLesson 授業をクラスに割り当てる（クラスに所属するユーザーのみが対象になる）
+func (u *GroupUsecase) AssignLesson(ctx context.Context, orgID, groupID, lessonID string) (*model.Lesson, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	lesson, err := u.getLessonInOrg(ctx, orgID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	lesson.GroupID = &group.ID
+	if err := u.lessonService.Update(ctx, lesson); err != nil {
+		return nil, err
+	}
+	return lesson, nil
+}
+
+// UnassignLesson 授業のクラスへの割り当てを解除する（組織の全ユーザーが対象に戻る）
+func (u *GroupUsecase) UnassignLesson(ctx context.Context, orgID, groupID, lessonID string) (*model.Lesson, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	lesson, err := u.getLessonInOrg(ctx, orgID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	if lesson.GroupID == nil || *lesson.GroupID != group.ID {
+		return nil, errors.New("指定された授業はクラスに割り当てられていません")
+	}
+
+	lesson.GroupID = nil
+	if err := u.lessonService.Update(ctx, lesson); err != nil {
+		return nil, err
+	}
+	return lesson, nil
+}
+
+// GetGroupTimetable クラスの指定日の時間割を取得（dateは省略時は今日）
+func (u *GroupUsecase) GetGroupTimetable(ctx context.Context, orgID, groupID, date string) ([]model.LessonOccurrence, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	targetDate, err := parseDateOrToday(date)
+	if err != nil {
+		return nil, err
+	}
+	return u.occurrenceService.GetTimetableByGroup(ctx, group, targetDate)
+}
+
+// GetGroupAttendance クラスの所属ユーザー全員の指定日の出席状況を取得（dateは省略時は今日）
+// 各ユーザーの出席は、クラスの授業に限らずそのユーザーが対象の授業すべてで判定する
+func (u *GroupUsecase) GetGroupAttendance(ctx context.Context, orgID, groupID, date string) (*GroupAttendance, error) {
+	group, err := u.GetGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	targetDate, err := parseDateOrToday(date)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.groupService.GetMembers(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]model.User, 0, len(members))
+	for _, member := range members {
+		users = append(users, member.User)
+	}
+
+	attendances, err := u.attendanceUsecase.GetAttendanceByUsers(ctx, orgID, users, targetDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 所属ユーザー全体の集計
+	summary := &AttendanceSummary{}
+	for _, attendance := range attendances {
+		summary.TotalLessons += attendance.Summary.TotalLessons
+		summary.OnTime += attendance.Summary.OnTime
+		summary.Late += attendance.Summary.Late
+		summary.EarlyLeave += attendance.Summary.EarlyLeave
+		summary.Absent += attendance.Summary.Absent
+		summary.Excused += attendance.Summary.Excused
+		summary.Closed = attendance.Summary.Closed
+		summary.ClosedReason = attendance.Summary.ClosedReason
+	}
+	if countedLessons := summary.TotalLessons - summary.Excused; countedLessons > 0 {
+		attendedLessons := summary.OnTime + summary.Late + summary.EarlyLeave
+		summary.AttendanceRate = float64(attendedLessons) / float64(countedLessons) * 100
+	}
+
+	return &GroupAttendance{
+		Group:   group,
+		Date:    targetDate.Format("2006-01-02"),
+		Summary: summary,
+		Members: attendances,
+	}, nil
+}
+
+// getLessonInOrg 組織に属する授業を取得
+func (u *GroupUsecase) getLessonInOrg(ctx context.Context, orgID, lessonID string) (*model.Lesson, error) {
+	lesson, err := u.lessonService.GetByID(ctx, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	if lesson.OrgID != orgID {
+		return nil, errors.New("指定された授業は組織に属していません")
+	}
+	return lesson, nil
+}
+
+// parseDateOrToday 日付（YYYY-MM-DD）を解析（空の場合は今日）
+func parseDateOrToday(date string) (time.Time, error) {
+	if date == "" {
+		return time.Now(), nil
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, ErrorInvalidDate
+	}
+	return parsed, nil
+}
@@ -0,0 +1,456 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/ical"
+)
+
+var (
+	ErrorInvalidICal = errors.New("iCalendarファイルを解析できませんでした")
+)
+
+// LessonImportSkip 取り込まなかった予定
+type LessonImportSkip struct {
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	Reason  string `json:"reason"`
+}
+
+// LessonImportResult iCalendarの取り込み結果
+type LessonImportResult struct {
+	Lessons   []model.Lesson         `json:"lessons"`   // 作成・更新した授業
+	Updated   int                    `json:"updated"`   // 以前の取り込みから更新した授業の数
+	Overrides []model.LessonOverride `json:"overrides"` // EXDATE・変更された回から作成した授業変更
+	Skipped   []LessonImportSkip     `json:"skipped"`
+}
+
+// LessonImportUsecase iCalendarからの時間割取り込みユースケース
+type LessonImportUsecase struct {
+	transactor          *repository.Transactor
+	lessonService       *service.LessonService
+	overrideService     *service.LessonOverrideService
+	subjectService      *service.SubjectService
+	roomService         *service.RoomService
+	organizationService *service.OrganizationService
+}
+
+// NewLessonImportUsecase iCalendarからの時間割取り込みユースケースを作成
+func NewLessonImportUsecase(
+	transactor *repository.Transactor,
+	lessonService *service.LessonService,
+	overrideService *service.LessonOverrideService,
+	subjectService *service.SubjectService,
+	roomService *service.RoomService,
+	organizationService *service.OrganizationService,
+) *LessonImportUsecase {
+	return &LessonImportUsecase{
+		transactor:          transactor,
+		lessonService:       lessonService,
+		overrideService:     overrideService,
+		subjectService:      subjectService,
+		roomService:         roomService,
+		organizationService: organizationService,
+	}
+}
+
+// lessonImport 1回の取り込みの状態
+type lessonImport struct {
+	orgID      string
+	result     *LessonImportResult
+	rooms      map[string]*model.Room    // 組織部屋ID → 部屋
+	subjects   map[string]*model.Subject // 教科名 → 教科
+	lessons    map[string][]model.Lesson // UID → 作成した授業（BYDAYが複数の場合は曜日ごと）
+	overridden map[string]bool           // 授業ID/日付 → 授業変更を作成済み
+}
+
+// ImportICal iCalendarの予定から授業を一括作成
+//
+//   - SUMMARYを教科名、LOCATIONを部屋の組織部屋ID（org_room_id）として対応付ける（教科がなければ作成する）
+//   - 繰り返しのない予定は日付指定の授業、RRULE（FREQ=WEEKLY）の予定はBYDAYの曜日ごとの毎週の授業にする
+//   - UNTIL・COUNTは授業の最終日、EXDATEは休講の授業変更、RECURRENCE-IDのある予定はその日の授業変更にする
+//   - 同じファイルを再度取り込んだ場合は、UIDが同じ予定の授業・授業変更を作り直さずに更新する
+//
+// 取り込めない予定はスキップして理由を結果に含める。取り込みは1つのトランザクションで行い、エラー時はすべて取り消す
+func (u *LessonImportUsecase) ImportICal(ctx context.Context, orgID string, r io.Reader) (*LessonImportResult, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorInvalidICal, err)
+	}
+
+	imp := &lessonImport{
+		orgID:      orgID,
+		result:     &LessonImportResult{Lessons: []model.Lesson{}, Overrides: []model.LessonOverride{}, Skipped: []LessonImportSkip{}},
+		rooms:      make(map[string]*model.Room),
+		subjects:   make(map[string]*model.Subject),
+		lessons:    make(map[string][]model.Lesson),
+		overridden: make(map[string]bool),
+	}
+
+	err = u.transactor.Run(ctx, func(ctx context.Context) error {
+		subjects, err := u.subjectService.GetByOrgID(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for i := range subjects {
+			if _, exists := imp.subjects[subjects[i].Name]; !exists {
+				imp.subjects[subjects[i].Name] = &subjects[i]
+			}
+		}
+
+		// 繰り返しの元になる予定を先に取り込み、変更された回（RECURRENCE-ID）はその後に反映する
+		events := calendar.Children("VEVENT")
+		for _, event := range events {
+			if event.Get("RECURRENCE-ID") != nil {
+				continue
+			}
+			if reason, err := u.importEvent(ctx, imp, event); err != nil {
+				return err
+			} else if reason != "" {
+				imp.skip(event, reason)
+			}
+		}
+		for _, event := range events {
+			if event.Get("RECURRENCE-ID") == nil {
+				continue
+			}
+			if reason, err := u.importException(ctx, imp, event); err != nil {
+				return err
+			} else if reason != "" {
+				imp.skip(event, reason)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imp.result, nil
+}
+
+// importEvent 予定を授業として作成（取り込めない場合は理由を返す）
+func (u *LessonImportUsecase) importEvent(ctx context.Context, imp *lessonImport, event *ical.Component) (string, error) {
+	if strings.EqualFold(event.Value("STATUS"), "CANCELLED") {
+		return "キャンセルされた予定です", nil
+	}
+
+	uid := strings.TrimSpace(event.Value("UID"))
+	if uid == "" {
+		return "UIDがありません", nil
+	}
+
+	start, end, reason := eventTimes(event)
+	if reason != "" {
+		return reason, nil
+	}
+
+	room, reason, err := u.resolveRoom(ctx, imp, event)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+
+	summary := strings.TrimSpace(ical.UnescapeText(event.Value("SUMMARY")))
+	if summary == "" {
+		return "SUMMARY（教科名）がありません", nil
+	}
+	subject, err := u.resolveSubject(ctx, imp, summary, start.Year())
+	if err != nil {
+		return "", err
+	}
+
+	startClock, endClock := lessonClock(start), lessonClock(end)
+
+	// 繰り返しのない予定は日付指定の授業
+	rruleProp := event.Get("RRULE")
+	if rruleProp == nil {
+		date := calendarDate(start)
+		lesson := &model.Lesson{
+			SubjectID: subject.ID,
+			RoomID:    room.ID,
+			DayOfWeek: int(start.Weekday()),
+			StartTime: startClock,
+			EndTime:   endClock,
+			Date:      &date,
+		}
+		return "", u.saveLesson(ctx, imp, uid, lesson)
+	}
+
+	rule, err := ical.ParseRecurrence(rruleProp.Value, time.Local)
+	if err != nil {
+		return "RRULEを解析できません", nil
+	}
+	if rule.Freq != ical.FreqWeekly || rule.Interval != 1 || len(rule.Unsupported) > 0 {
+		return "毎週の繰り返し（FREQ=WEEKLY、INTERVAL=1）以外のRRULEには対応していません", nil
+	}
+
+	weekdays := rule.ByDay
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{start.Weekday()}
+	}
+
+	// 最終日（UNTIL・COUNT）
+	var until *time.Time
+	switch {
+	case rule.Until != nil:
+		date := calendarDate(rule.Until.In(time.Local))
+		until = &date
+	case rule.Count > 0:
+		date := calendarDate(lastWeeklyDate(start, weekdays, rule.Count))
+		until = &date
+	}
+
+	created := make([]model.Lesson, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		lesson := &model.Lesson{
+			SubjectID: subject.ID,
+			RoomID:    room.ID,
+			DayOfWeek: int(weekday),
+			StartTime: startClock,
+			EndTime:   endClock,
+			Until:     until,
+		}
+		if err := u.saveLesson(ctx, imp, uid, lesson); err != nil {
+			return "", err
+		}
+		created = append(created, *lesson)
+	}
+
+	// 除外日は休講の授業変更にする
+	for _, prop := range event.GetAll("EXDATE") {
+		dates, err := ical.ParseDateTimeList(&prop, time.Local)
+		if err != nil {
+			continue
+		}
+		for _, date := range dates {
+			date = date.In(time.Local)
+			for _, lesson := range created {
+				if lesson.DayOfWeek != int(date.Weekday()) {
+					continue
+				}
+				override := &model.LessonOverride{
+					LessonID:  lesson.ID,
+					OrgID:     imp.orgID,
+					Date:      calendarDate(date),
+					Cancelled: true,
+					Reason:    "カレンダーの除外日",
+				}
+				if err := u.addOverride(ctx, imp, override); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// importException 繰り返しのうち変更・キャンセルされた回を授業変更として作成（取り込めない場合は理由を返す）
+func (u *LessonImportUsecase) importException(ctx context.Context, imp *lessonImport, event *ical.Component) (string, error) {
+	recurrenceID, _, err := ical.ParseDateTime(event.Get("RECURRENCE-ID"), time.Local)
+	if err != nil {
+		return "RECURRENCE-IDを解析できません", nil
+	}
+	recurrenceID = recurrenceID.In(time.Local)
+
+	var lesson *model.Lesson
+	for _, l := range imp.lessons[strings.TrimSpace(event.Value("UID"))] {
+		if l.Date == nil && l.DayOfWeek == int(recurrenceID.Weekday()) {
+			l := l
+			lesson = &l
+			break
+		}
+	}
+	if lesson == nil {
+		return "変更元の繰り返しの予定が取り込まれていません", nil
+	}
+
+	override := &model.LessonOverride{
+		LessonID: lesson.ID,
+		OrgID:    imp.orgID,
+		Date:     calendarDate(recurrenceID),
+	}
+
+	if strings.EqualFold(event.Value("STATUS"), "CANCELLED") {
+		override.Cancelled = true
+		override.Reason = "カレンダーでキャンセルされた回"
+		return "", u.addOverride(ctx, imp, override)
+	}
+
+	start, end, reason := eventTimes(event)
+	if reason != "" {
+		return reason, nil
+	}
+	if !calendarDate(start).Equal(override.Date) {
+		return "別の日への振替には対応していません（日付指定の授業として登録してください）", nil
+	}
+
+	room, reason, err := u.resolveRoom(ctx, imp, event)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+
+	startClock, endClock := lessonClock(start), lessonClock(end)
+	override.Reason = "カレンダーで変更された回"
+	if room.ID != lesson.RoomID {
+		override.RoomID = &room.ID
+	}
+	if !sameClock(startClock, lesson.StartTime) {
+		override.StartTime = &startClock
+	}
+	if !sameClock(endClock, lesson.EndTime) {
+		override.EndTime = &endClock
+	}
+	if override.RoomID == nil && override.StartTime == nil && override.EndTime == nil {
+		return "", nil
+	}
+	return "", u.addOverride(ctx, imp, override)
+}
+
+// resolveRoom LOCATIONを組織部屋IDとして部屋を取得（見つからない場合は理由を返す）
+func (u *LessonImportUsecase) resolveRoom(ctx context.Context, imp *lessonImport, event *ical.Component) (*model.Room, string, error) {
+	location := strings.TrimSpace(ical.UnescapeText(event.Value("LOCATION")))
+	if location == "" {
+		return nil, "LOCATION（組織部屋ID）がありません", nil
+	}
+	if room, ok := imp.rooms[location]; ok {
+		return room, "", nil
+	}
+
+	room, err := u.roomService.GetByOrgRoomID(ctx, imp.orgID, location)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil, fmt.Sprintf("組織部屋ID「%s」の部屋が存在しません", location), nil
+		}
+		return nil, "", err
+	}
+	imp.rooms[location] = room
+	return room, "", nil
+}
+
+// resolveSubject 教科名で教科を取得（存在しない場合は作成）
+func (u *LessonImportUsecase) resolveSubject(ctx context.Context, imp *lessonImport, name string, year int) (*model.Subject, error) {
+	if subject, ok := imp.subjects[name]; ok {
+		return subject, nil
+	}
+	subject, err := u.subjectService.Create(ctx, name, year, imp.orgID)
+	if err != nil {
+		return nil, err
+	}
+	imp.subjects[name] = subject
+	return subject, nil
+}
+
+// saveLesson 予定のUIDに対応する授業を作成または更新して記録
+func (u *LessonImportUsecase) saveLesson(ctx context.Context, imp *lessonImport, uid string, lesson *model.Lesson) error {
+	lesson.OrgID = imp.orgID
+	lesson.ICalUID = &uid
+	created, err := u.lessonService.UpsertByICalUID(ctx, lesson)
+	if err != nil {
+		return err
+	}
+	if !created {
+		imp.result.Updated++
+	}
+	imp.addLesson(uid, *lesson)
+	return nil
+}
+
+// addOverride 授業変更を作成または更新（この取り込みで同じ授業・日付の変更を作成済みの場合は何もしない）
+func (u *LessonImportUsecase) addOverride(ctx context.Context, imp *lessonImport, override *model.LessonOverride) error {
+	key := override.LessonID + "/" + override.Date.Format("2006-01-02")
+	if imp.overridden[key] {
+		return nil
+	}
+	if err := u.overrideService.Upsert(ctx, override); err != nil {
+		return err
+	}
+	imp.overridden[key] = true
+	imp.result.Overrides = append(imp.result.Overrides, *override)
+	return nil
+}
+
+// addLesson 作成した授業を記録
+func (imp *lessonImport) addLesson(uid string, lesson model.Lesson) {
+	imp.lessons[uid] = append(imp.lessons[uid], lesson)
+	imp.result.Lessons = append(imp.result.Lessons, lesson)
+}
+
+// skip 取り込まなかった予定を記録
+func (imp *lessonImport) skip(event *ical.Component, reason string) {
+	imp.result.Skipped = append(imp.result.Skipped, LessonImportSkip{
+		UID:     event.Value("UID"),
+		Summary: ical.UnescapeText(event.Value("SUMMARY")),
+		Reason:  reason,
+	})
+}
+
+// eventTimes 予定の開始・終了日時（サーバーのタイムゾーン）を取得（授業にできない場合は理由を返す）
+func eventTimes(event *ical.Component) (time.Time, time.Time, string) {
+	start, allDay, err := ical.ParseDateTime(event.Get("DTSTART"), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, "DTSTARTを解析できません"
+	}
+	if allDay {
+		return time.Time{}, time.Time{}, "終日の予定は取り込めません"
+	}
+	if event.Get("DTEND") == nil {
+		return time.Time{}, time.Time{}, "DTENDがありません"
+	}
+	end, _, err := ical.ParseDateTime(event.Get("DTEND"), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, "DTENDを解析できません"
+	}
+
+	start, end = start.In(time.Local), end.In(time.Local)
+	if !end.After(start) || !calendarDate(end).Equal(calendarDate(start)) {
+		return time.Time{}, time.Time{}, "終了日時は開始日時より後の同じ日である必要があります"
+	}
+	return start, end, ""
+}
+
+// lessonClock 授業の時刻として保存する値（管理画面での登録と同じく、日付とHH:MMをUTCで保存する）
+func lessonClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// calendarDate 日付として保存する値（UTCの0時）
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sameClock 授業の時刻（UTCで保存したHH:MM）が一致するか
+func sameClock(a, b time.Time) bool {
+	a, b = a.UTC(), b.UTC()
+	return a.Hour() == b.Hour() && a.Minute() == b.Minute()
+}
+
+// lastWeeklyDate 開始日から数えてcount回目の実施日（毎週の指定曜日）
+func lastWeeklyDate(start time.Time, weekdays []time.Weekday, count int) time.Time {
+	days := make(map[time.Weekday]bool, len(weekdays))
+	for _, weekday := range weekdays {
+		days[weekday] = true
+	}
+	date := start
+	for n := 0; ; date = date.AddDate(0, 0, 1) {
+		if days[date.Weekday()] {
+			n++
+			if n == count {
+				return date
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+var (
+	ErrorLessonOverrideExists   = errors.New("この授業の指定日の変更は既に登録されています")
+	ErrorLessonOverrideEmpty    = errors.New("cancelled, room_id, start_time, end_timeのいずれかを指定してください")
+	ErrorInvalidLessonTime      = errors.New("時刻の形式が不正です（HH:MM）")
+	ErrorInvalidLessonTimeRange = errors.New("終了時刻は開始時刻より後の時刻を指定してください")
+)
+
+// LessonOverrideUsecase 授業変更（休講・教室変更・時間変更）ユースケース
+type LessonOverrideUsecase struct {
+	overrideService     *service.LessonOverrideService
+	lessonService       *service.LessonService
+	roomService         *service.RoomService
+	organizationService *service.OrganizationService
+}
+
+// NewLessonOverrideUsecase 授業変更ユースケースを作成
+func NewLessonOverrideUsecase(
+	overrideService *service.LessonOverrideService,
+	lessonService *service.LessonService,
+	roomService *service.RoomService,
+	organizationService *service.OrganizationService,
+) *LessonOverrideUsecase {
+	return &LessonOverrideUsecase{
+		overrideService:     overrideService,
+		lessonService:       lessonService,
+		roomService:         roomService,
+		organizationService: organizationService,
+	}
+}
+
+// LessonOverrideRequest 授業変更の内容
+type LessonOverrideRequest struct {
+	Cancelled bool   `json:"cancelled"`
+	RoomID    string `json:"room_id"`    // 変更後の部屋（省略時は時間割の部屋）
+	StartTime string `json:"start_time"` // "10:40"（省略時は時間割の時刻）
+	EndTime   string `json:"end_time"`   // "12:10"（省略時は時間割の時刻）
+	Reason    string `json:"reason"`
+}
+
+// CreateLessonOverrideRequest 授業変更作成リクエスト
+type CreateLessonOverrideRequest struct {
+	OrgID    string `json:"org_id" validate:"required"`
+	LessonID string `json:"lesson_id" validate:"required"`
+	Date     string `json:"date" validate:"required"` // "2025-10-10"
+	LessonOverrideRequest
+}
+
+// UpdateLessonOverrideRequest 授業変更更新リクエスト（変更内容をすべて置き換える）
+type UpdateLessonOverrideRequest struct {
+	LessonOverrideRequest
+}
+
+// CreateLessonOverride 授業変更を作成
+func (u *LessonOverrideUsecase) CreateLessonOverride(ctx context.Context, req *CreateLessonOverrideRequest) (*model.LessonOverride, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授業の存在確認
+	lesson, err := u.lessonService.GetByID(ctx, req.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授業が指定された組織に属しているかチェック
+	if lesson.OrgID != req.OrgID {
+		return nil, errors.New("指定された授業は組織に属していません")
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, ErrorInvalidDate
+	}
+
+	// 同じ授業・日付の重複確認
+	if _, err := u.overrideService.GetByLessonAndDate(ctx, lesson.ID, date); err == nil {
+		return nil, ErrorLessonOverrideExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	override := &model.LessonOverride{
+		LessonID: lesson.ID,
+		OrgID:    lesson.OrgID,
+		Date:     date,
+	}
+	if err := u.applyRequest(ctx, override, lesson, &req.LessonOverrideRequest); err != nil {
+		return nil, err
+	}
+
+	if err := u.overrideService.Create(ctx, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// GetLessonOverridesByOrgID 組織IDで授業変更一覧を取得（from・toは省略可）
+func (u *LessonOverrideUsecase) GetLessonOverridesByOrgID(ctx context.Context, orgID, from, to string) ([]model.LessonOverride, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromDate, toDate time.Time
+	if from != "" {
+		if fromDate, err = time.Parse("2006-01-02", from); err != nil {
+			return nil, ErrorInvalidDate
+		}
+	}
+	if to != "" {
+		if toDate, err = time.Parse("2006-01-02", to); err != nil {
+			return nil, ErrorInvalidDate
+		}
+	}
+
+	return u.overrideService.GetByOrgID(ctx, orgID, fromDate, toDate)
+}
+
+// UpdateLessonOverride 授業変更を更新
+func (u *LessonOverrideUsecase) UpdateLessonOverride(ctx context.Context, orgID, overrideID string, req *UpdateLessonOverrideRequest) (*model.LessonOverride, error) {
+	override, err := u.getLessonOverrideInOrg(ctx, orgID, overrideID)
+	if err != nil {
+		return nil, err
+	}
+
+	lesson, err := u.lessonService.GetByID(ctx, override.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.applyRequest(ctx, override, lesson, &req.LessonOverrideRequest); err != nil {
+		return nil, err
+	}
+
+	if err := u.overrideService.Update(ctx, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// DeleteLessonOverride 授業変更を削除（時間割どおりの実施に戻す）
+func (u *LessonOverrideUsecase) DeleteLessonOverride(ctx context.Context, orgID, overrideID string) error {
+	if _, err := u.getLessonOverrideInOrg(ctx, orgID, overrideID); err != nil {
+		return err
+	}
+	return u.overrideService.Delete(ctx, overrideID)
+}
+
+// getLessonOverrideInOrg 組織に属する授業変更を取得
+func (u *LessonOverrideUsecase) getLessonOverrideInOrg(ctx context.Context, orgID, overrideID string) (*model.LessonOverride, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授業変更の存在確認
+	override, err := u.overrideService.GetByID(ctx, overrideID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授業変更が指定された組織に属しているかチェック
+	if override.OrgID != orgID {
+		return nil, errors.New("指定された授業変更は組織に属していません")
+	}
+	return override, nil
+}
+
+// applyRequest リクエストの内容を検証して授業変更に設定
+func (u *LessonOverrideUsecase) applyRequest(ctx context.Context, override *model.LessonOverride, lesson *model.Lesson, req *LessonOverrideRequest) error {
+	if !req.Cancelled && req.RoomID == "" && req.StartTime == "" && req.EndTime == "" {
+		return ErrorLessonOverrideEmpty
+	}
+
+	// 変更後の部屋の確認
+	var roomID *string
+	if req.RoomID != "" {
+		room, err := u.roomService.GetByID(ctx, req.RoomID)
+		if err != nil {
+			return err
+		}
+		if room.OrgID != lesson.OrgID {
+			return errors.New("指定された部屋は組織に属していません")
+		}
+		roomID = &room.ID
+	}
+
+	// 変更後の時刻（時間割と同じくUTCのHH:MMで保存する）
+	startTime, err := parseOverrideTime(req.StartTime, override.Date)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseOverrideTime(req.EndTime, override.Date)
+	if err != nil {
+		return err
+	}
+	shifted := *lesson
+	if startTime != nil {
+		shifted.StartTime = *startTime
+	}
+	if endTime != nil {
+		shifted.EndTime = *endTime
+	}
+	if start, end := shifted.TimesOn(override.Date); !end.After(start) {
+		return ErrorInvalidLessonTimeRange
+	}
+
+	override.Cancelled = req.Cancelled
+	override.RoomID = roomID
+	override.StartTime = startTime
+	override.EndTime = endTime
+	override.Reason = req.Reason
+	return nil
+}
+
+// parseOverrideTime HH:MM形式の時刻を解析（空の場合はnil）
+func parseOverrideTime(value string, date time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return nil, ErrorInvalidLessonTime
+	}
+	t := time.Date(date.Year(), date.Month(), date.Day(), parsed.Hour(), parsed.Minute(), 0, 0, time.UTC)
+	return &t, nil
+}
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/floorplan"
+)
+
+var (
+	ErrorUnsupportedFormat = errors.New("対応していない形式です（svgまたはpng）")
+)
+
+// MistUsecase Mist連携の管理向けユースケース
+type MistUsecase struct {
+	mapService      *service.MapService
+	zoneService     *service.ZoneService
+	deviceService   *service.DeviceService
+	roomService     *service.RoomService
+	mistSyncService *service.MistSyncService
+}
+
+// NewMistUsecase Mist連携の管理向けユースケースを作成
+func NewMistUsecase(mapService *service.MapService, zoneService *service.ZoneService, deviceService *service.DeviceService, roomService *service.RoomService, mistSyncService *service.MistSyncService) *MistUsecase {
+	return &MistUsecase{
+		mapService:      mapService,
+		zoneService:     zoneService,
+		deviceService:   deviceService,
+		roomService:     roomService,
+		mistSyncService: mistSyncService,
+	}
+}
+
+// ZoneOccupant ゾーン内で検知されたデバイスと紐づくユーザー
+type ZoneOccupant struct {
+	DeviceID           string  `json:"device_id"`
+	Type               string  `json:"type"` // "sdk", "wifi"
+	Name               string  `json:"name,omitempty"`
+	X                  float64 `json:"x"`
+	Y                  float64 `json:"y"`
+	LastSeen           float64 `json:"last_seen"`
+	UserID             string  `json:"user_id,omitempty"`
+	UserMail           string  `json:"user_mail,omitempty"`
+	OrgID              string  `json:"org_id,omitempty"`
+	AuthenticatedToday bool    `json:"authenticated_today"`
+}
+
+// ZoneOccupantsResponse ゾーン内のデバイス一覧レスポンス
+type ZoneOccupantsResponse struct {
+	Zone         *model.Zone    `json:"zone,omitempty"`
+	Occupants    []ZoneOccupant `json:"occupants"`
+	Registered   int            `json:"registered"`
+	Unregistered int            `json:"unregistered"`
+}
+
+// GetMaps 同期済みのマップ一覧を取得
+func (u *MistUsecase) GetMaps(ctx context.Context) ([]model.Map, error) {
+	return u.mapService.GetStored(ctx)
+}
+
+// GetZones 同期済みのゾーン一覧を取得（MistマップIDで絞り込み可能）
+func (u *MistUsecase) GetZones(ctx context.Context, mistMapID string) ([]model.Zone, error) {
+	return u.zoneService.GetStored(ctx, mistMapID)
+}
+
+// GetMapImage マップ画像を取得
+func (u *MistUsecase) GetMapImage(ctx context.Context, mistMapID string) ([]byte, error) {
+	return u.mapService.GetImage(ctx, mistMapID)
+}
+
+// GetZoneOccupants ゾーン内のクライアントを取得し、登録済みデバイスのユーザーに紐づける
+// orgIDを指定した場合は、他の組織のユーザーに登録されたデバイスを除外する
+func (u *MistUsecase) GetZoneOccupants(ctx context.Context, mistZoneID, orgID string) (*ZoneOccupantsResponse, error) {
+	clients, err := u.zoneService.GetClientsByZoneID(ctx, mistZoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ZoneOccupantsResponse{Occupants: []ZoneOccupant{}}
+
+	// 同期済みのゾーン情報（未同期の場合は省略）
+	zone, err := u.zoneService.GetStoredByMistZoneID(ctx, mistZoneID)
+	if err != nil && !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+	response.Zone = zone
+
+	for _, sc := range clients.SDKClients {
+		occupant := ZoneOccupant{
+			DeviceID: sc.ID,
+			Type:     "sdk",
+			Name:     sc.Name,
+			X:        sc.X,
+			Y:        sc.Y,
+			LastSeen: sc.LastSeen,
+		}
+		if err := u.resolveUser(ctx, &occupant); err != nil {
+			return nil, err
+		}
+		if orgID != "" && occupant.OrgID != "" && occupant.OrgID != orgID {
+			continue
+		}
+		response.addOccupant(occupant)
+	}
+
+	for _, wc := range clients.Clients {
+		occupant := ZoneOccupant{
+			DeviceID: wc.Mac,
+			Type:     "wifi",
+			Name:     wc.HostName,
+			X:        wc.X,
+			Y:        wc.Y,
+			LastSeen: wc.LastSeen,
+		}
+		if err := u.resolveUser(ctx, &occupant); err != nil {
+			return nil, err
+		}
+		if orgID != "" && occupant.OrgID != "" && occupant.OrgID != orgID {
+			continue
+		}
+		response.addOccupant(occupant)
+	}
+
+	return response, nil
+}
+
+// FloorPlanRequest フロアプラン描画リクエスト
+type FloorPlanRequest struct {
+	MistMapID string
+	OrgID     string // 指定した場合はその組織の部屋のみラベル付けする（空の場合は全組織、スーパー管理者のみ）
+	Format    string // "svg", "png"
+	WithDots  bool   // 匿名化したデバイスの位置を描画するか
+}
+
+// RenderFloorPlan フロアプランに部屋の範囲と在室数を重ねて描画
+// 戻り値は画像データとContent-Type
+func (u *MistUsecase) RenderFloorPlan(ctx context.Context, req *FloorPlanRequest) ([]byte, string, error) {
+	if req.Format != "svg" && req.Format != "png" {
+		return nil, "", ErrorUnsupportedFormat
+	}
+
+	background, err := u.mapService.GetImage(ctx, req.MistMapID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	plan := &floorplan.Plan{Background: background}
+	if m, err := u.mapService.GetStoredByMistMapID(ctx, req.MistMapID); err == nil {
+		plan.Title = m.Name
+		plan.Width = int(m.Width)
+		plan.Height = int(m.Height)
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, "", err
+	}
+
+	zones, err := u.zoneService.GetStored(ctx, req.MistMapID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// MistゾーンID → 部屋
+	var rooms []model.Room
+	if req.OrgID != "" {
+		rooms, err = u.roomService.GetByOrgID(ctx, req.OrgID)
+	} else {
+		rooms, err = u.roomService.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	roomsByZone := make(map[string]model.Room, len(rooms))
+	for _, room := range rooms {
+		if room.MistZoneID != "" {
+			roomsByZone[room.MistZoneID] = room
+		}
+	}
+
+	occupancy, err := u.zoneService.GetOccupancy(ctx, req.MistMapID, req.WithDots)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, zone := range zones {
+		region := floorplan.Region{
+			Label:      zone.Name,
+			ASCIILabel: zone.Name,
+			Count:      occupancy.Counts[zone.MistZoneID],
+		}
+		if room, ok := roomsByZone[zone.MistZoneID]; ok {
+			region.Linked = true
+			region.ASCIILabel = room.OrgRoomID
+			region.Label = room.Name
+			if region.Label == "" {
+				region.Label = room.OrgRoomID
+			}
+		}
+		for _, v := range zone.Vertices {
+			region.Vertices = append(region.Vertices, floorplan.Point{X: v.X, Y: v.Y})
+		}
+		plan.Regions = append(plan.Regions, region)
+	}
+
+	if req.WithDots {
+		for _, p := range occupancy.Positions {
+			plan.Dots = append(plan.Dots, floorplan.Point{X: p.X, Y: p.Y})
+		}
+	}
+
+	if req.Format == "png" {
+		image, err := floorplan.RenderPNG(plan)
+		if err != nil {
+			return nil, "", fmt.Errorf("フロアプラン描画エラー: %w", err)
+		}
+		return image, "image/png", nil
+	}
+
+	image, err := floorplan.RenderSVG(plan)
+	if err != nil {
+		return nil, "", fmt.Errorf("フロアプラン描画エラー: %w", err)
+	}
+	return image, "image/svg+xml", nil
+}
+
+// Sync Mistのマップ・ゾーン定義を即時同期
+func (u *MistUsecase) Sync(ctx context.Context) (*service.MistSyncResult, error) {
+	return u.mistSyncService.Sync(ctx)
+}
+
+// resolveUser デバイスIDから登録済みのユーザーを設定（未登録の場合は何もしない）
+func (u *MistUsecase) resolveUser(ctx context.Context, occupant *ZoneOccupant) error {
+	device, err := u.deviceService.GetByDeviceID(ctx, occupant.DeviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	occupant.UserID = device.UserID
+	occupant.UserMail = device.User.Mail
+	occupant.OrgID = device.User.OrgID
+	occupant.AuthenticatedToday = device.IsAuthenticatedToday()
+	return nil
+}
+
+func (r *ZoneOccupantsResponse) addOccupant(occupant ZoneOccupant) {
+	r.Occupants = append(r.Occupants, occupant)
+	if occupant.UserID != "" {
+		r.Registered++
+	} else {
+		r.Unregistered++
+	}
+}
@@ -5,21 +5,47 @@ import (
 	"errors"
 
 	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
 )
 
+var (
+	ErrorZoneNotFound = errors.New("指定されたMistゾーンが存在しません")
+)
+
 // RoomUsecase 部屋ユースケース
 type RoomUsecase struct {
 	roomService         *service.RoomService
 	organizationService *service.OrganizationService
+	zoneService         *service.ZoneService
 }
 
 // NewRoomUsecase 部屋ユースケースを作成
-func NewRoomUsecase(roomService *service.RoomService, organizationService *service.OrganizationService) *RoomUsecase {
+func NewRoomUsecase(roomService *service.RoomService, organizationService *service.OrganizationService, zoneService *service.ZoneService) *RoomUsecase {
 	return &RoomUsecase{
 		roomService:         roomService,
 		organizationService: organizationService,
+		zoneService:         zoneService,
+	}
+}
+
+// resolveMapID 同期済みのゾーンを確認してMistマップIDを取得（ゾーン未指定の場合は空）
+func (u *RoomUsecase) resolveMapID(ctx context.Context, mistZoneID string) (string, error) {
+	if mistZoneID == "" {
+		return "", nil
+	}
+
+	zone, err := u.zoneService.GetStoredByMistZoneID(ctx, mistZoneID)
+	if err != nil {
+		if errors.Is(err, repository.ErrorRecordNotFound) {
+			return "", ErrorZoneNotFound
+		}
+		return "", err
+	}
+	if !zone.IsActive {
+		return "", ErrorZoneNotFound
 	}
+	return zone.MistMapID, nil
 }
 
 // CreateRoomRequest 部屋作成リクエスト
@@ -39,7 +65,13 @@ func (u *RoomUsecase) CreateRoom(ctx context.Context, req *CreateRoomRequest) (*
 		return nil, err
 	}
 
-	room, err := u.roomService.Create(ctx, req.OrgID, req.OrgRoomID, req.RoomName, req.Caption, req.MistZoneID)
+	// Mistゾーンの存在確認
+	mapID, err := u.resolveMapID(ctx, req.MistZoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := u.roomService.Create(ctx, req.OrgID, req.OrgRoomID, req.RoomName, req.Caption, req.MistZoneID, mapID)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +100,21 @@ type UpdateRoomRequest struct {
 	MistZoneID string `json:"mist_zone_id"`
 }
 
+// GetRoom 組織に属する部屋を取得
+func (u *RoomUsecase) GetRoom(ctx context.Context, orgID, roomID string) (*model.Room, error) {
+	// 部屋の存在確認
+	room, err := u.roomService.GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 部屋が指定された組織に属しているかチェック
+	if room.OrgID != orgID {
+		return nil, errors.New("指定された部屋は組織に属していません")
+	}
+	return room, nil
+}
+
 // UpdateRoom 部屋を更新
 func (u *RoomUsecase) UpdateRoom(ctx context.Context, orgID, roomID string, req *UpdateRoomRequest) (*model.Room, error) {
 	// 組織の存在確認
@@ -87,8 +134,17 @@ func (u *RoomUsecase) UpdateRoom(ctx context.Context, orgID, roomID string, req
 		return nil, errors.New("指定された部屋は組織に属していません")
 	}
 
+	// Mistゾーンの存在確認（変更しない場合は既存の値を維持）
+	mapID := room.MapID
+	if req.MistZoneID != room.MistZoneID {
+		mapID, err = u.resolveMapID(ctx, req.MistZoneID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 部屋を更新
-	if err := u.roomService.Update(ctx, room, req.RoomName, req.Caption, req.MistZoneID); err != nil {
+	if err := u.roomService.Update(ctx, room, req.RoomName, req.Caption, req.MistZoneID, mapID); err != nil {
 		return nil, err
 	}
 
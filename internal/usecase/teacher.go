@@ -0,0 +1,434 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+)
+
+var (
+	ErrorNotTeacher                 = errors.New("指定されたユーザーは教員ではありません")
+	ErrorNotLessonTeacher           = errors.New("担当していない授業です")
+	ErrorTeachingAssignmentExists   = errors.New("同じ担当は既に登録されています")
+	ErrorTeachingAssignmentRequired = errors.New("subject_idまたはlesson_idを指定してください")
+	ErrorInvalidAttendanceMark      = errors.New("statusが不正です（present, excusedのいずれか）")
+	ErrorNotOnRoster                = errors.New("指定されたユーザーは授業の対象者ではありません")
+	ErrorOccurrenceCancelled        = errors.New("休講の授業です")
+)
+
+// RosterStatus 授業中の在室状況
+type RosterStatus string
+
+const (
+	RosterPresent    RosterStatus = "present"     // 在室（定刻に入室・教員が出席扱いにした）
+	RosterLate       RosterStatus = "late"        // 遅刻して入室
+	RosterLeft       RosterStatus = "left"        // 入室後に退室
+	RosterNotArrived RosterStatus = "not_arrived" // 未入室
+	RosterExcused    RosterStatus = "excused"     // 公欠
+)
+
+// RosterEntry 授業の対象者ごとの在室状況
+type RosterEntry struct {
+	User        model.User            `json:"user"`
+	Status      RosterStatus          `json:"status"`
+	LateMinutes int                   `json:"late_minutes"`
+	EntryTime   *time.Time            `json:"entry_time,omitempty"`
+	ExitTime    *time.Time            `json:"exit_time,omitempty"`
+	StayID      *int                  `json:"stay_id,omitempty"`
+	Mark        *model.AttendanceMark `json:"mark,omitempty"` // 教員による手動記録
+}
+
+// RosterSummary 授業の在室状況の集計
+type RosterSummary struct {
+	Total      int `json:"total"`
+	Present    int `json:"present"`
+	Late       int `json:"late"`
+	Left       int `json:"left"`
+	NotArrived int `json:"not_arrived"`
+	Excused    int `json:"excused"`
+}
+
+// LessonRosterView 授業の対象者の在室状況
+type LessonRosterView struct {
+	Occurrence *model.LessonOccurrence `json:"occurrence"`
+	Summary    RosterSummary           `json:"summary"`
+	Students   []RosterEntry           `json:"students"`
+}
+
+// CreateTeachingAssignmentRequest 教員の担当登録リクエスト
+// lesson_idを指定した場合はその授業のみ、subject_idのみの場合は教科のすべての授業を担当する
+type CreateTeachingAssignmentRequest struct {
+	OrgID     string `json:"org_id" validate:"required"`
+	UserID    string `json:"user_id" validate:"required"`
+	SubjectID string `json:"subject_id"`
+	LessonID  string `json:"lesson_id"`
+}
+
+// MarkAttendanceRequest 出席の手動記録リクエスト
+type MarkAttendanceRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	Status    string `json:"status" validate:"required"` // "present" or "excused"
+	Reason    string `json:"reason"`
+}
+
+// TeacherUsecase 教員向けユースケース
+type TeacherUsecase struct {
+	assignmentService   *service.TeachingAssignmentService
+	markService         *service.AttendanceMarkService
+	occurrenceService   *service.LessonOccurrenceService
+	enrollmentService   *service.EnrollmentService
+	stayService         *service.StayService
+	userService         *service.UserService
+	subjectService      *service.SubjectService
+	lessonService       *service.LessonService
+	organizationService *service.OrganizationService
+}
+
+// NewTeacherUsecase 教員向けユースケースを作成
+func NewTeacherUsecase(
+	assignmentService *service.TeachingAssignmentService,
+	markService *service.AttendanceMarkService,
+	occurrenceService *service.LessonOccurrenceService,
+	enrollmentService *service.EnrollmentService,
+	stayService *service.StayService,
+	userService *service.UserService,
+	subjectService *service.SubjectService,
+	lessonService *service.LessonService,
+	organizationService *service.OrganizationService,
+) *TeacherUsecase {
+	return &TeacherUsecase{
+		assignmentService:   assignmentService,
+		markService:         markService,
+		occurrenceService:   occurrenceService,
+		enrollmentService:   enrollmentService,
+		stayService:         stayService,
+		userService:         userService,
+		subjectService:      subjectService,
+		lessonService:       lessonService,
+		organizationService: organizationService,
+	}
+}
+
+// CreateTeachingAssignment 教員の担当を登録
+func (u *TeacherUsecase) CreateTeachingAssignment(ctx context.Context, req *CreateTeachingAssignmentRequest) (*model.TeachingAssignment, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SubjectID == "" && req.LessonID == "" {
+		return nil, ErrorTeachingAssignmentRequired
+	}
+
+	// 教員の確認
+	user, err := u.userService.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrgID != req.OrgID {
+		return nil, errors.New("指定されたユーザーは組織に属していません")
+	}
+	if !user.IsTeacher() {
+		return nil, ErrorNotTeacher
+	}
+
+	// 授業を指定した場合は授業の教科を担当の教科にする
+	subjectID := req.SubjectID
+	var lessonID *string
+	if req.LessonID != "" {
+		lesson, err := u.lessonService.GetByID(ctx, req.LessonID)
+		if err != nil {
+			return nil, err
+		}
+		if lesson.OrgID != req.OrgID {
+			return nil, errors.New("指定された授業は組織に属していません")
+		}
+		if subjectID != "" && subjectID != lesson.SubjectID {
+			return nil, errors.New("指定された授業は教科の授業ではありません")
+		}
+		subjectID = lesson.SubjectID
+		lessonID = &lesson.ID
+	} else {
+		subject, err := u.subjectService.GetByID(ctx, subjectID)
+		if err != nil {
+			return nil, err
+		}
+		if subject.OrgID != req.OrgID {
+			return nil, errors.New("指定された教科は組織に属していません")
+		}
+	}
+
+	// 重複確認
+	if _, err := u.assignmentService.GetByTarget(ctx, user.ID, subjectID, lessonID); err == nil {
+		return nil, ErrorTeachingAssignmentExists
+	} else if !errors.Is(err, repository.ErrorRecordNotFound) {
+		return nil, err
+	}
+
+	return u.assignmentService.Create(ctx, req.OrgID, user.ID, subjectID, lessonID)
+}
+
+// GetTeachingAssignmentsByOrgID 組織IDで教員の担当一覧を取得（userIDは省略可）
+func (u *TeacherUsecase) GetTeachingAssignmentsByOrgID(ctx context.Context, orgID, userID string) ([]model.TeachingAssignment, error) {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.assignmentService.GetByOrgID(ctx, orgID, userID)
+}
+
+// DeleteTeachingAssignment 教員の担当を削除
+func (u *TeacherUsecase) DeleteTeachingAssignment(ctx context.Context, orgID, assignmentID string) error {
+	// 組織の存在確認
+	_, err := u.organizationService.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	assignment, err := u.assignmentService.GetByID(ctx, assignmentID)
+	if err != nil {
+		return err
+	}
+
+	// 担当が指定された組織に属しているかチェック
+	if assignment.OrgID != orgID {
+		return errors.New("指定された担当は組織に属していません")
+	}
+
+	return u.assignmentService.Delete(ctx, assignmentID)
+}
+
+// GetCurrentLessons 教員が担当する、現在実施中（入室可能時刻〜終了時刻）の実施回を取得
+func (u *TeacherUsecase) GetCurrentLessons(ctx context.Context, teacherID string, now time.Time) ([]model.LessonOccurrence, error) {
+	teacher, assignments, err := u.getTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := u.occurrenceService.GetByDate(ctx, teacher.OrgID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultAttendanceConfig()
+	current := []model.LessonOccurrence{}
+	for _, occurrence := range occurrences {
+		openAt := occurrence.StartAt.Add(-time.Duration(config.EarlyEntryMinutes) * time.Minute)
+		if now.Before(openAt) || now.After(occurrence.EndAt) {
+			continue
+		}
+		if teaches(assignments, &occurrence) {
+			current = append(current, occurrence)
+		}
+	}
+	return current, nil
+}
+
+// GetLessonRoster 教員が担当する実施回の対象者ごとの在室状況を取得
+func (u *TeacherUsecase) GetLessonRoster(ctx context.Context, teacherID, occurrenceID string) (*LessonRosterView, error) {
+	occurrence, err := u.getTaughtOccurrence(ctx, teacherID, occurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := u.getStudents(ctx, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	stays, err := u.stayService.GetByLessonAndDate(ctx, occurrence.LessonID, occurrence.Date)
+	if err != nil {
+		return nil, err
+	}
+	stayByUser := make(map[string]model.Stay, len(stays))
+	for _, stay := range stays {
+		stayByUser[stay.UserID] = stay
+	}
+
+	marks, err := u.markService.GetByLessonAndDate(ctx, occurrence.LessonID, occurrence.Date)
+	if err != nil {
+		return nil, err
+	}
+	markByUser := make(map[string]model.AttendanceMark, len(marks))
+	for _, mark := range marks {
+		markByUser[mark.UserID] = mark
+	}
+
+	lesson := occurrence.LessonOnDate()
+	view := &LessonRosterView{
+		Occurrence: occurrence,
+		Students:   make([]RosterEntry, 0, len(students)),
+	}
+	for _, student := range students {
+		entry := RosterEntry{User: student, Status: RosterNotArrived}
+
+		if stay, ok := stayByUser[student.ID]; ok {
+			stayID := stay.ID
+			entry.StayID = &stayID
+			entry.EntryTime = &stay.CreatedAt
+			entry.ExitTime = stay.LeavedAt
+			entry.LateMinutes = CalculateLateMinutes(stay, &lesson)
+			switch {
+			case !stay.IsActive:
+				entry.Status = RosterLeft
+			case entry.LateMinutes > 0:
+				entry.Status = RosterLate
+			default:
+				entry.Status = RosterPresent
+			}
+		}
+
+		// 教員による手動記録を優先する
+		if mark, ok := markByUser[student.ID]; ok {
+			entry.Mark = &mark
+			switch {
+			case mark.Status == model.AttendanceMarkExcused:
+				entry.Status = RosterExcused
+			case entry.Status == RosterNotArrived:
+				entry.Status = RosterPresent
+			}
+		}
+
+		switch entry.Status {
+		case RosterPresent:
+			view.Summary.Present++
+		case RosterLate:
+			view.Summary.Late++
+		case RosterLeft:
+			view.Summary.Left++
+		case RosterNotArrived:
+			view.Summary.NotArrived++
+		case RosterExcused:
+			view.Summary.Excused++
+		}
+		view.Students = append(view.Students, entry)
+	}
+	view.Summary.Total = len(view.Students)
+	return view, nil
+}
+
+// MarkAttendance 教員が担当する実施回の対象者の出席を手動で記録（出席扱い・公欠）
+func (u *TeacherUsecase) MarkAttendance(ctx context.Context, teacherID, occurrenceID string, req *MarkAttendanceRequest) (*model.AttendanceMark, error) {
+	if !model.IsValidAttendanceMarkStatus(req.Status) {
+		return nil, ErrorInvalidAttendanceMark
+	}
+
+	occurrence, err := u.getTaughtOccurrence(ctx, teacherID, occurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	if occurrence.IsCancelled() {
+		return nil, ErrorOccurrenceCancelled
+	}
+
+	if err := u.checkOnRoster(ctx, occurrence, req.StudentID); err != nil {
+		return nil, err
+	}
+
+	return u.markService.Mark(ctx, occurrence, req.StudentID, req.Status, req.Reason, teacherID)
+}
+
+// UnmarkAttendance 教員による出席の手動記録を取り消す（滞在ログによる判定に戻る）
+func (u *TeacherUsecase) UnmarkAttendance(ctx context.Context, teacherID, occurrenceID, studentID string) error {
+	occurrence, err := u.getTaughtOccurrence(ctx, teacherID, occurrenceID)
+	if err != nil {
+		return err
+	}
+
+	mark, err := u.markService.GetByUserLessonDate(ctx, studentID, occurrence.LessonID, occurrence.Date)
+	if err != nil {
+		return err
+	}
+	return u.markService.Delete(ctx, mark.ID)
+}
+
+// getTeacher 教員とその担当一覧を取得
+func (u *TeacherUsecase) getTeacher(ctx context.Context, teacherID string) (*model.User, []model.TeachingAssignment, error) {
+	teacher, err := u.userService.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !teacher.IsTeacher() {
+		return nil, nil, ErrorNotTeacher
+	}
+
+	assignments, err := u.assignmentService.GetByUserID(ctx, teacher.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return teacher, assignments, nil
+}
+
+// getTaughtOccurrence 教員が担当する実施回を取得
+func (u *TeacherUsecase) getTaughtOccurrence(ctx context.Context, teacherID, occurrenceID string) (*model.LessonOccurrence, error) {
+	teacher, assignments, err := u.getTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence, err := u.occurrenceService.GetByID(ctx, occurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	if occurrence.OrgID != teacher.OrgID || !teaches(assignments, occurrence) {
+		return nil, ErrorNotLessonTeacher
+	}
+	return occurrence, nil
+}
+
+// getStudents 実施回の対象者（履修者・クラスの所属者。教員は除く）を取得
+func (u *TeacherUsecase) getStudents(ctx context.Context, occurrence *model.LessonOccurrence) ([]model.User, error) {
+	roster, err := u.enrollmentService.GetRoster(ctx, occurrence.SubjectID, occurrence.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := u.userService.GetByOrgID(ctx, occurrence.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	students := make([]model.User, 0, len(users))
+	for _, user := range users {
+		if !user.IsTeacher() && roster.Includes(user.ID) {
+			students = append(students, user)
+		}
+	}
+	return students, nil
+}
+
+// checkOnRoster ユーザーが実施回の対象者か確認
+func (u *TeacherUsecase) checkOnRoster(ctx context.Context, occurrence *model.LessonOccurrence, studentID string) error {
+	student, err := u.userService.GetByID(ctx, studentID)
+	if err != nil {
+		return err
+	}
+	if student.OrgID != occurrence.OrgID || student.IsTeacher() {
+		return ErrorNotOnRoster
+	}
+
+	roster, err := u.enrollmentService.GetRoster(ctx, occurrence.SubjectID, occurrence.GroupID)
+	if err != nil {
+		return err
+	}
+	if !roster.Includes(student.ID) {
+		return ErrorNotOnRoster
+	}
+	return nil
+}
+
+// teaches 担当に実施回の授業が含まれるか
+func teaches(assignments []model.TeachingAssignment, occurrence *model.LessonOccurrence) bool {
+	for _, assignment := range assignments {
+		if assignment.Covers(occurrence.LessonID, occurrence.SubjectID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shakkuuu/ed-mist-backend/internal/model"
+	"github.com/Shakkuuu/ed-mist-backend/internal/repository"
+	"github.com/Shakkuuu/ed-mist-backend/internal/service"
+	"github.com/Shakkuuu/ed-mist-backend/pkg/ical"
+)
+
+// TimetableFeedUsecase 時間割のiCalendar配信ユースケース
+type TimetableFeedUsecase struct {
+	transactor        *repository.Transactor
+	lessonService     *service.LessonService
+	overrideService   *service.LessonOverrideService
+	calendarService   *service.CalendarService
+	enrollmentService *service.EnrollmentService
+	userService       *service.UserService
+	roomService       *service.RoomService
+	feedTokenService  *service.FeedTokenService
+}
+
+// NewTimetableFeedUsecase 時間割のiCalendar配信ユースケースを作成
+func NewTimetableFeedUsecase(
+	transactor *repository.Transactor,
+	lessonService *service.LessonService,
+	overrideService *service.LessonOverrideService,
+	calendarService *service.CalendarService,
+	enrollmentService *service.EnrollmentService,
+	userService *service.UserService,
+	roomService *service.RoomService,
+	feedTokenService *service.FeedTokenService,
+) *TimetableFeedUsecase {
+	return &TimetableFeedUsecase{
+		transactor:        transactor,
+		lessonService:     lessonService,
+		overrideService:   overrideService,
+		calendarService:   calendarService,
+		enrollmentService: enrollmentService,
+		userService:       userService,
+		roomService:       roomService,
+		feedTokenService:  feedTokenService,
+	}
+}
+
+// FeedTokenResponse 発行した時間割の配信トークン
+type FeedTokenResponse struct {
+	Kind      string    `json:"kind"` // "user", "room"
+	TargetID  string    `json:"target_id"`
+	Token     string    `json:"token"` // 発行時のみ取得できる
+	Path      string    `json:"path"`  // 購読用のURLのパス
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IssueFeedToken 配信対象（ユーザー・部屋）の購読用のトークンを発行（以前に発行したURLは使えなくなる）
+// orgIDが空でない場合は、その組織以外の対象は見つからないものとして扱う
+func (u *TimetableFeedUsecase) IssueFeedToken(ctx context.Context, kind, targetID, orgID string) (*FeedTokenResponse, error) {
+	targetOrgID, err := u.feedTargetOrgID(ctx, kind, targetID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *FeedTokenResponse
+	err = u.transactor.Run(ctx, func(ctx context.Context) error {
+		token, feedToken, err := u.feedTokenService.Issue(ctx, targetOrgID, kind, targetID)
+		if err != nil {
+			return err
+		}
+		response = &FeedTokenResponse{
+			Kind:      feedToken.Kind,
+			TargetID:  feedToken.TargetID,
+			Token:     token,
+			Path:      "/feeds/" + token + ".ics",
+			CreatedAt: feedToken.CreatedAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RevokeFeedToken 配信対象の購読用のトークンを失効させる
+// orgIDが空でない場合は、その組織以外の対象は見つからないものとして扱う
+func (u *TimetableFeedUsecase) RevokeFeedToken(ctx context.Context, kind, targetID, orgID string) error {
+	if _, err := u.feedTargetOrgID(ctx, kind, targetID, orgID); err != nil {
+		return err
+	}
+	_, err := u.feedTokenService.Revoke(ctx, kind, targetID)
+	return err
+}
+
+// FeedByToken 購読用のトークンに対応する時間割のiCalendarを作成
+// 存在しない・失効したトークンは見つからないものとして扱う
+func (u *TimetableFeedUsecase) FeedByToken(ctx context.Context, token string) (*ical.Component, error) {
+	feedToken, err := u.feedTokenService.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !feedToken.IsActive() {
+		return nil, repository.ErrorRecordNotFound
+	}
+
+	switch feedToken.Kind {
+	case model.FeedKindUser:
+		return u.UserFeed(ctx, feedToken.TargetID, feedToken.OrgID)
+	case model.FeedKindRoom:
+		return u.RoomFeed(ctx, feedToken.TargetID, feedToken.OrgID)
+	}
+	return nil, repository.ErrorRecordNotFound
+}
+
+// feedTargetOrgID 配信対象が所属する組織のID
+// orgIDが空でない場合は、その組織以外の対象は見つからないものとして扱う
+func (u *TimetableFeedUsecase) feedTargetOrgID(ctx context.Context, kind, targetID, orgID string) (string, error) {
+	var targetOrgID string
+	switch kind {
+	case model.FeedKindUser:
+		user, err := u.userService.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		targetOrgID = user.OrgID
+	case model.FeedKindRoom:
+		room, err := u.roomService.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		targetOrgID = room.OrgID
+	default:
+		return "", repository.ErrorRecordNotFound
+	}
+
+	if orgID != "" && targetOrgID != orgID {
+		return "", repository.ErrorRecordNotFound
+	}
+	return targetOrgID, nil
+}
+
+// UserFeed ユーザーの時間割のiCalendarを作成（履修していない教科・所属していないクラスの授業は除く）
+// orgIDが空でない場合は、その組織以外のユーザーは見つからないものとして扱う
+func (u *TimetableFeedUsecase) UserFeed(ctx context.Context, userID, orgID string) (*ical.Component, error) {
+	user, err := u.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != "" && user.OrgID != orgID {
+		return nil, repository.ErrorRecordNotFound
+	}
+	lessons, err := u.lessonService.GetByOrgID(ctx, user.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	audience, err := u.enrollmentService.GetAudience(ctx, user.OrgID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enrolled := make([]model.Lesson, 0, len(lessons))
+	for _, lesson := range lessons {
+		if audience.Includes(lesson.SubjectID, lesson.GroupID) {
+			enrolled = append(enrolled, lesson)
+		}
+	}
+	return u.build(ctx, "時間割", user.OrgID, enrolled)
+}
+
+// RoomFeed 部屋の時間割のiCalendarを作成
+// orgIDが空でない場合は、その組織以外の部屋は見つからないものとして扱う
+func (u *TimetableFeedUsecase) RoomFeed(ctx context.Context, roomID, orgID string) (*ical.Component, error) {
+	room, err := u.roomService.GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != "" && room.OrgID != orgID {
+		return nil, repository.ErrorRecordNotFound
+	}
+	lessons, err := u.lessonService.GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return u.build(ctx, room.Name+" 時間割", room.OrgID, lessons)
+}
+
+// build 授業と授業変更・休日からiCalendarを作成
+// 毎週の授業はRRULE、休講と休日はEXDATE、教室変更・時間変更はRECURRENCE-IDの予定として書き出す
+func (u *TimetableFeedUsecase) build(ctx context.Context, name, orgID string, lessons []model.Lesson) (*ical.Component, error) {
+	overrides, err := u.overrideService.GetByOrgID(ctx, orgID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	overridesByLesson := make(map[string][]model.LessonOverride)
+	for _, override := range overrides {
+		overridesByLesson[override.LessonID] = append(overridesByLesson[override.LessonID], override)
+	}
+
+	days, err := u.calendarService.GetDaysByOrgID(ctx, orgID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	var holidays []time.Time
+	for _, day := range days {
+		if day.Kind == model.CalendarDayHoliday {
+			holidays = append(holidays, localDate(day.Date))
+		}
+	}
+
+	rooms := make(map[string]*model.Room)
+	roomOf := func(id string) *model.Room {
+		if room, ok := rooms[id]; ok {
+			return room
+		}
+		room, err := u.roomService.GetByID(ctx, id)
+		if err != nil {
+			room = nil
+		}
+		rooms[id] = room
+		return room
+	}
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0", nil)
+	calendar.Add("PRODID", "-//ed-mist//timetable//JA", nil)
+	calendar.Add("CALSCALE", "GREGORIAN", nil)
+	calendar.Add("METHOD", "PUBLISH", nil)
+	calendar.AddText("X-WR-CALNAME", name)
+
+	for _, lesson := range lessons {
+		first := firstLessonDate(lesson)
+		start, end := lesson.TimesOn(first)
+
+		event := lessonEvent(lesson, start, end, lesson.Room.OrgRoomID, lesson.Room.Name, lesson.UpdatedAt)
+
+		if lesson.Date != nil {
+			// 日付指定の授業は授業変更をそのまま反映する
+			for _, override := range overridesByLesson[lesson.ID] {
+				if !localDate(override.Date).Equal(first) {
+					continue
+				}
+				if override.Cancelled {
+					event.Add("STATUS", "CANCELLED", nil)
+				} else {
+					event = overriddenEvent(lesson, override, first, roomOf)
+				}
+			}
+			calendar.Components = append(calendar.Components, event)
+			continue
+		}
+
+		rule := &ical.Recurrence{Freq: ical.FreqWeekly, Interval: 1, ByDay: []time.Weekday{time.Weekday(lesson.DayOfWeek)}}
+		var last time.Time
+		if lesson.Until != nil {
+			last = localDate(*lesson.Until)
+			_, lastEnd := lesson.TimesOn(last)
+			rule.Until = &lastEnd
+		}
+		event.Add("RRULE", rule.String(), nil)
+
+		// 休日
+		for _, holiday := range holidays {
+			if int(holiday.Weekday()) != lesson.DayOfWeek || holiday.Before(first) || (!last.IsZero() && holiday.After(last)) {
+				continue
+			}
+			exdate, _ := lesson.TimesOn(holiday)
+			event.Add("EXDATE", ical.FormatDateTime(exdate), nil)
+		}
+
+		var exceptions []*ical.Component
+		for _, override := range overridesByLesson[lesson.ID] {
+			date := localDate(override.Date)
+			original, _ := lesson.TimesOn(date)
+			if override.Cancelled {
+				event.Add("EXDATE", ical.FormatDateTime(original), nil)
+				continue
+			}
+			exception := overriddenEvent(lesson, override, date, roomOf)
+			exception.Add("RECURRENCE-ID", ical.FormatDateTime(original), nil)
+			exceptions = append(exceptions, exception)
+		}
+
+		calendar.Components = append(calendar.Components, event)
+		calendar.Components = append(calendar.Components, exceptions...)
+	}
+	return calendar, nil
+}
+
+// lessonEvent 授業の予定（LOCATIONは取り込みと同じく組織部屋ID）
+func lessonEvent(lesson model.Lesson, start, end time.Time, orgRoomID, roomName string, updatedAt time.Time) *ical.Component {
+	event := ical.NewComponent("VEVENT")
+	event.Add("UID", lesson.ID+"@ed-mist", nil)
+	event.Add("DTSTAMP", ical.FormatDateTime(updatedAt), nil)
+	event.Add("DTSTART", ical.FormatDateTime(start), nil)
+	event.Add("DTEND", ical.FormatDateTime(end), nil)
+	event.AddText("SUMMARY", lesson.Subject.Name)
+	event.AddText("LOCATION", orgRoomID)
+	if roomName != "" {
+		event.AddText("DESCRIPTION", roomName)
+	}
+	return event
+}
+
+// overriddenEvent 授業変更を反映した回の予定
+func overriddenEvent(lesson model.Lesson, override model.LessonOverride, date time.Time, roomOf func(string) *model.Room) *ical.Component {
+	shifted := lesson
+	if override.StartTime != nil {
+		shifted.StartTime = *override.StartTime
+	}
+	if override.EndTime != nil {
+		shifted.EndTime = *override.EndTime
+	}
+	start, end := shifted.TimesOn(date)
+
+	orgRoomID, roomName := lesson.Room.OrgRoomID, lesson.Room.Name
+	if override.RoomID != nil {
+		if room := roomOf(*override.RoomID); room != nil {
+			orgRoomID, roomName = room.OrgRoomID, room.Name
+		}
+	}
+
+	return lessonEvent(lesson, start, end, orgRoomID, roomName, override.UpdatedAt)
+}
+
+// firstLessonDate 授業の最初の実施日（サーバーのタイムゾーンの0時）
+// 日付指定の授業はその日付、毎週の授業は作成日以降の最初の該当曜日
+func firstLessonDate(lesson model.Lesson) time.Time {
+	if lesson.Date != nil {
+		return localDate(*lesson.Date)
+	}
+	date := localDate(lesson.StartTime)
+	for int(date.Weekday()) != lesson.DayOfWeek {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// localDate UTCの0時で保存された日付をサーバーのタイムゾーンの0時にする
+func localDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/Shakkuuu/ed-mist-backend/internal/service"
 )
 
+var (
+	ErrorInvalidUserRole = errors.New("roleが不正です（student, teacherのいずれか）")
+)
+
 // UserUsecase ユーザーユースケース
 type UserUsecase struct {
 	userService         *service.UserService
@@ -26,6 +30,12 @@ func NewUserUsecase(userService *service.UserService, organizationService *servi
 type CreateUserRequest struct {
 	OrgID    string `json:"org_id" validate:"required"`
 	UserMail string `json:"user_mail" validate:"required,email"`
+	Role     string `json:"role"` // "student" or "teacher"（省略時はstudent）
+}
+
+// UpdateUserRoleRequest ユーザーの役割変更リクエスト
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required"` // "student" or "teacher"
 }
 
 // CreateUser ユーザーを作成
@@ -36,7 +46,15 @@ func (u *UserUsecase) CreateUser(ctx context.Context, req *CreateUserRequest) (*
 		return nil, err
 	}
 
-	user, err := u.userService.Create(ctx, req.OrgID, req.UserMail)
+	role := req.Role
+	if role == "" {
+		role = model.UserRoleStudent
+	}
+	if !model.IsValidUserRole(role) {
+		return nil, ErrorInvalidUserRole
+	}
+
+	user, err := u.userService.Create(ctx, req.OrgID, req.UserMail, role)
 	if err != nil {
 		return nil, err
 	}
@@ -103,3 +121,20 @@ func (u *UserUsecase) DeleteUser(ctx context.Context, orgID, userID string) erro
 	}
 	return nil
 }
+
+// UpdateUserRole ユーザーの役割を変更
+func (u *UserUsecase) UpdateUserRole(ctx context.Context, orgID, userID string, req *UpdateUserRoleRequest) (*model.User, error) {
+	if !model.IsValidUserRole(req.Role) {
+		return nil, ErrorInvalidUserRole
+	}
+
+	user, err := u.GetUser(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userService.UpdateRole(ctx, user, req.Role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,81 @@
+// Package devicekey デバイスの鍵ペアによる署名の検証
+// アプリがSecure Enclave・Android Keystoreなどで生成した公開鍵（SubjectPublicKeyInfoのDERをbase64にしたもの）を扱う
+package devicekey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrKeyMalformed       = errors.New("公開鍵の形式が不正です")
+	ErrKeyUnsupported     = errors.New("公開鍵の種類に対応していません（ECDSA P-256・Ed25519のみ）")
+	ErrSignatureMalformed = errors.New("署名の形式が不正です")
+	ErrSignatureInvalid   = errors.New("署名が一致しません")
+)
+
+// ParsePublicKey base64の公開鍵を読み込む（ECDSA P-256・Ed25519のみ）
+func ParsePublicKey(encoded string) (crypto.PublicKey, error) {
+	der, err := decode(encoded)
+	if err != nil {
+		return nil, ErrKeyMalformed
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrKeyMalformed
+	}
+
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, ErrKeyUnsupported
+		}
+		return pub, nil
+	case ed25519.PublicKey:
+		return pub, nil
+	default:
+		return nil, ErrKeyUnsupported
+	}
+}
+
+// Verify メッセージに対するbase64の署名を検証
+// ECDSAはSHA-256のハッシュに対するASN.1（DER）の署名、Ed25519はメッセージそのものに対する署名
+func Verify(encodedKey string, message []byte, encodedSignature string) error {
+	key, err := ParsePublicKey(encodedKey)
+	if err != nil {
+		return err
+	}
+	signature, err := decode(encodedSignature)
+	if err != nil || len(signature) == 0 {
+		return ErrSignatureMalformed
+	}
+
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(pub, digest[:], signature) {
+			return ErrSignatureInvalid
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, message, signature) {
+			return ErrSignatureInvalid
+		}
+	}
+	return nil
+}
+
+// decode 標準・URLセーフのどちらのbase64も受け付ける（パディングの有無も問わない）
+func decode(encoded string) ([]byte, error) {
+	encoded = strings.TrimRight(strings.TrimSpace(encoded), "=")
+	if strings.ContainsAny(encoded, "-_") {
+		return base64.RawURLEncoding.DecodeString(encoded)
+	}
+	return base64.RawStdEncoding.DecodeString(encoded)
+}
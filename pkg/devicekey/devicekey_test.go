@@ -0,0 +1,205 @@
+package devicekey
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// encodePublicKey テスト用に公開鍵をSubjectPublicKeyInfoのDERのbase64にする
+func encodePublicKey(t *testing.T, pub any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// testKey 署名方式ごとのテスト用の鍵ペア
+type testKey struct {
+	name      string
+	publicKey string
+	sign      func(message []byte) string
+}
+
+func newTestKeys(t *testing.T) []testKey {
+	t.Helper()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+
+	return []testKey{
+		{
+			name:      "ECDSA P-256",
+			publicKey: encodePublicKey(t, &ecKey.PublicKey),
+			sign: func(message []byte) string {
+				digest := sha256.Sum256(message)
+				signature, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+				if err != nil {
+					t.Fatalf("ecdsa.SignASN1() error = %v", err)
+				}
+				return base64.StdEncoding.EncodeToString(signature)
+			},
+		},
+		{
+			name:      "Ed25519",
+			publicKey: encodePublicKey(t, edPub),
+			sign: func(message []byte) string {
+				return base64.StdEncoding.EncodeToString(ed25519.Sign(edPriv, message))
+			},
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	nonce := []byte("nonce-0123456789")
+
+	for i, key := range keys {
+		otherKey := keys[(i+1)%len(keys)]
+		sameAlgorithmKey := newTestKeys(t)[i]
+		signature := key.sign(nonce)
+		signatureBytes := mustDecode(t, signature)
+		tampered := append([]byte{}, signatureBytes...)
+		tampered[len(tampered)-1] ^= 0xff
+
+		tests := []struct {
+			name      string
+			publicKey string
+			message   []byte
+			signature string
+			want      error
+		}{
+			{
+				name:      "正しい署名",
+				publicKey: key.publicKey,
+				message:   nonce,
+				signature: signature,
+			},
+			{
+				name:      "URLセーフ・パディングなしのbase64",
+				publicKey: base64.RawURLEncoding.EncodeToString(mustDecode(t, key.publicKey)),
+				message:   nonce,
+				signature: base64.RawURLEncoding.EncodeToString(signatureBytes),
+			},
+			{
+				name:      "Nonceの改ざん",
+				publicKey: key.publicKey,
+				message:   []byte("nonce-0123456780"),
+				signature: signature,
+				want:      ErrSignatureInvalid,
+			},
+			{
+				name:      "同じ方式の別の鍵",
+				publicKey: sameAlgorithmKey.publicKey,
+				message:   nonce,
+				signature: signature,
+				want:      ErrSignatureInvalid,
+			},
+			{
+				name:      "別の方式の鍵",
+				publicKey: otherKey.publicKey,
+				message:   nonce,
+				signature: signature,
+				want:      ErrSignatureInvalid,
+			},
+			{
+				name:      "署名の改ざん",
+				publicKey: key.publicKey,
+				message:   nonce,
+				signature: base64.StdEncoding.EncodeToString(tampered),
+				want:      ErrSignatureInvalid,
+			},
+			{
+				name:      "署名がbase64でない",
+				publicKey: key.publicKey,
+				message:   nonce,
+				signature: "!!!not-base64!!!",
+				want:      ErrSignatureMalformed,
+			},
+			{
+				name:      "署名が空",
+				publicKey: key.publicKey,
+				message:   nonce,
+				signature: "",
+				want:      ErrSignatureMalformed,
+			},
+			{
+				name:      "公開鍵がbase64でない",
+				publicKey: "!!!not-base64!!!",
+				message:   nonce,
+				signature: signature,
+				want:      ErrKeyMalformed,
+			},
+			{
+				name:      "公開鍵がDERでない",
+				publicKey: base64.StdEncoding.EncodeToString([]byte("not-der")),
+				message:   nonce,
+				signature: signature,
+				want:      ErrKeyMalformed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(key.name+"/"+tt.name, func(t *testing.T) {
+				err := Verify(tt.publicKey, tt.message, tt.signature)
+				if !errors.Is(err, tt.want) {
+					t.Errorf("Verify() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestParsePublicKeyRejectsUnsupportedKeys(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		publicKey string
+		want      error
+	}{
+		{name: "ECDSA P-384", publicKey: encodePublicKey(t, &p384.PublicKey), want: ErrKeyUnsupported},
+		{name: "RSA", publicKey: encodePublicKey(t, &rsaKey.PublicKey), want: ErrKeyUnsupported},
+		{name: "空文字列", publicKey: "", want: ErrKeyMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePublicKey(tt.publicKey); !errors.Is(err, tt.want) {
+				t.Errorf("ParsePublicKey() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// mustDecode テスト用にbase64をデコード
+func mustDecode(t *testing.T, encoded string) []byte {
+	t.Helper()
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("base64.DecodeString() error = %v", err)
+	}
+	return decoded
+}
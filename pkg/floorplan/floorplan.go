@@ -0,0 +1,156 @@
+// Package floorplan フロアプラン画像に部屋の範囲と在室状況を重ねて描画する
+package floorplan
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	_ "image/gif"  // 背景画像のデコード用
+	_ "image/jpeg" // 背景画像のデコード用
+	_ "image/png"  // 背景画像のデコード用
+	"net/http"
+	"unicode"
+)
+
+// Point マップ画像上の座標（ピクセル）
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Region 描画する範囲（部屋・ゾーン）
+type Region struct {
+	Label      string
+	ASCIILabel string // PNG描画用のラベル（LabelがASCII以外を含む場合に使用）
+	Count      int    // 在室数
+	Vertices   []Point
+	Linked     bool // 部屋に紐づいているか（紐づいていないゾーンは薄く描画）
+}
+
+// Plan 描画内容
+type Plan struct {
+	Title      string
+	Width      int    // 背景画像をデコードできない場合の幅
+	Height     int    // 背景画像をデコードできない場合の高さ
+	Background []byte // フロアプラン画像（PNG/JPEG/GIF）
+	Regions    []Region
+	Dots       []Point // 匿名化したデバイスの位置
+}
+
+// size 背景画像のサイズ（デコードできない場合はPlanの指定値）
+func (p *Plan) size() (int, int, error) {
+	if len(p.Background) > 0 {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(p.Background)); err == nil {
+			return cfg.Width, cfg.Height, nil
+		}
+	}
+	if p.Width <= 0 || p.Height <= 0 {
+		return 0, 0, fmt.Errorf("フロアプランのサイズが不明です")
+	}
+	return p.Width, p.Height, nil
+}
+
+// RenderSVG SVG形式で描画（背景画像はdata URIとして埋め込む）
+func RenderSVG(p *Plan) ([]byte, error) {
+	width, height, err := p.size()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	if p.Title != "" {
+		fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(p.Title))
+	}
+	if len(p.Background) > 0 {
+		fmt.Fprintf(&b, `<image x="0" y="0" width="%d" height="%d" href="data:%s;base64,%s"/>`,
+			width, height, http.DetectContentType(p.Background), base64.StdEncoding.EncodeToString(p.Background))
+	}
+
+	for _, r := range p.Regions {
+		if len(r.Vertices) < 3 {
+			continue
+		}
+		fill, stroke := regionColors(r)
+		b.WriteString(`<polygon points="`)
+		for i, v := range r.Vertices {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%.1f,%.1f", v.X, v.Y)
+		}
+		fmt.Fprintf(&b, `" fill="%s" fill-opacity="%.2f" stroke="%s" stroke-width="2"/>`, hex(fill), float64(fill.A)/255, hex(stroke))
+	}
+
+	for _, d := range p.Dots {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="5" fill="%s" stroke="#ffffff" stroke-width="1"/>`, d.X, d.Y, hex(dotColor))
+	}
+
+	for _, r := range p.Regions {
+		if len(r.Vertices) < 3 || r.Label == "" {
+			continue
+		}
+		c := centroid(r.Vertices)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" font-family="sans-serif" font-size="16" fill="#1a1a1a" stroke="#ffffff" stroke-width="3" paint-order="stroke">%s (%d)</text>`,
+			c.X, c.Y, html.EscapeString(r.Label), r.Count)
+	}
+
+	b.WriteString(`</svg>`)
+	return b.Bytes(), nil
+}
+
+var (
+	dotColor = color.NRGBA{R: 0xd9, G: 0x30, B: 0x25, A: 0xff}
+)
+
+// regionColors 在室数に応じた塗りつぶし色と枠線色
+func regionColors(r Region) (fill, stroke color.NRGBA) {
+	switch {
+	case !r.Linked:
+		return color.NRGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0x30}, color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xff}
+	case r.Count == 0:
+		return color.NRGBA{R: 0x42, G: 0x85, B: 0xf4, A: 0x40}, color.NRGBA{R: 0x1a, G: 0x5f, B: 0xd0, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x34, G: 0xa8, B: 0x53, A: 0x60}, color.NRGBA{R: 0x1e, G: 0x7e, B: 0x34, A: 0xff}
+	}
+}
+
+func hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// centroid ポリゴンの重心（面積が0の場合は頂点の平均）
+func centroid(vertices []Point) Point {
+	var area, cx, cy float64
+	n := len(vertices)
+	for i := range n {
+		a, b := vertices[i], vertices[(i+1)%n]
+		cross := a.X*b.Y - b.X*a.Y
+		area += cross
+		cx += (a.X + b.X) * cross
+		cy += (a.Y + b.Y) * cross
+	}
+	if area == 0 {
+		var sx, sy float64
+		for _, v := range vertices {
+			sx += v.X
+			sy += v.Y
+		}
+		return Point{X: sx / float64(n), Y: sy / float64(n)}
+	}
+	area /= 2
+	return Point{X: cx / (6 * area), Y: cy / (6 * area)}
+}
+
+// isASCII 文字列がASCII文字のみかどうか
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package floorplan
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"slices"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPNG PNG形式で描画
+// ラベルは組み込みのASCIIフォントで描画するため、ASCII以外を含むラベルはASCIILabelを使用する
+func RenderPNG(p *Plan) ([]byte, error) {
+	width, height, err := p.size()
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	if len(p.Background) > 0 {
+		if bg, _, err := image.Decode(bytes.NewReader(p.Background)); err == nil {
+			draw.Draw(canvas, canvas.Bounds(), bg, bg.Bounds().Min, draw.Over)
+		}
+	}
+
+	for _, r := range p.Regions {
+		if len(r.Vertices) < 3 {
+			continue
+		}
+		fill, stroke := regionColors(r)
+		fillPolygon(canvas, r.Vertices, fill)
+		strokePolygon(canvas, r.Vertices, stroke)
+	}
+
+	for _, d := range p.Dots {
+		fillCircle(canvas, d, 5, color.White)
+		fillCircle(canvas, d, 4, dotColor)
+	}
+
+	for _, r := range p.Regions {
+		if len(r.Vertices) < 3 {
+			continue
+		}
+		label := r.Label
+		if !isASCII(label) {
+			label = r.ASCIILabel
+		}
+		if label == "" {
+			continue
+		}
+		drawLabel(canvas, centroid(r.Vertices), fmt.Sprintf("%s (%d)", label, r.Count))
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, canvas); err != nil {
+		return nil, fmt.Errorf("PNGエンコードエラー: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// fillPolygon ポリゴンを半透明で塗りつぶす（スキャンライン方式）
+func fillPolygon(img *image.RGBA, vertices []Point, c color.NRGBA) {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, v := range vertices {
+		minY = math.Min(minY, v.Y)
+		maxY = math.Max(maxY, v.Y)
+	}
+	bounds := img.Bounds()
+	src := image.NewUniform(c)
+
+	for y := max(int(minY), bounds.Min.Y); y <= min(int(maxY), bounds.Max.Y-1); y++ {
+		sy := float64(y) + 0.5
+		var xs []float64
+		n := len(vertices)
+		for i := range n {
+			a, b := vertices[i], vertices[(i+1)%n]
+			if (a.Y > sy) != (b.Y > sy) {
+				xs = append(xs, a.X+(sy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		slices.Sort(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := max(int(math.Round(xs[i])), bounds.Min.X)
+			x1 := min(int(math.Round(xs[i+1])), bounds.Max.X)
+			if x0 < x1 {
+				draw.Draw(img, image.Rect(x0, y, x1, y+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
+
+// strokePolygon ポリゴンの枠線を描画
+func strokePolygon(img *image.RGBA, vertices []Point, c color.NRGBA) {
+	n := len(vertices)
+	for i := range n {
+		a, b := vertices[i], vertices[(i+1)%n]
+		steps := int(math.Max(math.Abs(b.X-a.X), math.Abs(b.Y-a.Y)))
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(s) / float64(steps)
+			}
+			x := int(a.X + (b.X-a.X)*t)
+			y := int(a.Y + (b.Y-a.Y)*t)
+			// 2px幅
+			img.Set(x, y, c)
+			img.Set(x+1, y, c)
+			img.Set(x, y+1, c)
+		}
+	}
+}
+
+// fillCircle 円を塗りつぶす
+func fillCircle(img *image.RGBA, center Point, radius int, c color.Color) {
+	cx, cy := int(center.X), int(center.Y)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
+
+// drawLabel 中央揃えでラベルを描画（白い縁取り付き）
+func drawLabel(img *image.RGBA, at Point, text string) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	x := int(at.X) - width/2
+	y := int(at.Y) + face.Ascent/2
+
+	outline := &font.Drawer{Dst: img, Src: image.White, Face: face}
+	for _, off := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		outline.Dot = fixed.P(x+off[0], y+off[1])
+		outline.DrawString(text)
+	}
+	d := &font.Drawer{Dst: img, Src: image.Black, Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
@@ -0,0 +1,88 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets 折り返す前の1行の最大オクテット数（改行を除く）
+const maxLineOctets = 75
+
+// Encode コンポーネントをiCalendar形式で書き出す（CRLF改行、75オクテットで折り返し）
+func (c *Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := c.encode(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// encode コンポーネントを再帰的に書き出す
+func (c *Component) encode(w *bufio.Writer) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Properties {
+		if err := writeLine(w, p.line()); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := child.encode(w); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// line プロパティを1行の文字列にする（パラメーターは名前順）
+func (p *Property) line() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	keys := make([]string, 0, len(p.Params))
+	for key := range p.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteByte(';')
+		b.WriteString(key)
+		b.WriteByte('=')
+		for i, value := range p.Params[key] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if strings.ContainsAny(value, ":;,") {
+				value = `"` + value + `"`
+			}
+			b.WriteString(value)
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// writeLine 1行を書き出す（UTF-8の文字の途中では折り返さない）
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// 継続行は先頭の空白を含めて75オクテット
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
@@ -0,0 +1,285 @@
+// Package ical iCalendar（RFC 5545）形式の読み込みと書き出し
+// 時間割の取り込み・配信に必要な範囲（VEVENT、RRULEの毎週の繰り返し、EXDATEなど）のみを扱う
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 読み込みで返すエラー
+var (
+	ErrNoCalendar    = errors.New("ical: VCALENDARが見つかりません")
+	ErrUnclosed      = errors.New("ical: ENDのないコンポーネントがあります")
+	ErrMalformedLine = errors.New("ical: 不正な行があります")
+	ErrUnexpectedEnd = errors.New("ical: 対応するBEGINのないENDがあります")
+	ErrLineTooLong   = errors.New("ical: 行が長すぎます")
+)
+
+// maxLogicalLineLen 折り返しを連結した1行の最大長
+const maxLogicalLineLen = 1 << 20
+
+// Property プロパティ（名前・パラメーター・値）
+type Property struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// Param パラメーターの最初の値（ない場合は空）
+func (p *Property) Param(name string) string {
+	if values := p.Params[strings.ToUpper(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Component コンポーネント（VCALENDAR・VEVENTなど）
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// NewComponent コンポーネントを作成
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// Get 指定した名前の最初のプロパティ（ない場合はnil）
+func (c *Component) Get(name string) *Property {
+	name = strings.ToUpper(name)
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Value 指定した名前の最初のプロパティの値（ない場合は空）
+func (c *Component) Value(name string) string {
+	if p := c.Get(name); p != nil {
+		return p.Value
+	}
+	return ""
+}
+
+// GetAll 指定した名前のプロパティをすべて取得
+func (c *Component) GetAll(name string) []Property {
+	name = strings.ToUpper(name)
+	var props []Property
+	for _, p := range c.Properties {
+		if p.Name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// Add プロパティを追加
+func (c *Component) Add(name, value string, params map[string][]string) {
+	c.Properties = append(c.Properties, Property{Name: strings.ToUpper(name), Params: params, Value: value})
+}
+
+// AddText テキスト値のプロパティを追加（値はエスケープされる）
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value), nil)
+}
+
+// Children 指定した名前の子コンポーネントを取得
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var children []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Parse iCalendarを読み込み、最初のVCALENDARを返す
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := NewComponent(prop.Value)
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, ErrUnexpectedEnd
+			}
+			component := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 && root == nil && component.Name == "VCALENDAR" {
+				root = component
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: %s", ErrMalformedLine, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, *prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, ErrUnclosed
+	}
+	if root == nil {
+		return nil, ErrNoCalendar
+	}
+	return root, nil
+}
+
+// unfold 折り返された行を連結して論理行の一覧にする
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogicalLineLen)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			last := lines[len(lines)-1] + line[1:]
+			if len(last) > maxLogicalLineLen {
+				return nil, ErrLineTooLong
+			}
+			lines[len(lines)-1] = last
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrLineTooLong
+		}
+		return nil, err
+	}
+
+	// 先頭のBOMを除去
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\ufeff")
+	}
+	return lines, nil
+}
+
+// parseLine 論理行をプロパティに分解（NAME;PARAM=VALUE:値）
+func parseLine(line string) (*Property, error) {
+	// 値の区切りの「:」を探す（パラメーターの引用符内は除く）
+	inQuote := false
+	nameEnd := -1
+	valueStart := -1
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == ';' && !inQuote && nameEnd < 0:
+			nameEnd = i
+		case r == ':' && !inQuote:
+			valueStart = i
+		}
+		if valueStart >= 0 {
+			break
+		}
+	}
+	if valueStart < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrMalformedLine, truncate(line))
+	}
+	if nameEnd < 0 || nameEnd > valueStart {
+		nameEnd = valueStart
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(line[:nameEnd]))
+	if name == "" {
+		return nil, fmt.Errorf("%w: %q", ErrMalformedLine, truncate(line))
+	}
+	prop := &Property{Name: name, Value: line[valueStart+1:]}
+
+	if nameEnd < valueStart {
+		prop.Params = make(map[string][]string)
+		for _, param := range splitOutsideQuotes(line[nameEnd+1:valueStart], ';') {
+			key, value, ok := strings.Cut(param, "=")
+			if !ok {
+				continue
+			}
+			key = strings.ToUpper(strings.TrimSpace(key))
+			for _, v := range splitOutsideQuotes(value, ',') {
+				prop.Params[key] = append(prop.Params[key], strings.Trim(v, `"`))
+			}
+		}
+	}
+	return prop, nil
+}
+
+// splitOutsideQuotes 引用符の外側の区切り文字で分割
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// truncate エラーメッセージ用に行を短くする
+func truncate(line string) string {
+	if len(line) > 60 {
+		return line[:60] + "..."
+	}
+	return line
+}
+
+// EscapeText テキスト値をエスケープ
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// UnescapeText テキスト値のエスケープを解除
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
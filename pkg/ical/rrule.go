@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 繰り返しの頻度
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Recurrence RRULEの内容
+// 時間割で使用するFREQ・INTERVAL・BYDAY・UNTIL・COUNTのみを解析し、それ以外の指定はUnsupportedに記録する
+type Recurrence struct {
+	Freq        string
+	Interval    int
+	ByDay       []time.Weekday
+	Until       *time.Time
+	Count       int
+	Unsupported []string // 解析しなかった指定（BYMONTHなど）
+}
+
+// ParseRecurrence RRULEの値を解析（UNTILの浮動時刻・日付はlocとして解釈する）
+func ParseRecurrence(value string, loc *time.Location) (*Recurrence, error) {
+	rule := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToUpper(key)
+		switch key {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("ical: INTERVALが不正です: %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("ical: COUNTが不正です: %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, _, err := parseDateTimeValue(val, "", "", loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				code = strings.ToUpper(strings.TrimSpace(code))
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					// 「1MO」のような月内の位置指定は毎週の繰り返しでは扱えない
+					rule.Unsupported = append(rule.Unsupported, "BYDAY="+code)
+					continue
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "WKST":
+			// 間隔1の毎週の繰り返しには影響しない
+		default:
+			rule.Unsupported = append(rule.Unsupported, key)
+		}
+	}
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("ical: FREQが指定されていません")
+	}
+	return rule, nil
+}
+
+// String RRULEの値として書き出す
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, weekday := range r.ByDay {
+			codes[i] = WeekdayCode(weekday)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+FormatDateTime(*r.Until))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// WeekdayCode 曜日のコード（MO・TUなど）
+func WeekdayCode(weekday time.Weekday) string {
+	for code, w := range weekdayCodes {
+		if w == weekday {
+			return code
+		}
+	}
+	return ""
+}
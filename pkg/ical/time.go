@@ -0,0 +1,83 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// 日時の書式
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// ParseDateTime DTSTARTなどの日時プロパティを解析
+// VALUE=DATEの場合は終日（allDay=true）としてlocの0時を返す。
+// 末尾がZの場合はUTC、TZIDがある場合はそのタイムゾーン、いずれもない場合（浮動時刻）はlocとして解釈する
+func ParseDateTime(p *Property, loc *time.Location) (t time.Time, allDay bool, err error) {
+	if p == nil {
+		return time.Time{}, false, fmt.Errorf("ical: 日時が指定されていません")
+	}
+	return parseDateTimeValue(p.Value, p.Param("VALUE"), p.Param("TZID"), loc)
+}
+
+// ParseDateTimeList EXDATEなどカンマ区切りの日時プロパティを解析
+func ParseDateTimeList(p *Property, loc *time.Location) ([]time.Time, error) {
+	var times []time.Time
+	for _, value := range splitOutsideQuotes(p.Value, ',') {
+		if value == "" {
+			continue
+		}
+		t, _, err := parseDateTimeValue(value, p.Param("VALUE"), p.Param("TZID"), loc)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// parseDateTimeValue 日時の値を解析
+func parseDateTimeValue(value, valueType, tzid string, loc *time.Location) (time.Time, bool, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	if valueType == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("ical: 日付の形式が不正です: %q", value)
+		}
+		return t, true, nil
+	}
+
+	if len(value) == len(dateTimeLayout)+1 && value[len(value)-1] == 'Z' {
+		t, err := time.ParseInLocation(dateTimeLayout, value[:len(value)-1], time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("ical: 日時の形式が不正です: %q", value)
+		}
+		return t, false, nil
+	}
+
+	if tzid != "" {
+		tz, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("ical: 不明なタイムゾーンです: %q", tzid)
+		}
+		loc = tz
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ical: 日時の形式が不正です: %q", value)
+	}
+	return t, false, nil
+}
+
+// FormatDateTime 日時をUTC形式（YYYYMMDDTHHMMSSZ）で書き出す
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+// FormatDate 日付を書き出す（YYYYMMDD）
+func FormatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
@@ -0,0 +1,252 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret-0123456789abcdef")
+
+// encodeSegment テスト用にJSONをbase64urlでエンコード
+func encodeSegment(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// forge 任意のヘッダーで署名したトークンを作成（HS256以外の署名方式の検証用）
+func forge(t *testing.T, rawHeader string, claims Claims, sign func(signingInput string) string) string {
+	t.Helper()
+	token, err := Sign(claims, testSecret)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	payload := strings.Split(token, ".")[1]
+	signingInput := encodeSegment(rawHeader) + "." + payload
+	return signingInput + "." + sign(signingInput)
+}
+
+func TestSignAndParse(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	claims := Claims{
+		Subject:   "account-1",
+		Issuer:    "ed-mist-backend",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(15 * time.Minute).Unix(),
+		ID:        "jti-1",
+		Role:      "org_admin",
+		OrgID:     "org-1",
+		UserID:    "user-1",
+	}
+
+	token, err := Sign(claims, testSecret)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	got, err := Parse(token, testSecret, now)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if *got != claims {
+		t.Errorf("Parse() = %+v, want %+v", *got, claims)
+	}
+}
+
+func TestParseRejectsInvalidTokens(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	valid := Claims{
+		Subject:   "account-1",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}
+	token, err := Sign(valid, testSecret)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	parts := strings.Split(token, ".")
+
+	// 署名の最後の1文字を差し替える
+	lastChar := parts[2][len(parts[2])-1:]
+	replacement := "A"
+	if lastChar == "A" {
+		replacement = "B"
+	}
+	tamperedSignature := parts[0] + "." + parts[1] + "." + parts[2][:len(parts[2])-1] + replacement
+
+	// 署名はそのままでペイロードの役割を書き換える
+	tamperedPayload := parts[0] + "." + encodeSegment(`{"sub":"account-1","iat":1700000000,"exp":1700003600,"role":"super_admin"}`) + "." + parts[2]
+
+	hs512 := func(signingInput string) string {
+		mac := hmac.New(sha512.New, testSecret)
+		mac.Write([]byte(signingInput))
+		return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		secret []byte
+		want   error
+	}{
+		{
+			name:   "署名の改ざん",
+			token:  tamperedSignature,
+			secret: testSecret,
+			want:   ErrTokenSignature,
+		},
+		{
+			name:   "ペイロードの改ざん",
+			token:  tamperedPayload,
+			secret: testSecret,
+			want:   ErrTokenSignature,
+		},
+		{
+			name:   "異なる署名鍵",
+			token:  token,
+			secret: []byte("other-secret"),
+			want:   ErrTokenSignature,
+		},
+		{
+			name:   "alg: none（署名なし）",
+			token:  forge(t, `{"alg":"none","typ":"JWT"}`, valid, func(string) string { return "" }),
+			secret: testSecret,
+			want:   ErrTokenAlgorithm,
+		},
+		{
+			name:   "alg: None（大文字小文字違い）",
+			token:  forge(t, `{"alg":"None","typ":"JWT"}`, valid, func(string) string { return "" }),
+			secret: testSecret,
+			want:   ErrTokenAlgorithm,
+		},
+		{
+			name:   "alg: HS512",
+			token:  forge(t, `{"alg":"HS512","typ":"JWT"}`, valid, hs512),
+			secret: testSecret,
+			want:   ErrTokenAlgorithm,
+		},
+		{
+			name:   "alg: RS256",
+			token:  forge(t, `{"alg":"RS256","typ":"JWT"}`, valid, hs512),
+			secret: testSecret,
+			want:   ErrTokenAlgorithm,
+		},
+		{
+			name:   "algなし",
+			token:  forge(t, `{"typ":"JWT"}`, valid, hs512),
+			secret: testSecret,
+			want:   ErrTokenAlgorithm,
+		},
+		{
+			name:   "セグメント数が不足",
+			token:  parts[0] + "." + parts[1],
+			secret: testSecret,
+			want:   ErrTokenMalformed,
+		},
+		{
+			name:   "セグメント数が過剰",
+			token:  token + ".extra",
+			secret: testSecret,
+			want:   ErrTokenMalformed,
+		},
+		{
+			name:   "ヘッダーがbase64urlでない",
+			token:  "!!!." + parts[1] + "." + parts[2],
+			secret: testSecret,
+			want:   ErrTokenMalformed,
+		},
+		{
+			name:   "ヘッダーがJSONでない",
+			token:  encodeSegment("not-json") + "." + parts[1] + "." + parts[2],
+			secret: testSecret,
+			want:   ErrTokenMalformed,
+		},
+		{
+			name:   "空文字列",
+			token:  "",
+			secret: testSecret,
+			want:   ErrTokenMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := Parse(tt.token, tt.secret, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+			if claims != nil {
+				t.Errorf("Parse() claims = %+v, want nil", claims)
+			}
+		})
+	}
+}
+
+func TestParseTimeBoundaries(t *testing.T) {
+	issuedAt := time.Unix(1_700_000_000, 0)
+	exp := issuedAt.Add(15 * time.Minute)
+	nbf := issuedAt.Add(time.Minute)
+
+	tests := []struct {
+		name   string
+		claims Claims
+		now    time.Time
+		want   error
+	}{
+		{
+			name:   "有効期限の1秒前",
+			claims: Claims{Subject: "a", ExpiresAt: exp.Unix()},
+			now:    exp.Add(-time.Second),
+		},
+		{
+			name:   "有効期限ちょうど",
+			claims: Claims{Subject: "a", ExpiresAt: exp.Unix()},
+			now:    exp,
+			want:   ErrTokenExpired,
+		},
+		{
+			name:   "有効期限の1秒後",
+			claims: Claims{Subject: "a", ExpiresAt: exp.Unix()},
+			now:    exp.Add(time.Second),
+			want:   ErrTokenExpired,
+		},
+		{
+			name:   "有効期限なし",
+			claims: Claims{Subject: "a"},
+			now:    issuedAt,
+			want:   ErrTokenExpired,
+		},
+		{
+			name:   "nbfの1秒前",
+			claims: Claims{Subject: "a", NotBefore: nbf.Unix(), ExpiresAt: exp.Unix()},
+			now:    nbf.Add(-time.Second),
+			want:   ErrTokenNotYet,
+		},
+		{
+			name:   "nbfちょうど",
+			claims: Claims{Subject: "a", NotBefore: nbf.Unix(), ExpiresAt: exp.Unix()},
+			now:    nbf,
+		},
+		{
+			name:   "nbfより後",
+			claims: Claims{Subject: "a", NotBefore: nbf.Unix(), ExpiresAt: exp.Unix()},
+			now:    nbf.Add(time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Sign(tt.claims, testSecret)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			_, err = Parse(token, testSecret, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Package mailer メール送信
+// 本番ではSMTPSender、開発・テスト環境ではメールを送らずにログへ出力するLogSenderを使う
+package mailer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress 送信元・宛先のメールアドレスが不正
+var ErrInvalidAddress = errors.New("メールアドレスの形式が不正です")
+
+// Message 送信するメール（本文はプレーンテキスト）
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender メールの送信方法
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender SMTPサーバー経由でメールを送信
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender SMTPサーバー経由の送信を作成（usernameが空の場合は認証しない）
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send メールを送信（STARTTLSはサーバーが対応している場合に使用される）
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	from, to, body, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	if err := smtp.SendMail(addr, auth, from, []string{to}, body); err != nil {
+		return fmt.Errorf("メール送信エラー: %w", err)
+	}
+	return nil
+}
+
+// build RFC 5322形式のメールを作成（件名・本文はUTF-8）
+// 送信元・宛先のアドレス（エンベロープ用）とメールを返す
+func (s *SMTPSender) build(msg Message) (string, string, []byte, error) {
+	from, err := parseAddress(s.from)
+	if err != nil {
+		return "", "", nil, err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("\r\n")
+
+	// 本文は76文字ごとに改行したbase64
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return from.Address, to.Address, []byte(b.String()), nil
+}
+
+// parseAddress ヘッダーに書き込むメールアドレスを検証（改行を含むもの・複数のアドレスは受け付けない）
+func parseAddress(address string) (*mail.Address, error) {
+	if strings.ContainsAny(address, "\r\n") {
+		return nil, ErrInvalidAddress
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	return parsed, nil
+}
+
+// LogSender メールを送信せずにログへ出力（開発・テスト用）
+type LogSender struct{}
+
+// NewLogSender ログへ出力する送信を作成
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send メールの内容をログに出力
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("[LogSender] メール送信（ログ出力のみ）: To=%s, Subject=%s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
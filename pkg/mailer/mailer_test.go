@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSMTPSenderBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		wantFrom string
+		wantTo   string
+		wantErr  error
+	}{
+		{
+			name:     "アドレスのみ",
+			from:     "noreply@example.com",
+			to:       "student@example.com",
+			wantFrom: "noreply@example.com",
+			wantTo:   "student@example.com",
+		},
+		{
+			name:     "表示名付きの送信元",
+			from:     "ED Mist <noreply@example.com>",
+			to:       "student@example.com",
+			wantFrom: "noreply@example.com",
+			wantTo:   "student@example.com",
+		},
+		{
+			name:    "宛先にヘッダーを挿入",
+			from:    "noreply@example.com",
+			to:      "student@example.com\r\nBcc: attacker@example.com",
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name:    "宛先にLFのみでヘッダーを挿入",
+			from:    "noreply@example.com",
+			to:      "student@example.com\nBcc: attacker@example.com",
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name:    "送信元にヘッダーを挿入",
+			from:    "noreply@example.com\r\nReply-To: attacker@example.com",
+			to:      "student@example.com",
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name:    "複数の宛先",
+			from:    "noreply@example.com",
+			to:      "student@example.com, attacker@example.com",
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name:    "アドレスの形式でない",
+			from:    "noreply@example.com",
+			to:      "not-an-address",
+			wantErr: ErrInvalidAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSMTPSender("localhost", 25, "", "", tt.from)
+			from, to, body, err := s.build(Message{To: tt.to, Subject: "確認コード", Body: "123456"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("build() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("build() from, to = %q, %q, want %q, %q", from, to, tt.wantFrom, tt.wantTo)
+			}
+
+			header, _, _ := strings.Cut(string(body), "\r\n\r\n")
+			for _, line := range strings.Split(header, "\r\n") {
+				if strings.ContainsAny(line, "\r\n") {
+					t.Errorf("ヘッダーに改行が含まれている: %q", line)
+				}
+			}
+			if !strings.Contains(header, "To: <"+tt.wantTo+">") {
+				t.Errorf("Toヘッダーが不正: %q", header)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package mistapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand/v2"
 	"net/http"
 	"time"
 )
@@ -14,15 +17,28 @@ type Client struct {
 	APIToken   string
 	SiteID     string
 	HTTPClient *http.Client
+
+	// リトライ設定
+	MaxRetries    int           // 最大リトライ回数
+	BaseBackoff   time.Duration // 指数バックオフの初期待機時間
+	MaxBackoff    time.Duration // 指数バックオフの最大待機時間
+	MaxRetryAfter time.Duration // これを超えるRetry-Afterは待たずにRateLimitErrorを返す
+
+	// Limiter APIリクエストのレートリミッター（nilの場合は制限しない）
+	Limiter *RateLimiter
 }
 
 // 新しいMist APIクライアントを作成
 func NewClient(baseURL, apiToken, siteID string) *Client {
 	return &Client{
-		BaseURL:    baseURL,
-		APIToken:   apiToken,
-		SiteID:     siteID,
-		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		BaseURL:       baseURL,
+		APIToken:      apiToken,
+		SiteID:        siteID,
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		MaxRetries:    3,
+		BaseBackoff:   500 * time.Millisecond,
+		MaxBackoff:    10 * time.Second,
+		MaxRetryAfter: 60 * time.Second,
 	}
 }
 
@@ -101,32 +117,12 @@ type MistBLEDevice struct {
 }
 
 // Mistのマップ一覧を取得
-func (c *Client) GetMaps(siteID string) ([]MistMap, error) {
+func (c *Client) GetMaps(ctx context.Context, siteID string) ([]MistMap, error) {
 	url := fmt.Sprintf("%s/api/v1/sites/%s/maps", c.BaseURL, siteID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	c.SetAuthHeader(req)
-
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.get(ctx, "マップ取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("mist APIマップ取得エラー: %s, %s", resp.Status, string(body))
-	}
-
-	// デバッグ用：レスポンスボディをログ出力
-	body, _ := io.ReadAll(resp.Body)
-	// fmt.Printf("Mist API マップ取得レスポンス: %s\n", string(body))
 
 	var maps []MistMap
 	if err := json.Unmarshal(body, &maps); err != nil {
@@ -136,33 +132,12 @@ func (c *Client) GetMaps(siteID string) ([]MistMap, error) {
 }
 
 // Mistのゾーン一覧を取得
-func (c *Client) GetZones(siteID string) ([]MistZone, error) {
+func (c *Client) GetZones(ctx context.Context, siteID string) ([]MistZone, error) {
 	url := fmt.Sprintf("%s/api/v1/sites/%s/zones", c.BaseURL, siteID)
-	// fmt.Printf("Mist API ゾーン取得URL: %s\n", url)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	c.SetAuthHeader(req)
-
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.get(ctx, "ゾーン取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-
-	// レスポンスボディを読み取り
-	body, _ := io.ReadAll(resp.Body)
-	// fmt.Printf("mist API ゾーン取得レスポンス: Status=%s, Body=%s\n", resp.Status, string(body))
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("mist APIゾーン取得エラー: %s, %s", resp.Status, string(body))
-	}
 
 	// まず配列として解析を試みる
 	var zones []MistZone
@@ -192,9 +167,9 @@ func (c *Client) GetZones(siteID string) ([]MistZone, error) {
 }
 
 // マップ画像を取得
-func (c *Client) GetMapImage(siteID, mapID string) ([]byte, error) {
+func (c *Client) GetMapImage(ctx context.Context, siteID, mapID string) ([]byte, error) {
 	// まずマップ一覧を取得して、指定されたマップのURLを取得
-	maps, err := c.GetMaps(siteID)
+	maps, err := c.GetMaps(ctx, siteID)
 	if err != nil {
 		return nil, fmt.Errorf("マップ一覧取得エラー: %w", err)
 	}
@@ -208,7 +183,12 @@ func (c *Client) GetMapImage(siteID, mapID string) ([]byte, error) {
 	}
 
 	if targetMap == nil {
-		return nil, fmt.Errorf("マップが見つかりません: %s", mapID)
+		return nil, &NotFoundError{APIError: &APIError{
+			Op:         "マップ画像取得",
+			StatusCode: http.StatusNotFound,
+			Status:     http.StatusText(http.StatusNotFound),
+			Body:       "マップが見つかりません: " + mapID,
+		}}
 	}
 
 	// URLフィールドから直接画像を取得
@@ -216,14 +196,66 @@ func (c *Client) GetMapImage(siteID, mapID string) ([]byte, error) {
 		return nil, fmt.Errorf("マップ画像URLが利用できません")
 	}
 
-	req, err := http.NewRequest("GET", targetMap.URL, nil)
+	// 画像URLは署名付きURLのため認証ヘッダーは付与しない
+	return c.get(ctx, "マップ画像取得", targetMap.URL, false)
+}
+
+// 認証ヘッダーをセット
+func (c *Client) SetAuthHeader(req *http.Request) {
+	req.Header.Set("Authorization", "Token "+c.APIToken)
+}
+
+// get GETリクエストを送信してレスポンスボディを返す
+// レート制限・一時的なエラーの場合は指数バックオフでリトライする
+func (c *Client) get(ctx context.Context, op, url string, withAuth bool) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := c.getOnce(ctx, op, url, withAuth)
+		if err == nil {
+			return body, nil
+		}
+		if !isRetryable(err) || attempt >= c.MaxRetries {
+			return nil, err
+		}
+
+		wait, ok := c.retryWait(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		log.Printf("[MistAPI] %s失敗（%d回目）、%s後に再試行します: %v", op, attempt+1, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// getOnce GETリクエストを1回だけ送信
+func (c *Client) getOnce(ctx context.Context, op, url string, withAuth bool) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	if withAuth {
+		// Mist APIへのリクエストのみ予算を消費する
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx, PriorityFromContext(ctx)); err != nil {
+				return nil, err
+			}
+		}
+		c.SetAuthHeader(req)
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		// 呼び出し元のキャンセル・タイムアウトはリトライしない
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &TransientError{Err: err}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -231,52 +263,66 @@ func (c *Client) GetMapImage(siteID, mapID string) ([]byte, error) {
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("マップ画像取得エラー: %s, %s", resp.Status, string(body))
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &TransientError{Err: err}
 	}
 
-	return io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		err := newStatusError(op, resp, body)
+		// Mist側で制限された場合は他のリクエストも止める
+		var rateErr *RateLimitError
+		if c.Limiter != nil && errors.As(err, &rateErr) && rateErr.RetryAfter > 0 {
+			c.Limiter.PauseUntil(time.Now().Add(rateErr.RetryAfter))
+		}
+		return nil, err
+	}
+	return body, nil
 }
 
-// 認証ヘッダーをセット
-func (c *Client) SetAuthHeader(req *http.Request) {
-	req.Header.Set("Authorization", "Token "+c.APIToken)
+// RateLimitStats レートリミッターの統計情報を取得（リミッター未設定の場合はnil）
+func (c *Client) RateLimitStats() *RateLimitStats {
+	if c.Limiter == nil {
+		return nil
+	}
+	stats := c.Limiter.Stats()
+	return &stats
 }
 
-// Mistのゾーン内クライアント一覧を取得
-func (c *Client) GetZoneClients(siteID, zoneID string) (sdkClients []string, Clients []string, err error) {
-	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/zones/%s", c.BaseURL, siteID, zoneID)
-	// fmt.Printf("Mist API ゾーン統計取得URL: %s\n", url)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, nil, err
+// retryWait 次のリトライまでの待機時間を計算
+// Retry-AfterがMaxRetryAfterを超える場合はリトライしない
+func (c *Client) retryWait(attempt int, err error) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) && rateErr.RetryAfter > 0 {
+		if rateErr.RetryAfter > c.MaxRetryAfter {
+			return 0, false
+		}
+		return rateErr.RetryAfter, true
 	}
-	c.SetAuthHeader(req)
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, nil, err
+	wait := c.BaseBackoff << attempt
+	if wait <= 0 || wait > c.MaxBackoff {
+		wait = c.MaxBackoff
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-
-	// レスポンスボディを読み取り
-	body, _ := io.ReadAll(resp.Body)
-	// fmt.Printf("Mist API ゾーン統計取得レスポンス: Status=%s, Body=%s\n", resp.Status, string(body))
+	// 同時リトライの集中を避けるためジッターを加える
+	if half := int64(wait / 2); half > 0 {
+		wait = wait/2 + time.Duration(rand.Int64N(half+1))
+	}
+	return wait, true
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+// Mistのゾーン内クライアント一覧を取得
+func (c *Client) GetZoneClients(ctx context.Context, siteID, zoneID string) (sdkClients []string, Clients []string, err error) {
+	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/zones/%s", c.BaseURL, siteID, zoneID)
+	body, err := c.get(ctx, "ゾーン統計取得", url, true)
+	if err != nil {
 		// 404エラーの場合は空配列を返す（クライアントが存在しない）
-		if resp.StatusCode == http.StatusNotFound {
-			log.Printf("ゾーン統計が見つかりません（404）: %s\n", string(body))
+		var notFoundErr *NotFoundError
+		if errors.As(err, &notFoundErr) {
+			log.Printf("ゾーン統計が見つかりません（404）: %s\n", notFoundErr.Body)
 			return nil, nil, nil
 		}
-		return nil, nil, fmt.Errorf("mist APIゾーン統計取得エラー: %s, %s", resp.Status, string(body))
+		return nil, nil, err
 	}
 
 	var zone MistZone
@@ -284,98 +330,70 @@ func (c *Client) GetZoneClients(siteID, zoneID string) (sdkClients []string, Cli
 		return nil, nil, fmt.Errorf("レスポンスにzonesフィールドが見つかりません: %s", string(body))
 	}
 
-	// fmt.Printf("取得したゾーンクライアント数(sdk): %d\n", len(zone.SDKClients))
 	return zone.SDKClients, zone.Clients, nil
 }
 
-// SDKクライアント詳細取得
-func (c *Client) GetSDKClient(siteID, clientID string) (*MistSDKClient, error) {
-	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/sdkclients/%s", c.BaseURL, siteID, clientID)
-	req, err := http.NewRequest("GET", url, nil)
+// サイト内の全ゾーンの統計情報（ゾーン内クライアント一覧を含む）を取得
+func (c *Client) GetZoneStats(ctx context.Context, siteID string) ([]MistZone, error) {
+	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/zones", c.BaseURL, siteID)
+	body, err := c.get(ctx, "ゾーン統計一覧取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	c.SetAuthHeader(req)
-	resp, err := c.HTTPClient.Do(req)
+
+	var zones []MistZone
+	if err := json.Unmarshal(body, &zones); err != nil {
+		return nil, fmt.Errorf("ゾーン統計データのJSON解析エラー: %w, Body: %s", err, string(body))
+	}
+	return zones, nil
+}
+
+// SDKクライアント詳細取得
+func (c *Client) GetSDKClient(ctx context.Context, siteID, clientID string) (*MistSDKClient, error) {
+	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/sdkclients/%s", c.BaseURL, siteID, clientID)
+	body, err := c.get(ctx, "SDKクライアント取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("mist API SDKクライアント取得エラー: %s, %s", resp.Status, string(body))
-	}
 	var client MistSDKClient
-	if err := json.NewDecoder(resp.Body).Decode(&client); err != nil {
+	if err := json.Unmarshal(body, &client); err != nil {
 		return nil, err
 	}
 	return &client, nil
 }
 
 // WiFiクライアント詳細取得
-func (c *Client) GetWirelessClient(siteID, clientID string) (*MistWirelessClient, error) {
+func (c *Client) GetWirelessClient(ctx context.Context, siteID, clientID string) (*MistWirelessClient, error) {
 	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/clients/%s", c.BaseURL, siteID, clientID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	c.SetAuthHeader(req)
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.get(ctx, "WiFiクライアント取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("mist API WiFiクライアント取得エラー: %s, %s", resp.Status, string(body))
-	}
 	var client MistWirelessClient
-	if err := json.NewDecoder(resp.Body).Decode(&client); err != nil {
+	if err := json.Unmarshal(body, &client); err != nil {
 		return nil, err
 	}
 	return &client, nil
 }
 
 // 新しいBLEデバイス一覧を取得
-func (c *Client) GetBLEDevices(siteID, mapID string) ([]MistBLEDevice, error) {
+func (c *Client) GetBLEDevices(ctx context.Context, siteID, mapID string) ([]MistBLEDevice, error) {
 	url := fmt.Sprintf("%s/api/v1/sites/%s/stats/maps/%s/discovered_assets", c.BaseURL, siteID, mapID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	c.SetAuthHeader(req)
-	resp, err := c.HTTPClient.Do(req)
+	body, err := c.get(ctx, "BLEデバイス取得", url, true)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("close error: %v\n", err)
-		}
-	}()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("mist API BLEデバイス取得エラー: %s, %s", resp.Status, string(body))
-	}
 	var devices []MistBLEDevice
-	if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
+	if err := json.Unmarshal(body, &devices); err != nil {
 		return nil, err
 	}
 	return devices, nil
 }
 
 // ゾーン内のBLEデバイスを抽出
-func (c *Client) GetZoneBLEDevices(siteID, zoneID, mapID string) ([]MistBLEDevice, error) {
+func (c *Client) GetZoneBLEDevices(ctx context.Context, siteID, zoneID, mapID string) ([]MistBLEDevice, error) {
 	// まずゾーン情報を取得
-	zones, err := c.GetZones(siteID)
+	zones, err := c.GetZones(ctx, siteID)
 	if err != nil {
 		return nil, fmt.Errorf("ゾーン情報取得エラー: %w", err)
 	}
@@ -394,7 +412,7 @@ func (c *Client) GetZoneBLEDevices(siteID, zoneID, mapID string) ([]MistBLEDevic
 	}
 
 	// BLEデバイス一覧を取得
-	bleDevices, err := c.GetBLEDevices(siteID, mapID)
+	bleDevices, err := c.GetBLEDevices(ctx, siteID, mapID)
 	if err != nil {
 		return nil, fmt.Errorf("BLEデバイス取得エラー: %w", err)
 	}
@@ -410,6 +428,11 @@ func (c *Client) GetZoneBLEDevices(siteID, zoneID, mapID string) ([]MistBLEDevic
 	return zoneBLEDevices, nil
 }
 
+// Contains 座標がゾーン内にあるかどうかを判定
+func (z *MistZone) Contains(x, y float64) bool {
+	return isPointInZone(x, y, z.Vertices)
+}
+
 // 点がゾーン内にあるかどうかを判定（ポリゴン内判定）
 func isPointInZone(x, y float64, vertices []Vertices) bool {
 	if len(vertices) < 3 {
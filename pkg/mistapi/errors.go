@@ -0,0 +1,113 @@
+package mistapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError Mist APIが返したエラーレスポンス
+type APIError struct {
+	Op         string // 操作名（例: "ゾーン取得"）
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mist API %sエラー: %s, %s", e.Op, e.Status, e.Body)
+}
+
+// RateLimitError レート制限（429）エラー
+type RateLimitError struct {
+	*APIError
+	RetryAfter time.Duration // Retry-Afterヘッダーの値（未指定の場合は0）
+}
+
+func (e *RateLimitError) Unwrap() error { return e.APIError }
+
+// UnauthorizedError 認証・認可（401/403）エラー
+type UnauthorizedError struct {
+	*APIError
+}
+
+func (e *UnauthorizedError) Unwrap() error { return e.APIError }
+
+// NotFoundError リソースが存在しない（404）エラー
+type NotFoundError struct {
+	*APIError
+}
+
+func (e *NotFoundError) Unwrap() error { return e.APIError }
+
+// TransientError 一時的なエラー（5xx・通信エラー）
+// 通信エラーの場合はAPIErrorがnilでErrに原因が入る
+type TransientError struct {
+	*APIError
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	if e.APIError != nil {
+		return e.APIError.Error()
+	}
+	return fmt.Sprintf("mist API通信エラー: %v", e.Err)
+}
+
+func (e *TransientError) Unwrap() error {
+	if e.APIError != nil {
+		return e.APIError
+	}
+	return e.Err
+}
+
+// newStatusError ステータスコードに応じた型付きエラーを作成
+func newStatusError(op string, resp *http.Response, body []byte) error {
+	apiErr := &APIError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{APIError: apiErr, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &UnauthorizedError{APIError: apiErr}
+	case resp.StatusCode == http.StatusNotFound:
+		return &NotFoundError{APIError: apiErr}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return &TransientError{APIError: apiErr}
+	default:
+		return apiErr
+	}
+}
+
+// parseRetryAfter Retry-Afterヘッダー（秒数またはHTTP日付）を解析
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// isRetryable リトライ対象のエラーかどうか
+func isRetryable(err error) bool {
+	var rateErr *RateLimitError
+	var transientErr *TransientError
+	return errors.As(err, &rateErr) || errors.As(err, &transientErr)
+}
@@ -0,0 +1,244 @@
+package mistapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Priority リクエストの優先度
+type Priority int
+
+const (
+	PriorityLow  Priority = iota // 管理画面からの参照など（デフォルト）
+	PriorityHigh                 // 出席監視のポーリング
+)
+
+func (p Priority) String() string {
+	if p == PriorityHigh {
+		return "high"
+	}
+	return "low"
+}
+
+type priorityKey struct{}
+
+// WithPriority contextにリクエストの優先度を設定
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext contextからリクエストの優先度を取得（未設定の場合はPriorityLow）
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityLow
+}
+
+// RateLimiter Mist API用のトークンバケット型レートリミッター
+//
+// トークンは1時間あたりの予算に合わせて一定速度で補充される。
+// 低優先度のリクエストはバケットに予約分のトークンを残し、
+// 高優先度のリクエストが待機している間は送信しない。
+// また1時間ごとの使用数が予算に達した場合は次の時間枠まで全リクエストを待たせる。
+type RateLimiter struct {
+	mu sync.Mutex
+
+	hourlyBudget int
+	capacity     float64
+	tokens       float64
+	refillPerSec float64
+	lowReserve   float64
+	lastRefill   time.Time
+	pausedUntil  time.Time
+
+	windowStart time.Time
+	windowUsed  int
+
+	waitingHigh int
+	waitingLow  int
+	total       map[Priority]int64
+	delayed     map[Priority]int64
+}
+
+// RateLimitStats レートリミッターの統計情報
+type RateLimitStats struct {
+	HourlyBudget    int              `json:"hourly_budget"`
+	UsedThisHour    int              `json:"used_this_hour"`
+	RemainingBudget int              `json:"remaining_budget"`
+	WindowResetAt   time.Time        `json:"window_reset_at"`
+	AvailableTokens float64          `json:"available_tokens"`
+	BurstCapacity   int              `json:"burst_capacity"`
+	PausedUntil     *time.Time       `json:"paused_until,omitempty"`
+	WaitingHigh     int              `json:"waiting_high"`
+	WaitingLow      int              `json:"waiting_low"`
+	TotalRequests   map[string]int64 `json:"total_requests"`
+	DelayedRequests map[string]int64 `json:"delayed_requests"`
+}
+
+// NewRateLimiter レートリミッターを作成
+// hourlyBudgetは1以上を指定する（0以下では予算が補充されず、すべての呼び出しが待ち続ける）
+// lowReserveRatioはバースト容量のうち高優先度用に確保しておく割合（0〜1）
+func NewRateLimiter(hourlyBudget, burst int, lowReserveRatio float64) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	if lowReserveRatio < 0 {
+		lowReserveRatio = 0
+	}
+	if lowReserveRatio > 1 {
+		lowReserveRatio = 1
+	}
+	now := time.Now()
+	return &RateLimiter{
+		hourlyBudget: hourlyBudget,
+		capacity:     float64(burst),
+		tokens:       float64(burst),
+		refillPerSec: float64(hourlyBudget) / time.Hour.Seconds(),
+		lowReserve:   float64(burst) * lowReserveRatio,
+		lastRefill:   now,
+		windowStart:  now,
+		total:        make(map[Priority]int64),
+		delayed:      make(map[Priority]int64),
+	}
+}
+
+// Wait トークンを1つ取得できるまで待機
+func (l *RateLimiter) Wait(ctx context.Context, p Priority) error {
+	l.mu.Lock()
+	l.addWaiter(p, 1)
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.addWaiter(p, -1)
+		l.mu.Unlock()
+	}()
+
+	delayed := false
+	for {
+		l.mu.Lock()
+		wait := l.tryAcquire(p, time.Now())
+		if wait == 0 {
+			l.total[p]++
+			if delayed {
+				l.delayed[p]++
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		delayed = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// PauseUntil 指定時刻まで全リクエストを停止（Mistから429を受け取った場合に使用）
+func (l *RateLimiter) PauseUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+		l.tokens = 0
+	}
+}
+
+// Stats 現在の統計情報を取得
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	stats := RateLimitStats{
+		HourlyBudget:    l.hourlyBudget,
+		UsedThisHour:    l.windowUsed,
+		RemainingBudget: max(l.hourlyBudget-l.windowUsed, 0),
+		WindowResetAt:   l.windowStart.Add(time.Hour),
+		AvailableTokens: l.tokens,
+		BurstCapacity:   int(l.capacity),
+		WaitingHigh:     l.waitingHigh,
+		WaitingLow:      l.waitingLow,
+		TotalRequests:   make(map[string]int64),
+		DelayedRequests: make(map[string]int64),
+	}
+	if l.pausedUntil.After(now) {
+		pausedUntil := l.pausedUntil
+		stats.PausedUntil = &pausedUntil
+	}
+	for _, p := range []Priority{PriorityHigh, PriorityLow} {
+		stats.TotalRequests[p.String()] = l.total[p]
+		stats.DelayedRequests[p.String()] = l.delayed[p]
+	}
+	return stats
+}
+
+// tryAcquire トークンの取得を試み、取得できない場合は次に試すまでの待機時間を返す
+// 呼び出し時にはロックを取得していること
+func (l *RateLimiter) tryAcquire(p Priority, now time.Time) time.Duration {
+	l.refill(now)
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	// 時間あたりの予算を使い切った場合は次の時間枠まで待機
+	if l.windowUsed >= l.hourlyBudget {
+		resetAt := l.windowStart.Add(time.Hour)
+		if l.windowUsed == l.hourlyBudget {
+			log.Printf("[MistAPI] 1時間あたりのAPI予算(%d)を使い切りました。%sまで待機します", l.hourlyBudget, resetAt.Format("15:04:05"))
+		}
+		return resetAt.Sub(now)
+	}
+
+	need := 1.0
+	if p == PriorityLow {
+		// 高優先度が待機中なら譲る
+		if l.waitingHigh > 0 {
+			return 100 * time.Millisecond
+		}
+		need += l.lowReserve
+	}
+
+	if l.tokens >= need {
+		l.tokens--
+		l.windowUsed++
+		return 0
+	}
+
+	if l.refillPerSec <= 0 {
+		return time.Second
+	}
+	wait := time.Duration((need - l.tokens) / l.refillPerSec * float64(time.Second))
+	return max(wait, 10*time.Millisecond)
+}
+
+// refill 経過時間に応じてトークンを補充し、必要に応じて時間枠を更新
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.lastRefill).Seconds(); elapsed > 0 {
+		l.tokens = min(l.capacity, l.tokens+elapsed*l.refillPerSec)
+		l.lastRefill = now
+	}
+	if now.Sub(l.windowStart) >= time.Hour {
+		l.windowStart = now
+		l.windowUsed = 0
+	}
+}
+
+func (l *RateLimiter) addWaiter(p Priority, delta int) {
+	if p == PriorityHigh {
+		l.waitingHigh += delta
+	} else {
+		l.waitingLow += delta
+	}
+}
@@ -0,0 +1,371 @@
+package mistapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamKind ストリームで配信されるデバイスの種類
+type StreamKind string
+
+const (
+	StreamKindClient    StreamKind = "clients"    // WiFiクライアント
+	StreamKindSDKClient StreamKind = "sdkclients" // SDKクライアント
+	StreamKindAsset     StreamKind = "assets"     // BLEアセット
+)
+
+// streamKinds マップごとに購読するチャンネルの種類
+var streamKinds = []StreamKind{StreamKindClient, StreamKindSDKClient, StreamKindAsset}
+
+// LocationUpdate ストリームで受信した位置情報
+type LocationUpdate struct {
+	Kind       StreamKind
+	MapID      string
+	ID         string // SDKクライアントのID
+	MAC        string
+	X          float64
+	Y          float64
+	ReceivedAt time.Time
+}
+
+// DeviceID ゾーン統計と同じ形式のデバイスID（SDKクライアントはID、それ以外はMACアドレス）
+func (u *LocationUpdate) DeviceID() string {
+	if u.Kind == StreamKindSDKClient && u.ID != "" {
+		return u.ID
+	}
+	return u.MAC
+}
+
+// streamMessage WebSocketで送受信するメッセージ
+type streamMessage struct {
+	Event   string `json:"event,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Data    string `json:"data,omitempty"` // JSON文字列
+
+	Subscribe   string `json:"subscribe,omitempty"`
+	Unsubscribe string `json:"unsubscribe,omitempty"`
+}
+
+// streamData チャンネルのdataに含まれる位置情報
+type streamData struct {
+	ID    string  `json:"id"`
+	MAC   string  `json:"mac"`
+	MapID string  `json:"map_id"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+}
+
+// StreamClient Mist WebSocketストリーム（/api-ws/v1/stream）のクライアント
+//
+// マップごとにクライアント・SDKクライアント・アセットの位置情報チャンネルを購読し、
+// 切断された場合は指数バックオフで再接続して購読し直す。
+type StreamClient struct {
+	URL      string
+	APIToken string
+	SiteID   string
+	Dialer   *websocket.Dialer
+
+	// 再接続設定
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	updates chan LocationUpdate
+
+	mu        sync.Mutex
+	writeMu   sync.Mutex
+	conn      *websocket.Conn
+	maps      map[string]bool // 購読中のマップID
+	connected bool
+}
+
+// NewStreamClient ストリームクライアントを作成
+func NewStreamClient(streamURL, apiToken, siteID string) *StreamClient {
+	return &StreamClient{
+		URL:         streamURL,
+		APIToken:    apiToken,
+		SiteID:      siteID,
+		Dialer:      &websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: http.ProxyFromEnvironment},
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+		updates:     make(chan LocationUpdate, 1024),
+		maps:        make(map[string]bool),
+	}
+}
+
+// NewStreamClient REST APIクライアントと同じ認証情報でストリームクライアントを作成
+func (c *Client) NewStreamClient() (*StreamClient, error) {
+	streamURL, err := StreamURLFromBaseURL(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamClient(streamURL, c.APIToken, c.SiteID), nil
+}
+
+// StreamURLFromBaseURL REST APIのベースURLからストリームのURLを作成
+// 例: https://api.mist.com → wss://api-ws.mist.com/api-ws/v1/stream
+func StreamURLFromBaseURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("mist APIのベースURLが不正です: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("mist APIのベースURLのスキームが不正です: %s", u.Scheme)
+	}
+	if host, ok := strings.CutPrefix(u.Host, "api."); ok {
+		u.Host = "api-ws." + host
+	}
+	u.Path = "/api-ws/v1/stream"
+	return u.String(), nil
+}
+
+// Updates 位置情報の受信チャンネル（Run終了時にクローズされる）
+func (s *StreamClient) Updates() <-chan LocationUpdate {
+	return s.updates
+}
+
+// Connected 接続中かどうか
+func (s *StreamClient) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+// Subscribed マップを購読中かどうか
+func (s *StreamClient) Subscribed(mapID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maps[mapID]
+}
+
+// SubscribeMap マップの位置情報チャンネルを購読（接続前の場合は接続時に購読する）
+func (s *StreamClient) SubscribeMap(mapID string) error {
+	s.mu.Lock()
+	if s.maps[mapID] {
+		s.mu.Unlock()
+		return nil
+	}
+	s.maps[mapID] = true
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return s.sendMapMessages(conn, mapID, true)
+}
+
+// UnsubscribeMap マップの位置情報チャンネルの購読を解除
+func (s *StreamClient) UnsubscribeMap(mapID string) error {
+	s.mu.Lock()
+	if !s.maps[mapID] {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.maps, mapID)
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return s.sendMapMessages(conn, mapID, false)
+}
+
+// Run ctxがキャンセルされるまで接続・受信・再接続を繰り返す
+func (s *StreamClient) Run(ctx context.Context) error {
+	defer close(s.updates)
+
+	for attempt := 0; ; attempt++ {
+		connectedAt := time.Now()
+		err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// 一定時間接続できていた場合はバックオフをリセット
+		if time.Since(connectedAt) > s.MaxBackoff {
+			attempt = 0
+		}
+		wait := s.backoff(attempt)
+		log.Printf("[MistStream] 切断されました、%s後に再接続します: %v", wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// runOnce 1回分の接続と受信ループ
+func (s *StreamClient) runOnce(ctx context.Context) error {
+	header := http.Header{}
+	header.Set("Authorization", "Token "+s.APIToken)
+
+	conn, resp, err := s.Dialer.DialContext(ctx, s.URL, header)
+	if err != nil {
+		if resp != nil {
+			return newStatusError("ストリーム接続", resp, nil)
+		}
+		return &TransientError{Err: err}
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.connected = true
+	mapIDs := make([]string, 0, len(s.maps))
+	for mapID := range s.maps {
+		mapIDs = append(mapIDs, mapID)
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.conn = nil
+		s.connected = false
+		s.mu.Unlock()
+		if err := conn.Close(); err != nil {
+			log.Printf("close error: %v\n", err)
+		}
+	}()
+
+	// ctxのキャンセルで受信を中断する
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	log.Printf("[MistStream] 接続しました (マップ数: %d)", len(mapIDs))
+	for _, mapID := range mapIDs {
+		if err := s.sendMapMessages(conn, mapID, true); err != nil {
+			return err
+		}
+	}
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		s.handleMessage(raw)
+	}
+}
+
+// handleMessage 受信したメッセージを解析して位置情報を配信
+func (s *StreamClient) handleMessage(raw []byte) {
+	var msg streamMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		log.Printf("[MistStream] メッセージの解析に失敗しました: %v", err)
+		return
+	}
+
+	switch msg.Event {
+	case "data":
+	case "channel_subscribed":
+		log.Printf("[MistStream] 購読開始: %s", msg.Channel)
+		return
+	default:
+		return
+	}
+
+	kind, mapID, ok := parseStreamChannel(msg.Channel)
+	if !ok {
+		return
+	}
+
+	var data streamData
+	if err := json.Unmarshal([]byte(msg.Data), &data); err != nil {
+		log.Printf("[MistStream] データの解析に失敗しました (channel=%s): %v", msg.Channel, err)
+		return
+	}
+	if data.MapID != "" {
+		mapID = data.MapID
+	}
+
+	update := LocationUpdate{
+		Kind:       kind,
+		MapID:      mapID,
+		ID:         data.ID,
+		MAC:        data.MAC,
+		X:          data.X,
+		Y:          data.Y,
+		ReceivedAt: time.Now(),
+	}
+
+	select {
+	case s.updates <- update:
+	default:
+		// 受信側が追いつかない場合は破棄する（次の更新で最新の位置が届く）
+		log.Printf("[MistStream] 受信キューが一杯のため位置情報を破棄しました: %s", update.DeviceID())
+	}
+}
+
+// sendMapMessages マップの全チャンネルの購読・購読解除メッセージを送信
+func (s *StreamClient) sendMapMessages(conn *websocket.Conn, mapID string, subscribe bool) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	for _, kind := range streamKinds {
+		channel := streamChannel(s.SiteID, mapID, kind)
+		msg := streamMessage{Unsubscribe: channel}
+		if subscribe {
+			msg = streamMessage{Subscribe: channel}
+		}
+		if err := conn.WriteJSON(msg); err != nil {
+			return &TransientError{Err: err}
+		}
+	}
+	return nil
+}
+
+// backoff 再接続までの待機時間（指数バックオフ＋ジッター）
+func (s *StreamClient) backoff(attempt int) time.Duration {
+	wait := s.BaseBackoff << min(attempt, 16)
+	if wait <= 0 || wait > s.MaxBackoff {
+		wait = s.MaxBackoff
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// streamChannel マップの位置情報チャンネル名
+func streamChannel(siteID, mapID string, kind StreamKind) string {
+	return fmt.Sprintf("/sites/%s/stats/maps/%s/%s", siteID, mapID, kind)
+}
+
+// parseStreamChannel チャンネル名から種類とマップIDを取得
+func parseStreamChannel(channel string) (StreamKind, string, bool) {
+	parts := strings.Split(strings.Trim(channel, "/"), "/")
+	// sites/{site_id}/stats/maps/{map_id}/{kind}
+	if len(parts) != 6 || parts[0] != "sites" || parts[2] != "stats" || parts[3] != "maps" {
+		return "", "", false
+	}
+	kind := StreamKind(parts[5])
+	for _, k := range streamKinds {
+		if k == kind {
+			return kind, parts[4], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,191 @@
+package mistapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Webhookのトピック
+const (
+	WebhookTopicZone       = "zone"
+	WebhookTopicLocation   = "location"
+	WebhookTopicClientJoin = "client-join"
+)
+
+// Webhookの署名ヘッダー
+const (
+	WebhookSignatureHeader   = "X-Mist-Signature"    // HMAC-SHA1
+	WebhookSignatureV2Header = "X-Mist-Signature-v2" // HMAC-SHA256
+)
+
+var (
+	ErrWebhookSignatureMissing = errors.New("webhookの署名ヘッダーがありません")
+	ErrWebhookSignatureInvalid = errors.New("webhookの署名が一致しません")
+)
+
+// VerifyWebhookSignature Webhookの署名を検証
+// X-Mist-Signature-v2（SHA256）を優先し、なければX-Mist-Signature（SHA1）で検証する
+func VerifyWebhookSignature(secret string, body []byte, header http.Header) error {
+	if sig := header.Get(WebhookSignatureV2Header); sig != "" {
+		return verifyHMAC(sha256.New, secret, body, sig)
+	}
+	if sig := header.Get(WebhookSignatureHeader); sig != "" {
+		return verifyHMAC(sha1.New, secret, body, sig)
+	}
+	return ErrWebhookSignatureMissing
+}
+
+func verifyHMAC(h func() hash.Hash, secret string, body []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrWebhookSignatureInvalid
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrWebhookSignatureInvalid
+	}
+	return nil
+}
+
+// webhookPayload Webhookのリクエストボディ
+type webhookPayload struct {
+	Topic  string            `json:"topic"`
+	Events []json.RawMessage `json:"events"`
+}
+
+// ZoneEvent ゾーン入退室イベント
+type ZoneEvent struct {
+	ID        string  `json:"id"` // SDKクライアント・アセットのID
+	MAC       string  `json:"mac"`
+	Name      string  `json:"name"`
+	SiteID    string  `json:"site_id"`
+	MapID     string  `json:"map_id"`
+	ZoneID    string  `json:"zone_id"`
+	Type      string  `json:"type"`    // "wifi", "sdk", "asset"
+	Trigger   string  `json:"trigger"` // "enter", "exit"
+	Timestamp float64 `json:"timestamp"`
+}
+
+// DeviceID ゾーン統計と同じ形式のデバイスID（SDKクライアントはID、それ以外はMACアドレス）
+func (e *ZoneEvent) DeviceID() string {
+	if e.Type == "sdk" && e.ID != "" {
+		return e.ID
+	}
+	return e.MAC
+}
+
+// IsEnter 入室イベントかどうか
+func (e *ZoneEvent) IsEnter() bool {
+	return e.Trigger == "enter"
+}
+
+// Time イベントの発生時刻
+func (e *ZoneEvent) Time() time.Time {
+	return unixFloatToTime(e.Timestamp)
+}
+
+// LocationEvent 位置情報イベント
+type LocationEvent struct {
+	ID        string  `json:"id"`
+	MAC       string  `json:"mac"`
+	SiteID    string  `json:"site_id"`
+	MapID     string  `json:"map_id"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Type      string  `json:"type"` // "wifi", "sdk", "asset"
+	Timestamp float64 `json:"timestamp"`
+}
+
+// DeviceID ゾーン統計と同じ形式のデバイスID（SDKクライアントはID、それ以外はMACアドレス）
+func (e *LocationEvent) DeviceID() string {
+	if e.Type == "sdk" && e.ID != "" {
+		return e.ID
+	}
+	return e.MAC
+}
+
+// Time イベントの発生時刻
+func (e *LocationEvent) Time() time.Time {
+	return unixFloatToTime(e.Timestamp)
+}
+
+// ClientJoinEvent WiFiクライアント接続イベント
+type ClientJoinEvent struct {
+	MAC       string  `json:"mac"`
+	SiteID    string  `json:"site_id"`
+	AP        string  `json:"ap"`
+	SSID      string  `json:"ssid"`
+	Timestamp float64 `json:"timestamp"`
+}
+
+// Time イベントの発生時刻
+func (e *ClientJoinEvent) Time() time.Time {
+	return unixFloatToTime(e.Timestamp)
+}
+
+// WebhookEvents 解析済みのWebhookイベント
+type WebhookEvents struct {
+	Topic       string
+	Zone        []ZoneEvent
+	Location    []LocationEvent
+	ClientJoin  []ClientJoinEvent
+	Unsupported int // 未対応トピックまたは解析できなかったイベント数
+}
+
+// ParseWebhook Webhookのリクエストボディを型付きイベントに解析
+func ParseWebhook(body []byte) (*WebhookEvents, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("webhookのJSON解析エラー: %w", err)
+	}
+
+	events := &WebhookEvents{Topic: payload.Topic}
+	for _, raw := range payload.Events {
+		var err error
+		switch payload.Topic {
+		case WebhookTopicZone:
+			var e ZoneEvent
+			if err = json.Unmarshal(raw, &e); err == nil {
+				events.Zone = append(events.Zone, e)
+			}
+		case WebhookTopicLocation:
+			var e LocationEvent
+			if err = json.Unmarshal(raw, &e); err == nil {
+				events.Location = append(events.Location, e)
+			}
+		case WebhookTopicClientJoin:
+			var e ClientJoinEvent
+			if err = json.Unmarshal(raw, &e); err == nil {
+				events.ClientJoin = append(events.ClientJoin, e)
+			}
+		default:
+			events.Unsupported++
+			continue
+		}
+		if err != nil {
+			log.Printf("[MistAPI] webhookイベントの解析に失敗しました (topic=%s): %v", payload.Topic, err)
+			events.Unsupported++
+		}
+	}
+	return events, nil
+}
+
+// unixFloatToTime 小数秒のUNIX時刻をtime.Timeに変換（0の場合は現在時刻）
+func unixFloatToTime(ts float64) time.Time {
+	if ts <= 0 {
+		return time.Now()
+	}
+	sec := int64(ts)
+	nsec := int64((ts - float64(sec)) * float64(time.Second))
+	return time.Unix(sec, nsec)
+}
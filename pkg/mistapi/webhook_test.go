@@ -0,0 +1,132 @@
+package mistapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"testing"
+)
+
+// sign テスト用にbodyのHMAC署名を16進文字列で作成
+func sign(h func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// headers テスト用に署名ヘッダーを作成（実際のリクエストと同じく正規化したキーで設定する）
+func headers(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	body := []byte(`{"topic":"zone","events":[{"mac":"aabbccddeeff","zone_id":"z1","trigger":"enter"}]}`)
+	tampered := []byte(`{"topic":"zone","events":[{"mac":"000000000000","zone_id":"z1","trigger":"enter"}]}`)
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		header http.Header
+		want   error
+	}{
+		{
+			name:   "正しいv2署名（SHA256）",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureV2Header, sign(sha256.New, secret, body)),
+		},
+		{
+			name:   "正しいv1署名（SHA1）",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureHeader, sign(sha1.New, secret, body)),
+		},
+		{
+			name:   "v2署名を優先する",
+			secret: secret,
+			body:   body,
+			header: headers(
+				WebhookSignatureV2Header, sign(sha256.New, secret, body),
+				WebhookSignatureHeader, "invalid",
+			),
+		},
+		{
+			name:   "v2署名が不正ならv1署名が正しくても拒否",
+			secret: secret,
+			body:   body,
+			header: headers(
+				WebhookSignatureV2Header, sign(sha256.New, "other", body),
+				WebhookSignatureHeader, sign(sha1.New, secret, body),
+			),
+			want: ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "改ざんされたボディ",
+			secret: secret,
+			body:   tampered,
+			header: headers(WebhookSignatureV2Header, sign(sha256.New, secret, body)),
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "改ざんされたボディ（v1）",
+			secret: secret,
+			body:   tampered,
+			header: headers(WebhookSignatureHeader, sign(sha1.New, secret, body)),
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "署名ヘッダーなし",
+			secret: secret,
+			body:   body,
+			header: headers(),
+			want:   ErrWebhookSignatureMissing,
+		},
+		{
+			name:   "空の署名ヘッダー",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureV2Header, ""),
+			want:   ErrWebhookSignatureMissing,
+		},
+		{
+			name:   "異なるシークレットで署名",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureV2Header, sign(sha256.New, "wrong-secret", body)),
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "16進数でない署名",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureV2Header, "not-hex"),
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "SHA1の署名をv2ヘッダーで送信",
+			secret: secret,
+			body:   body,
+			header: headers(WebhookSignatureV2Header, sign(sha1.New, secret, body)),
+			want:   ErrWebhookSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(tt.secret, tt.body, tt.header)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyWebhookSignature() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}